@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrUnknownClient      = errors.New("unknown client")
+)
+
+// Authenticator resolves the identity of the caller from a bearer token or, when allowed, from the legacy Client-Id
+// header used by the simulator.
+type Authenticator struct {
+	verifier      *JWTVerifier
+	adminToken    string
+	allowClientID bool
+}
+
+// NewAuthenticator creates the authenticator. The verifier is nil when bearer tokens are not configured. The admin
+// token, when set, is accepted as a bearer token granting the admin role.
+func NewAuthenticator(verifier *JWTVerifier, adminToken string, allowClientID bool) *Authenticator {
+	return &Authenticator{
+		verifier:      verifier,
+		adminToken:    adminToken,
+		allowClientID: allowClientID,
+	}
+}
+
+// AllowsClientID tells whether clients may identify themselves with the Client-Id header.
+func (a *Authenticator) AllowsClientID() bool {
+	return a.allowClientID
+}
+
+// Authenticate prefers the bearer token over the client ID. Identities coming from the client ID are not verified
+// here, the caller checks them against the registered users.
+func (a *Authenticator) Authenticate(ctx context.Context, bearerToken, clientID string) (*Identity, error) {
+	if bearerToken != "" {
+		if a.adminToken != "" && subtle.ConstantTimeCompare([]byte(bearerToken), []byte(a.adminToken)) == 1 {
+			return &Identity{
+				Subject: uuid.Nil,
+				Method:  MethodAdminToken,
+				Roles:   []Role{RoleAdmin},
+			}, nil
+		}
+
+		if a.verifier != nil {
+			return a.verifier.Verify(ctx, bearerToken)
+		}
+	}
+
+	if clientID != "" && a.allowClientID {
+		clientUUID, err := uuid.Parse(clientID)
+		if err != nil {
+			return nil, fmt.Errorf("parsing client id: %w", errors.Join(err, ErrUnknownClient))
+		}
+
+		return &Identity{
+			Subject: clientUUID,
+			Method:  MethodClientID,
+			Roles:   []Role{RoleRider},
+		}, nil
+	}
+
+	if bearerToken != "" {
+		return nil, ErrInvalidToken
+	}
+
+	return nil, ErrMissingCredentials
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MethodJWT        = "jwt"
+	MethodClientID   = "client_id"
+	MethodAdminToken = "admin_token"
+)
+
+// Identity describes the authenticated caller. Cities limit where the caller may act, no cities mean all of them.
+type Identity struct {
+	Subject uuid.UUID
+	Method  string
+	Roles   []Role
+	Cities  []string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of the context carrying the identity of the caller.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the caller put into the context by the authentication layer.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+
+	return identity, ok && identity != nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// claims are the registered claims extended with the access the token grants.
+type claims struct {
+	jwt.RegisteredClaims
+	Roles  []string `json:"roles"`
+	Cities []string `json:"cities"`
+}
+
+// JWTVerifier checks the signature, issuer, audience and expiry of bearer tokens.
+type JWTVerifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(keys *KeySet, issuer, audience string, leeway time.Duration) *JWTVerifier {
+	return &JWTVerifier{
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(keys.Methods()),
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(leeway),
+		),
+	}
+}
+
+// Verify parses the token and returns the identity of its subject, which has to be a UUID. Tokens without roles
+// are issued to riders.
+func (v *JWTVerifier) Verify(_ context.Context, token string) (*Identity, error) {
+	tokenClaims := claims{}
+
+	if _, err := v.parser.ParseWithClaims(token, &tokenClaims, v.keys.keyFor); err != nil {
+		return nil, fmt.Errorf("parsing token: %w", errors.Join(err, ErrInvalidToken))
+	}
+
+	subject, err := uuid.Parse(tokenClaims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("parsing token subject: %w", errors.Join(err, ErrInvalidToken))
+	}
+
+	roles := []Role{RoleRider}
+	if tokenClaims.Roles != nil {
+		roles = ParseRoles(tokenClaims.Roles)
+	}
+
+	return &Identity{
+		Subject: subject,
+		Method:  MethodJWT,
+		Roles:   roles,
+		Cities:  tokenClaims.Cities,
+	}, nil
+}
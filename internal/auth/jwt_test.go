@@ -0,0 +1,308 @@
+//go:build unit
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testHMACKey  = "test-hmac-key"
+	testIssuer   = "https://auth.scootin-aboot.test"
+	testAudience = "scooter-rental"
+	testKeyID    = "rider-signing-key"
+)
+
+func TestJWTVerifierVerify(t *testing.T) {
+	subject := uuid.New()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	hmacKeyPath := writeTestFile(t, dir, "hmac.key", []byte(testHMACKey+"\n"))
+	rsaKeyPath := writeTestFile(t, dir, testKeyID+".pem", publicKeyPEM(t, &rsaKey.PublicKey))
+	ecKeyPath := writeTestFile(t, dir, "ec.pem", publicKeyPEM(t, &ecKey.PublicKey))
+	jwksPath := writeTestFile(t, dir, "jwks.json", testJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey))
+
+	validClaims := jwt.RegisteredClaims{
+		Subject:   subject.String(),
+		Issuer:    testIssuer,
+		Audience:  jwt.ClaimStrings{testAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+
+	withClaims := func(modify func(claims *jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		claims := validClaims
+		modify(&claims)
+
+		return claims
+	}
+
+	tests := map[string]struct {
+		loadKeys func(keys *KeySet) error
+		token    string
+		wantErr  error
+	}{
+		"verifying hmac signed token": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadHMACKey(hmacKeyPath) },
+			token:    signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", validClaims),
+		},
+		"verifying rsa signed token with key id": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadPublicKey(rsaKeyPath) },
+			token:    signToken(t, jwt.SigningMethodRS256, rsaKey, testKeyID, validClaims),
+		},
+		"verifying ecdsa signed token without key id": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadPublicKey(ecKeyPath) },
+			token:    signToken(t, jwt.SigningMethodES256, ecKey, "", validClaims),
+		},
+		"verifying rsa signed token with jwks key": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadJWKS(jwksPath) },
+			token:    signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", validClaims),
+		},
+		"verifying ecdsa signed token with jwks key": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadJWKS(jwksPath) },
+			token:    signToken(t, jwt.SigningMethodES256, ecKey, "ec", validClaims),
+		},
+		"failed verifying token signed with unknown key": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadPublicKey(rsaKeyPath) },
+			token:    signToken(t, jwt.SigningMethodRS256, otherRSAKey, testKeyID, validClaims),
+			wantErr:  ErrInvalidToken,
+		},
+		"failed verifying token with algorithm not matching the keys": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadPublicKey(rsaKeyPath) },
+			token:    signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", validClaims),
+			wantErr:  ErrInvalidToken,
+		},
+		"failed verifying expired token": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadHMACKey(hmacKeyPath) },
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", withClaims(
+				func(claims *jwt.RegisteredClaims) {
+					claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+				},
+			)),
+			wantErr: jwt.ErrTokenExpired,
+		},
+		"failed verifying token without expiry": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadHMACKey(hmacKeyPath) },
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", withClaims(
+				func(claims *jwt.RegisteredClaims) { claims.ExpiresAt = nil },
+			)),
+			wantErr: ErrInvalidToken,
+		},
+		"failed verifying token of other issuer": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadHMACKey(hmacKeyPath) },
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", withClaims(
+				func(claims *jwt.RegisteredClaims) { claims.Issuer = "https://other.test" },
+			)),
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		"failed verifying token for other audience": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadHMACKey(hmacKeyPath) },
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", withClaims(
+				func(claims *jwt.RegisteredClaims) { claims.Audience = jwt.ClaimStrings{"other"} },
+			)),
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		"failed verifying token with subject not being uuid": {
+			loadKeys: func(keys *KeySet) error { return keys.LoadHMACKey(hmacKeyPath) },
+			token: signToken(t, jwt.SigningMethodHS256, []byte(testHMACKey), "", withClaims(
+				func(claims *jwt.RegisteredClaims) { claims.Subject = "rider" },
+			)),
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys := NewKeySet()
+			require.NoError(t, tt.loadKeys(keys))
+
+			verifier := NewJWTVerifier(keys, testIssuer, testAudience, 0)
+
+			identity, err := verifier.Verify(context.Background(), tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && identity.Subject != subject {
+				t.Errorf("Verify() subject = %v, want %v", identity.Subject, subject)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierVerifyRoles(t *testing.T) {
+	hmacKeyPath := writeTestFile(t, t.TempDir(), "hmac.key", []byte(testHMACKey))
+
+	keys := NewKeySet()
+	require.NoError(t, keys.LoadHMACKey(hmacKeyPath))
+
+	verifier := NewJWTVerifier(keys, testIssuer, testAudience, 0)
+
+	registeredClaims := jwt.RegisteredClaims{
+		Subject:   uuid.NewString(),
+		Issuer:    testIssuer,
+		Audience:  jwt.ClaimStrings{testAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+
+	tests := map[string]struct {
+		claims     claims
+		wantRoles  []Role
+		wantCities []string
+	}{
+		"token without roles is issued to rider": {
+			claims:    claims{RegisteredClaims: registeredClaims},
+			wantRoles: []Role{RoleRider},
+		},
+		"token with operator role scoped to city": {
+			claims:     claims{RegisteredClaims: registeredClaims, Roles: []string{"operator"}, Cities: []string{"Ottawa"}},
+			wantRoles:  []Role{RoleOperator},
+			wantCities: []string{"Ottawa"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte(testHMACKey))
+			require.NoError(t, err)
+
+			identity, err := verifier.Verify(context.Background(), token)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.wantRoles, identity.Roles)
+			require.Equal(t, tt.wantCities, identity.Cities)
+		})
+	}
+}
+
+func TestAuthenticatorAuthenticate(t *testing.T) {
+	clientID := uuid.New()
+
+	tests := map[string]struct {
+		clientID      string
+		allowClientID bool
+		wantMethod    string
+		wantErr       error
+	}{
+		"authenticating with client id": {
+			clientID:      clientID.String(),
+			allowClientID: true,
+			wantMethod:    MethodClientID,
+		},
+		"failed authenticating with client id that is not uuid": {
+			clientID:      "client",
+			allowClientID: true,
+			wantErr:       ErrUnknownClient,
+		},
+		"failed authenticating with disabled client id": {
+			clientID:      clientID.String(),
+			allowClientID: false,
+			wantErr:       ErrMissingCredentials,
+		},
+		"failed authenticating without credentials": {
+			allowClientID: true,
+			wantErr:       ErrMissingCredentials,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			identity, err := NewAuthenticator(nil, "", tt.allowClientID).Authenticate(context.Background(), "", tt.clientID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && identity.Method != tt.wantMethod {
+				t.Errorf("Authenticate() method = %v, want %v", identity.Method, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, keyID string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func writeTestFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	return path
+}
+
+func publicKeyPEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func testJWKS(t *testing.T, rsaKey *rsa.PublicKey, ecKey *ecdsa.PublicKey) []byte {
+	t.Helper()
+
+	encode := base64.RawURLEncoding.EncodeToString
+
+	content, err := json.Marshal(jsonWebKeySet{
+		Keys: []jsonWebKey{
+			{
+				KeyType: "RSA",
+				KeyID:   "rsa",
+				Use:     "sig",
+				N:       encode(rsaKey.N.Bytes()),
+				E:       encode([]byte{1, 0, 1}),
+			},
+			{
+				KeyType: "EC",
+				KeyID:   "ec",
+				Curve:   "P-256",
+				X:       encode(ecKey.X.Bytes()),
+				Y:       encode(ecKey.Y.Bytes()),
+			},
+			{
+				KeyType: "RSA",
+				KeyID:   "encryption",
+				Use:     "enc",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	return content
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrKeyNotFound       = errors.New("no key matching the token")
+	ErrUnsupportedKey    = errors.New("unsupported key")
+	ErrAmbiguousKey      = errors.New("token does not name its key and several keys match")
+	ErrNoVerificationKey = errors.New("no token verification key configured")
+)
+
+// KeySet holds the keys accepted for verifying token signatures. Public keys are named by their key ID, which tokens
+// may carry in the kid header. Tokens without kid are accepted when only one key of their type is known.
+type KeySet struct {
+	hmacKey    []byte
+	publicKeys map[string]any
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{
+		publicKeys: make(map[string]any),
+	}
+}
+
+// Empty tells whether no key was loaded into the set.
+func (ks *KeySet) Empty() bool {
+	return len(ks.hmacKey) == 0 && len(ks.publicKeys) == 0
+}
+
+// LoadHMACKey reads the shared secret used to verify HS256, HS384 and HS512 signatures. Surrounding whitespace is
+// trimmed, so the file may end with a new line.
+func (ks *KeySet) LoadHMACKey(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading hmac key file: %w", err)
+	}
+
+	key := []byte(strings.TrimSpace(string(content)))
+	if len(key) == 0 {
+		return fmt.Errorf("hmac key file %s is empty: %w", path, ErrUnsupportedKey)
+	}
+
+	ks.hmacKey = key
+
+	return nil
+}
+
+// LoadPublicKey reads a PEM encoded RSA or ECDSA public key. The key ID is the file name without its extension.
+func (ks *KeySet) LoadPublicKey(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading public key file: %w", err)
+	}
+
+	keyID := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	if key, rsaErr := jwt.ParseRSAPublicKeyFromPEM(content); rsaErr == nil {
+		ks.publicKeys[keyID] = key
+
+		return nil
+	}
+
+	key, err := jwt.ParseECPublicKeyFromPEM(content)
+	if err != nil {
+		return fmt.Errorf("parsing public key %s: %w", path, errors.Join(err, ErrUnsupportedKey))
+	}
+
+	ks.publicKeys[keyID] = key
+
+	return nil
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	K       string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// LoadJWKS reads RSA and EC public keys from a local JSON Web Key Set file. Keys meant for encryption are skipped.
+func (ks *KeySet) LoadJWKS(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading jwks file: %w", err)
+	}
+
+	var keySet jsonWebKeySet
+
+	if err = json.Unmarshal(content, &keySet); err != nil {
+		return fmt.Errorf("unmarshaling jwks: %w", err)
+	}
+
+	for i := range keySet.Keys {
+		if keySet.Keys[i].Use != "" && keySet.Keys[i].Use != "sig" {
+			continue
+		}
+
+		key, innerErr := keySet.Keys[i].publicKey()
+		if innerErr != nil {
+			return fmt.Errorf("parsing jwks key %q: %w", keySet.Keys[i].KeyID, innerErr)
+		}
+
+		ks.publicKeys[keySet.Keys[i].KeyID] = key
+	}
+
+	return nil
+}
+
+// Methods returns the signing methods the loaded keys can verify.
+func (ks *KeySet) Methods() []string {
+	var methods []string
+
+	if len(ks.hmacKey) > 0 {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+
+	var hasRSA, hasEC bool
+
+	for _, key := range ks.publicKeys {
+		switch key.(type) {
+		case *rsa.PublicKey:
+			hasRSA = true
+		case *ecdsa.PublicKey:
+			hasEC = true
+		}
+	}
+
+	if hasRSA {
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512")
+	}
+
+	if hasEC {
+		methods = append(methods, "ES256", "ES384", "ES512")
+	}
+
+	return methods
+}
+
+// keyFor returns the key verifying the signature of the token, matching the token's algorithm with the key type.
+func (ks *KeySet) keyFor(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(ks.hmacKey) == 0 {
+			return nil, ErrKeyNotFound
+		}
+
+		return ks.hmacKey, nil
+	}
+
+	matchesMethod := func(key any) bool {
+		switch key.(type) {
+		case *rsa.PublicKey:
+			_, isRSA := token.Method.(*jwt.SigningMethodRSA)
+			_, isPSS := token.Method.(*jwt.SigningMethodRSAPSS)
+
+			return isRSA || isPSS
+		case *ecdsa.PublicKey:
+			_, isEC := token.Method.(*jwt.SigningMethodECDSA)
+
+			return isEC
+		default:
+			return false
+		}
+	}
+
+	if keyID, ok := token.Header["kid"].(string); ok && keyID != "" {
+		key, found := ks.publicKeys[keyID]
+		if !found || !matchesMethod(key) {
+			return nil, fmt.Errorf("key %q: %w", keyID, ErrKeyNotFound)
+		}
+
+		return key, nil
+	}
+
+	var result any
+
+	for _, key := range ks.publicKeys {
+		if !matchesMethod(key) {
+			continue
+		}
+
+		if result != nil {
+			return nil, ErrAmbiguousKey
+		}
+
+		result = key
+	}
+
+	if result == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return result, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (any, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding exponent: %w", err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %q: %w", jwk.Curve, ErrUnsupportedKey)
+		}
+
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x coordinate: %w", err)
+		}
+
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y coordinate: %w", err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("key type %q: %w", jwk.KeyType, ErrUnsupportedKey)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64url value: %w", err)
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
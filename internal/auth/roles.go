@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleRider    Role = "rider"
+	RoleOperator Role = "operator"
+	RoleSupport  Role = "support"
+	RoleAdmin    Role = "admin"
+	// RoleDevice is held by the scooters themselves, the subject of their tokens is the UUID of the scooter.
+	RoleDevice Role = "device"
+)
+
+type Permission string
+
+const (
+	PermissionScootersRead   Permission = "scooters:read"
+	PermissionScootersRent   Permission = "scooters:rent"
+	PermissionScootersFree   Permission = "scooters:free"
+	PermissionRentalsRead    Permission = "rentals:read"
+	PermissionLogLevelRead   Permission = "log_level:read"
+	PermissionLogLevelWrite  Permission = "log_level:write"
+	PermissionProfileRead    Permission = "profile:read"
+	PermissionProfileWrite   Permission = "profile:write"
+	PermissionUsersManage    Permission = "users:manage"
+	PermissionWebhooksManage Permission = "webhooks:manage"
+	PermissionTelemetryWrite Permission = "telemetry:write"
+)
+
+// Reasons of the denials, meant for the clients to tell them apart.
+const (
+	ReasonMissingPermission = "missing_permission"
+	ReasonCityNotAllowed    = "city_not_allowed"
+	ReasonUserNotRegistered = "user_not_registered"
+	ReasonUserSuspended     = "user_suspended"
+	ReasonScooterNotAllowed = "scooter_not_allowed"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// rolePermissions lists what each role is allowed to do. Admins are allowed everything.
+var rolePermissions = map[Role][]Permission{
+	RoleRider: {
+		PermissionScootersRead,
+		PermissionScootersRent,
+		PermissionScootersFree,
+		PermissionRentalsRead,
+		PermissionProfileRead,
+		PermissionProfileWrite,
+	},
+	RoleOperator: {
+		PermissionScootersRead,
+		PermissionScootersFree,
+		PermissionProfileRead,
+		PermissionProfileWrite,
+	},
+	RoleSupport: {
+		PermissionScootersRead,
+		PermissionLogLevelRead,
+		PermissionProfileRead,
+		PermissionProfileWrite,
+		PermissionUsersManage,
+	},
+	RoleDevice: {
+		PermissionTelemetryWrite,
+	},
+}
+
+// scopedRoles are the roles limited to the cities of the identity.
+var scopedRoles = []Role{RoleOperator, RoleSupport}
+
+// DeniedError is returned when the identity lacks the permission or the access to the city or the scooter, or its user
+// may not use the service at all.
+type DeniedError struct {
+	Reason      string
+	Permission  Permission
+	City        string
+	ScooterUUID uuid.UUID
+}
+
+func (de *DeniedError) Error() string {
+	switch de.Reason {
+	case ReasonCityNotAllowed:
+		return fmt.Sprintf("permission denied: city %q is not allowed", de.City)
+	case ReasonScooterNotAllowed:
+		return fmt.Sprintf("permission denied: scooter %s is not allowed", de.ScooterUUID)
+	case ReasonMissingPermission:
+		return fmt.Sprintf("permission denied: missing %q", de.Permission)
+	default:
+		return "permission denied: " + de.Reason
+	}
+}
+
+func (de *DeniedError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// Known tells whether the role is one of the roles of the service.
+func (r Role) Known() bool {
+	_, known := rolePermissions[r]
+
+	return known || r == RoleAdmin
+}
+
+// ParseRoles keeps the known roles, so tokens issued with roles of other services are still accepted.
+func ParseRoles(values []string) []Role {
+	var roles []Role
+
+	for _, value := range values {
+		if role := Role(strings.ToLower(value)); role.Known() {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// Authorize checks whether any of the roles of the identity grants the permission.
+func (i *Identity) Authorize(permission Permission) error {
+	for _, role := range i.Roles {
+		if role == RoleAdmin || slices.Contains(rolePermissions[role], permission) {
+			return nil
+		}
+	}
+
+	return &DeniedError{
+		Reason:     ReasonMissingPermission,
+		Permission: permission,
+	}
+}
+
+// AuthorizeCity checks whether the identity may act in the city. Identities without cities are not scoped, admins and
+// the identities holding none of the scoped roles, e.g. riders, are never scoped. The identity holding a scoped role
+// is scoped whatever other roles it holds, so the role of a rider doesn't lift the scope of an operator.
+func (i *Identity) AuthorizeCity(city string) error {
+	if len(i.Cities) == 0 || slices.Contains(i.Roles, RoleAdmin) || !slices.ContainsFunc(i.Roles, isScoped) {
+		return nil
+	}
+
+	for _, allowed := range i.Cities {
+		if strings.EqualFold(allowed, city) {
+			return nil
+		}
+	}
+
+	return &DeniedError{
+		Reason: ReasonCityNotAllowed,
+		City:   city,
+	}
+}
+
+func isScoped(role Role) bool {
+	return slices.Contains(scopedRoles, role)
+}
+
+// AuthorizeScooter checks whether the identity may act as the scooter. Devices may act only as the scooter they are,
+// admins as any.
+func (i *Identity) AuthorizeScooter(scooterUUID uuid.UUID) error {
+	if slices.Contains(i.Roles, RoleAdmin) || (slices.Contains(i.Roles, RoleDevice) && i.Subject == scooterUUID) {
+		return nil
+	}
+
+	return &DeniedError{
+		Reason:      ReasonScooterNotAllowed,
+		ScooterUUID: scooterUUID,
+	}
+}
@@ -0,0 +1,175 @@
+//go:build unit
+
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestIdentityAuthorize(t *testing.T) {
+	tests := map[string]struct {
+		roles      []Role
+		permission Permission
+		wantErr    error
+	}{
+		"rider renting scooter": {
+			roles:      []Role{RoleRider},
+			permission: PermissionScootersRent,
+		},
+		"operator freeing scooter": {
+			roles:      []Role{RoleOperator},
+			permission: PermissionScootersFree,
+		},
+		"admin changing log level": {
+			roles:      []Role{RoleAdmin},
+			permission: PermissionLogLevelWrite,
+		},
+		"support with rider role renting scooter": {
+			roles:      []Role{RoleSupport, RoleRider},
+			permission: PermissionScootersRent,
+		},
+		"operator renting scooter": {
+			roles:      []Role{RoleOperator},
+			permission: PermissionScootersRent,
+			wantErr:    ErrPermissionDenied,
+		},
+		"support changing log level": {
+			roles:      []Role{RoleSupport},
+			permission: PermissionLogLevelWrite,
+			wantErr:    ErrPermissionDenied,
+		},
+		"device reporting telemetry": {
+			roles:      []Role{RoleDevice},
+			permission: PermissionTelemetryWrite,
+		},
+		"device renting scooter": {
+			roles:      []Role{RoleDevice},
+			permission: PermissionScootersRent,
+			wantErr:    ErrPermissionDenied,
+		},
+		"rider reporting telemetry": {
+			roles:      []Role{RoleRider},
+			permission: PermissionTelemetryWrite,
+			wantErr:    ErrPermissionDenied,
+		},
+		"identity without roles reading scooters": {
+			roles:      nil,
+			permission: PermissionScootersRead,
+			wantErr:    ErrPermissionDenied,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			identity := &Identity{Subject: uuid.New(), Roles: tt.roles}
+
+			if err := identity.Authorize(tt.permission); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdentityAuthorizeCity(t *testing.T) {
+	tests := map[string]struct {
+		roles   []Role
+		cities  []string
+		city    string
+		wantErr error
+	}{
+		"operator in own city": {
+			roles:  []Role{RoleOperator},
+			cities: []string{"Ottawa"},
+			city:   "ottawa",
+		},
+		"operator without cities": {
+			roles: []Role{RoleOperator},
+			city:  "Montreal",
+		},
+		"admin scoped to other city": {
+			roles:  []Role{RoleAdmin},
+			cities: []string{"Ottawa"},
+			city:   "Montreal",
+		},
+		"rider scoped to other city": {
+			roles:  []Role{RoleRider},
+			cities: []string{"Ottawa"},
+			city:   "Montreal",
+		},
+		"operator in other city": {
+			roles:   []Role{RoleOperator},
+			cities:  []string{"Ottawa"},
+			city:    "Montreal",
+			wantErr: ErrPermissionDenied,
+		},
+		"operator and rider in other city": {
+			roles:   []Role{RoleRider, RoleOperator},
+			cities:  []string{"Ottawa"},
+			city:    "Montreal",
+			wantErr: ErrPermissionDenied,
+		},
+		"support and rider in other city": {
+			roles:   []Role{RoleSupport, RoleRider},
+			cities:  []string{"Ottawa"},
+			city:    "Montreal",
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			identity := &Identity{Subject: uuid.New(), Roles: tt.roles, Cities: tt.cities}
+
+			if err := identity.AuthorizeCity(tt.city); !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeCity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdentityAuthorizeScooter(t *testing.T) {
+	scooterUUID := uuid.New()
+
+	tests := map[string]struct {
+		subject uuid.UUID
+		roles   []Role
+		wantErr error
+	}{
+		"device acting as itself": {
+			subject: scooterUUID,
+			roles:   []Role{RoleDevice},
+		},
+		"admin acting as scooter": {
+			subject: uuid.Nil,
+			roles:   []Role{RoleAdmin},
+		},
+		"device acting as other scooter": {
+			subject: uuid.New(),
+			roles:   []Role{RoleDevice},
+			wantErr: ErrPermissionDenied,
+		},
+		"rider with subject of the scooter": {
+			subject: scooterUUID,
+			roles:   []Role{RoleRider},
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			identity := &Identity{Subject: tt.subject, Roles: tt.roles}
+
+			if err := identity.AuthorizeScooter(scooterUUID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeScooter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRoles(t *testing.T) {
+	roles := ParseRoles([]string{"Operator", "billing", "admin"})
+
+	if len(roles) != 2 || roles[0] != RoleOperator || roles[1] != RoleAdmin {
+		t.Errorf("ParseRoles() = %v, want [%s %s]", roles, RoleOperator, RoleAdmin)
+	}
+}
@@ -2,18 +2,40 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"github.com/sethvargo/go-envconfig"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
 )
 
+var ErrInvalidConfig = errors.New("invalid config")
+
 type Config struct {
-	HTTP  int    `env:"HTTP,required"`
-	Name  string `env:"NAME,required"`
-	Users string `env:"USERS,required"`
-	Redis Redis  `env:",prefix=REDIS_"`
+	HTTP       int    `env:"HTTP,required"`
+	GRPC       int    `env:"GRPC,default=9091"`
+	Name       string `env:"NAME,required"`
+	Users      string `env:"USERS,required"`
+	AdminToken string `env:"ADMIN_TOKEN"`
+	Redis      Redis  `env:",prefix=REDIS_"`
+	Log        Log    `env:",prefix=LOG_"`
+
+	Resilience Resilience `env:",prefix=RESILIENCE_"`
+	Health     Health     `env:",prefix=HEALTH_"`
+	Auth       Auth       `env:",prefix=AUTH_"`
+	RateLimit  RateLimit  `env:",prefix=RATE_LIMIT_"`
+	Fare       Fare       `env:",prefix=FARE_"`
+	Webhook    Webhook    `env:",prefix=WEBHOOK_"`
+	Events     Events     `env:",prefix=EVENTS_"`
+	Tracking   Tracking   `env:",prefix=TRACKING_"`
+	MQTT       MQTT       `env:",prefix=MQTT_"`
 }
 
 type Redis struct {
@@ -22,20 +44,232 @@ type Redis struct {
 	Database int    `env:"DATABASE"`
 }
 
+type Log struct {
+	Format         string `env:"FORMAT,default=text"`
+	Level          string `env:"LEVEL,default=info"`
+	File           string `env:"FILE"`
+	FileMaxSizeMB  int    `env:"FILE_MAX_SIZE_MB,default=100"`
+	FileMaxBackups int    `env:"FILE_MAX_BACKUPS,default=5"`
+	FileMaxAgeDays int    `env:"FILE_MAX_AGE_DAYS,default=28"`
+}
+
+type Resilience struct {
+	RetryAttempts           int           `env:"RETRY_ATTEMPTS,default=3"`
+	RetryInitialBackoff     time.Duration `env:"RETRY_INITIAL_BACKOFF,default=50ms"`
+	RetryMaxBackoff         time.Duration `env:"RETRY_MAX_BACKOFF,default=1s"`
+	BreakerFailureThreshold int           `env:"BREAKER_FAILURE_THRESHOLD,default=5"`
+	BreakerOpenTimeout      time.Duration `env:"BREAKER_OPEN_TIMEOUT,default=10s"`
+}
+
+type Health struct {
+	CheckTimeout time.Duration `env:"CHECK_TIMEOUT,default=2s"`
+	DrainDelay   time.Duration `env:"DRAIN_DELAY,default=5s"`
+}
+
+// Auth configures how riders authenticate. Bearer tokens are verified with the HMAC key, the PEM public keys or
+// the JWKS file, the Client-Id header is only accepted when explicitly allowed.
+type Auth struct {
+	JWTIssuer           string        `env:"JWT_ISSUER"`
+	JWTAudience         string        `env:"JWT_AUDIENCE"`
+	JWTHMACKeyFile      string        `env:"JWT_HMAC_KEY_FILE"`
+	JWTPublicKeyFiles   []string      `env:"JWT_PUBLIC_KEY_FILES"`
+	JWTJWKSFile         string        `env:"JWT_JWKS_FILE"`
+	JWTLeeway           time.Duration `env:"JWT_LEEWAY,default=30s"`
+	AllowClientIDHeader bool          `env:"ALLOW_CLIENT_ID_HEADER,default=false"`
+}
+
+// JWTEnabled tells whether any key for verifying bearer tokens is configured.
+func (a *Auth) JWTEnabled() bool {
+	return a.JWTHMACKeyFile != "" || len(a.JWTPublicKeyFiles) > 0 || a.JWTJWKSFile != ""
+}
+
+// RateLimit configures how many requests each role may send within the window, separately for searching and for
+// changing the state. Roles left out are not limited.
+type RateLimit struct {
+	Enabled  bool           `env:"ENABLED,default=true"`
+	Window   time.Duration  `env:"WINDOW,default=1m"`
+	Search   map[string]int `env:"SEARCH,default=rider:60,operator:600,support:600"`
+	Mutation map[string]int `env:"MUTATION,default=rider:20,operator:120,support:60"`
+}
+
+// Fare configures the price of the rides, in the minor units of the currency: the unlock fee is charged once per
+// ride and the per-minute price for every started minute of it.
+type Fare struct {
+	UnlockFee int64  `env:"UNLOCK_FEE,default=100"`
+	PerMinute int64  `env:"PER_MINUTE,default=35"`
+	Currency  string `env:"CURRENCY,default=CAD"`
+}
+
+// Webhook configures the deliveries to the webhook subscriptions: the timeout of a single attempt and the retries
+// made before the delivery is written to the dead letters.
+type Webhook struct {
+	Timeout             time.Duration `env:"TIMEOUT,default=5s"`
+	RetryAttempts       int           `env:"RETRY_ATTEMPTS,default=5"`
+	RetryInitialBackoff time.Duration `env:"RETRY_INITIAL_BACKOFF,default=1s"`
+	RetryMaxBackoff     time.Duration `env:"RETRY_MAX_BACKOFF,default=30s"`
+}
+
+// Events configures the Redis stream carrying the domain events. The consumer names the instance within the consumer
+// groups and must stay the same across its restarts, so the events it left unacknowledged are handled again; it
+// defaults to the hostname. The events left by the instances gone for longer than the claim idle time are taken over
+// by the others.
+type Events struct {
+	Stream        string        `env:"STREAM,default=domain_events"`
+	MaxLen        int64         `env:"MAX_LEN,default=100000"`
+	Consumer      string        `env:"CONSUMER"`
+	ClaimMinIdle  time.Duration `env:"CLAIM_MIN_IDLE,default=1m"`
+	DedupTTL      time.Duration `env:"DEDUP_TTL,default=24h"`
+	RelayInterval time.Duration `env:"RELAY_INTERVAL,default=200ms"`
+}
+
+// Tracking chooses where the positions of the rented scooters come from: the tracker simulates the rides or the
+// scooters report their telemetry. The telemetry timestamped further ahead of the clock than the max clock skew is
+// rejected. The routes of the rentals are simplified by dropping the points closer than the route tolerance in meters
+// to the line of the others, 0 keeps all the points.
+type Tracking struct {
+	Mode           string        `env:"MODE,default=simulated"`
+	MaxClockSkew   time.Duration `env:"MAX_CLOCK_SKEW,default=30s"`
+	RouteTolerance float64       `env:"ROUTE_TOLERANCE,default=5"`
+}
+
+// MQTT configures the connection to the broker the scooters talk to. The topics hold the {scooterID} level standing
+// for the ID of the scooter. The telemetry is read as a shared subscription of the group, so every report is handled
+// by one of the instances only, an empty group subscribes every instance to all the reports. The timeout bounds
+// handling a report and publishing a command.
+type MQTT struct {
+	Enabled        bool          `env:"ENABLED,default=false"`
+	Broker         string        `env:"BROKER"`
+	Username       string        `env:"USERNAME"`
+	Password       string        `env:"PASSWORD"`
+	TelemetryTopic string        `env:"TELEMETRY_TOPIC,default=scooters/{scooterID}/telemetry"`
+	TelemetryQoS   byte          `env:"TELEMETRY_QOS,default=1"`
+	CommandTopic   string        `env:"COMMAND_TOPIC,default=scooters/{scooterID}/commands"`
+	CommandQoS     byte          `env:"COMMAND_QOS,default=1"`
+	ShareGroup     string        `env:"SHARE_GROUP,default=scooter-rental"`
+	Timeout        time.Duration `env:"TIMEOUT,default=10s"`
+}
+
 func NewConfig(ctx context.Context, configPath string) (*Config, error) {
-	if err := godotenv.Load(configPath); err != nil {
+	fileVars, err := godotenv.Read(configPath)
+	if err != nil {
 		return nil, fmt.Errorf("loading config files: %w", err)
 	}
 
 	var c Config
 
-	if err := envconfig.Process(ctx, &c); err != nil {
+	// the environment takes precedence over the file, which is not loaded into the environment, so reading
+	// the file again picks up its changes
+	lookuper := envconfig.MultiLookuper(envconfig.OsLookuper(), envconfig.MapLookuper(fileVars))
+
+	if err = envconfig.ProcessWith(ctx, &c, lookuper); err != nil {
 		return nil, fmt.Errorf("processing environment config: %w", err)
 	}
 
+	if err = c.Validate(); err != nil {
+		return nil, fmt.Errorf("validating config: %w", err)
+	}
+
 	return &c, nil
 }
 
+// Validate checks the values that can't be verified by the env tags alone.
+func (c *Config) Validate() error {
+	switch strings.ToLower(c.Log.Format) {
+	case "text", "json":
+	default:
+		return fmt.Errorf("log format %q is neither text nor json: %w", c.Log.Format, ErrInvalidConfig)
+	}
+
+	for userID := range c.GetUsersMap() {
+		if _, err := uuid.Parse(userID); err != nil {
+			return fmt.Errorf("user %q: %w", userID, errors.Join(err, ErrInvalidConfig))
+		}
+	}
+
+	switch c.Tracking.Mode {
+	case "simulated", "device":
+	default:
+		return fmt.Errorf("tracking mode %q is neither simulated nor device: %w", c.Tracking.Mode, ErrInvalidConfig)
+	}
+
+	if c.Tracking.RouteTolerance < 0 {
+		return fmt.Errorf("route tolerance %v is negative: %w", c.Tracking.RouteTolerance, ErrInvalidConfig)
+	}
+
+	if !c.Auth.JWTEnabled() && !c.Auth.AllowClientIDHeader {
+		return fmt.Errorf("neither jwt keys nor the client id header are configured for auth: %w", ErrInvalidConfig)
+	}
+
+	if c.Auth.JWTEnabled() && (c.Auth.JWTIssuer == "" || c.Auth.JWTAudience == "") {
+		return fmt.Errorf("jwt issuer and audience are required: %w", ErrInvalidConfig)
+	}
+
+	if err := c.RateLimit.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Fare.validate(); err != nil {
+		return err
+	}
+
+	if err := c.MQTT.validate(); err != nil {
+		return err
+	}
+
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		return fmt.Errorf("log level %q: %w", c.Log.Level, errors.Join(err, ErrInvalidConfig))
+	}
+
+	return nil
+}
+
+func (rl *RateLimit) validate() error {
+	if rl.Enabled && rl.Window <= 0 {
+		return fmt.Errorf("rate limit window %v is not positive: %w", rl.Window, ErrInvalidConfig)
+	}
+
+	for _, limits := range []map[string]int{rl.Search, rl.Mutation} {
+		for role, requests := range limits {
+			if !auth.Role(role).Known() {
+				return fmt.Errorf("rate limit of unknown role %q: %w", role, ErrInvalidConfig)
+			}
+
+			if requests <= 0 {
+				return fmt.Errorf("rate limit of role %q is not positive: %w", role, ErrInvalidConfig)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Fare) validate() error {
+	if f.UnlockFee < 0 || f.PerMinute < 0 {
+		return fmt.Errorf("fare prices %d and %d are negative: %w", f.UnlockFee, f.PerMinute, ErrInvalidConfig)
+	}
+
+	if len(f.Currency) != 3 || strings.ToUpper(f.Currency) != f.Currency {
+		return fmt.Errorf("fare currency %q is not an ISO 4217 code: %w", f.Currency, ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// GetUserIDs returns the sorted IDs of the users listed in the config.
+func (c *Config) GetUserIDs() []string {
+	userIDs := make([]string, 0, len(c.GetUsersMap()))
+
+	for userID := range c.GetUsersMap() {
+		userIDs = append(userIDs, userID)
+	}
+
+	sort.Strings(userIDs)
+
+	return userIDs
+}
+
 func (c *Config) GetUsersMap() map[string]bool {
 	result := make(map[string]bool)
 
@@ -47,3 +281,45 @@ func (c *Config) GetUsersMap() map[string]bool {
 
 	return result
 }
+
+func (m *MQTT) validate() error {
+	if !m.Enabled {
+		return nil
+	}
+
+	if m.Broker == "" {
+		return fmt.Errorf("mqtt broker is required: %w", ErrInvalidConfig)
+	}
+
+	for _, topic := range []string{m.TelemetryTopic, m.CommandTopic} {
+		if !isScooterTopic(topic) {
+			return fmt.Errorf(
+				"mqtt topic %q needs a single {scooterID} level and no wildcards: %w",
+				topic,
+				ErrInvalidConfig,
+			)
+		}
+	}
+
+	if m.TelemetryQoS > 2 || m.CommandQoS > 2 {
+		return fmt.Errorf("mqtt qos %d and %d are not 0, 1 or 2: %w", m.TelemetryQoS, m.CommandQoS, ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// isScooterTopic tells whether exactly one level of the topic is the ID of the scooter and none is a wildcard.
+func isScooterTopic(topic string) bool {
+	var scooterLevels int
+
+	for _, level := range strings.Split(topic, "/") {
+		switch {
+		case level == "{scooterID}":
+			scooterLevels++
+		case strings.ContainsAny(level, "+#{}"):
+			return false
+		}
+	}
+
+	return scooterLevels == 1
+}
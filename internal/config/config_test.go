@@ -6,6 +6,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -18,11 +19,71 @@ func TestNewConfig(t *testing.T) {
 			configPath: "test_vars/valid_vars.env",
 			want: &Config{
 				HTTP:  8081,
+				GRPC:  9091,
 				Name:  "scootin_aboot",
 				Users: "8212d8ba-74d1-49af-8a84-6d6c392ec71c,897737a8-77f1-4f53-8a51-6f9edaee6ed9",
 				Redis: Redis{
 					Host: "redis:6379",
 				},
+				Log: Log{
+					Format:         "text",
+					Level:          "info",
+					FileMaxSizeMB:  100,
+					FileMaxBackups: 5,
+					FileMaxAgeDays: 28,
+				},
+				Resilience: Resilience{
+					RetryAttempts:           3,
+					RetryInitialBackoff:     50 * time.Millisecond,
+					RetryMaxBackoff:         time.Second,
+					BreakerFailureThreshold: 5,
+					BreakerOpenTimeout:      10 * time.Second,
+				},
+				Health: Health{
+					CheckTimeout: 2 * time.Second,
+					DrainDelay:   5 * time.Second,
+				},
+				Auth: Auth{
+					JWTLeeway:           30 * time.Second,
+					AllowClientIDHeader: true,
+				},
+				RateLimit: RateLimit{
+					Enabled:  true,
+					Window:   time.Minute,
+					Search:   map[string]int{"rider": 60, "operator": 600, "support": 600},
+					Mutation: map[string]int{"rider": 20, "operator": 120, "support": 60},
+				},
+				Fare: Fare{
+					UnlockFee: 100,
+					PerMinute: 35,
+					Currency:  "CAD",
+				},
+				Webhook: Webhook{
+					Timeout:             5 * time.Second,
+					RetryAttempts:       5,
+					RetryInitialBackoff: time.Second,
+					RetryMaxBackoff:     30 * time.Second,
+				},
+				Events: Events{
+					Stream:        "domain_events",
+					MaxLen:        100000,
+					ClaimMinIdle:  time.Minute,
+					DedupTTL:      24 * time.Hour,
+					RelayInterval: 200 * time.Millisecond,
+				},
+				Tracking: Tracking{
+					Mode:           "simulated",
+					MaxClockSkew:   30 * time.Second,
+					RouteTolerance: 5,
+				},
+				MQTT: MQTT{
+					TelemetryTopic: "scooters/{scooterID}/telemetry",
+					TelemetryQoS:   1,
+					CommandTopic:   "scooters/{scooterID}/commands",
+					CommandQoS:     1,
+					ShareGroup:     "scooter-rental",
+					Timeout:        10 * time.Second,
+				},
 			},
 			wantErr: false,
 		},
@@ -31,6 +92,36 @@ func TestNewConfig(t *testing.T) {
 			want:       nil,
 			wantErr:    true,
 		},
+		"failed run because of invalid log format": {
+			configPath: "test_vars/invalid_log_vars.env",
+			want:       nil,
+			wantErr:    true,
+		},
+		"failed run because of rate limit of unknown role": {
+			configPath: "test_vars/invalid_rate_limit_vars.env",
+			want:       nil,
+			wantErr:    true,
+		},
+		"failed run because of invalid fare currency": {
+			configPath: "test_vars/invalid_fare_vars.env",
+			want:       nil,
+			wantErr:    true,
+		},
+		"failed run because of jwt keys without issuer and audience": {
+			configPath: "test_vars/invalid_auth_vars.env",
+			want:       nil,
+			wantErr:    true,
+		},
+		"failed run because of unknown tracking mode": {
+			configPath: "test_vars/invalid_tracking_vars.env",
+			want:       nil,
+			wantErr:    true,
+		},
+		"failed run because of mqtt topic without scooter id": {
+			configPath: "test_vars/invalid_mqtt_vars.env",
+			want:       nil,
+			wantErr:    true,
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
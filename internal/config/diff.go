@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	usersSetting = "USERS"
+	prefixOption = "prefix="
+)
+
+// secretSettings are reported as changed without revealing their values.
+var secretSettings = []string{"PASSWORD", "TOKEN", "SECRET"}
+
+// Diff describes every setting that differs between the two configs, named after its environment variable.
+func Diff(previous, current *Config) []string {
+	var changes []string
+
+	diffStruct(reflect.ValueOf(*previous), reflect.ValueOf(*current), "", &changes)
+
+	if added, removed := DiffUsers(previous, current); len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("%s: added %v, removed %v", usersSetting, added, removed))
+	}
+
+	return changes
+}
+
+// DiffUsers returns the sorted IDs of the users added to and removed from the current config.
+func DiffUsers(previous, current *Config) (added, removed []string) {
+	previousUsers := previous.GetUsersMap()
+	currentUsers := current.GetUsersMap()
+
+	for userID := range currentUsers {
+		if !previousUsers[userID] {
+			added = append(added, userID)
+		}
+	}
+
+	for userID := range previousUsers {
+		if !currentUsers[userID] {
+			removed = append(removed, userID)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
+
+func diffStruct(previous, current reflect.Value, prefix string, changes *[]string) {
+	for i := 0; i < previous.NumField(); i++ {
+		field := previous.Type().Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("env"), ",")
+
+		if field.Type.Kind() == reflect.Struct {
+			nestedPrefix, _ := strings.CutPrefix(options, prefixOption)
+
+			diffStruct(previous.Field(i), current.Field(i), prefix+nestedPrefix, changes)
+
+			continue
+		}
+
+		setting := prefix + name
+
+		if setting == usersSetting || reflect.DeepEqual(previous.Field(i).Interface(), current.Field(i).Interface()) {
+			continue
+		}
+
+		if isSecret(setting) {
+			*changes = append(*changes, fmt.Sprintf("%s: changed", setting))
+
+			continue
+		}
+
+		*changes = append(*changes, fmt.Sprintf(
+			"%s: %v -> %v",
+			setting,
+			previous.Field(i).Interface(),
+			current.Field(i).Interface(),
+		))
+	}
+}
+
+func isSecret(setting string) bool {
+	for _, secret := range secretSettings {
+		if strings.Contains(setting, secret) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+)
+
+// reloadDebounce groups the several file events editors emit for a single save into one reload.
+const reloadDebounce = 100 * time.Millisecond
+
+// ChangeFunc is called with the previous and the newly loaded config after every successful reload.
+type ChangeFunc func(ctx context.Context, previous, current *Config)
+
+// Watcher reloads the config file whenever it changes on disk or the process receives SIGHUP. A config that fails
+// loading or validation is rejected and the previous one stays in use.
+type Watcher struct {
+	configPath string
+	onChange   ChangeFunc
+
+	mu      sync.Mutex
+	current *Config
+}
+
+func NewWatcher(configPath string, initial *Config, onChange ChangeFunc) *Watcher {
+	return &Watcher{
+		configPath: configPath,
+		onChange:   onChange,
+		current:    initial,
+	}
+}
+
+// Current returns the config in use.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Run watches for changes until the context is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	fileWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+
+	defer fileWatcher.Close()
+
+	// watching the directory instead of the file survives editors replacing the file on save
+	if err = fileWatcher.Add(filepath.Dir(w.configPath)); err != nil {
+		return fmt.Errorf("watching config directory: %w", err)
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGHUP)
+
+	defer signal.Stop(signalChan)
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+
+	configFile := filepath.Clean(w.configPath)
+	logger := logging.FromContext(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fileWatcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if filepath.Clean(event.Name) == configFile && !event.Has(fsnotify.Chmod) {
+				debounce.Reset(reloadDebounce)
+			}
+		case watchErr, ok := <-fileWatcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			logger.Error("failed watching config file", slog.Any("err", watchErr))
+		case <-signalChan:
+			logger.Info("Received SIGHUP, reloading config.")
+
+			_ = w.Reload(ctx)
+		case <-debounce.C:
+			logger.Info("Config file changed, reloading config.")
+
+			_ = w.Reload(ctx)
+		}
+	}
+}
+
+// Reload loads the config file again and, if it is valid, swaps it with the current one and notifies about
+// the change.
+func (w *Watcher) Reload(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	current, err := NewConfig(ctx, w.configPath)
+	if err != nil {
+		logger.Error("failed to reload config, keeping the previous one", slog.Any("err", err))
+
+		return fmt.Errorf("reloading config: %w", err)
+	}
+
+	w.mu.Lock()
+	previous := w.current
+	w.current = current
+	w.mu.Unlock()
+
+	changes := Diff(previous, current)
+	if len(changes) == 0 {
+		logger.Info("Reloaded config without changes.")
+
+		return nil
+	}
+
+	logger.Info("Reloaded config.", slog.Any("changes", changes))
+
+	w.onChange(ctx, previous, current)
+
+	return nil
+}
@@ -0,0 +1,152 @@
+//go:build unit
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFirstUser  = "8212d8ba-74d1-49af-8a84-6d6c392ec71c"
+	testSecondUser = "897737a8-77f1-4f53-8a51-6f9edaee6ed9"
+
+	testConfigTemplate = "HTTP=8081\nNAME=scootin_aboot\nUSERS=%s\nREDIS_HOST=redis:6379\nLOG_LEVEL=%s\n" +
+		"AUTH_ALLOW_CLIENT_ID_HEADER=true\n"
+)
+
+func TestWatcherReload(t *testing.T) {
+	tests := map[string]struct {
+		newConfig   string
+		wantUsers   string
+		wantChanged bool
+		wantErr     bool
+	}{
+		"reloaded changed config": {
+			newConfig:   buildTestConfig(testFirstUser+","+testSecondUser, "debug"),
+			wantUsers:   testFirstUser + "," + testSecondUser,
+			wantChanged: true,
+			wantErr:     false,
+		},
+		"reloaded config without changes": {
+			newConfig:   buildTestConfig(testFirstUser, "info"),
+			wantUsers:   testFirstUser,
+			wantChanged: false,
+			wantErr:     false,
+		},
+		"kept previous config, because the new one failed validation": {
+			newConfig:   buildTestConfig("not-a-uuid", "info"),
+			wantUsers:   testFirstUser,
+			wantChanged: false,
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			configPath := writeTestConfig(t, buildTestConfig(testFirstUser, "info"))
+
+			initial, err := NewConfig(ctx, configPath)
+			require.NoError(t, err)
+
+			var changed bool
+
+			watcher := NewWatcher(configPath, initial, func(ctx context.Context, previous, current *Config) {
+				changed = true
+			})
+
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.newConfig), 0o600))
+
+			if err = watcher.Reload(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if changed != tt.wantChanged {
+				t.Errorf("Reload() notified about change = %v, want %v", changed, tt.wantChanged)
+			}
+
+			if got := watcher.Current().Users; got != tt.wantUsers {
+				t.Errorf("Current() users = %v, want %v", got, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestWatcherRunReloadsOnFileChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configPath := writeTestConfig(t, buildTestConfig(testFirstUser, "info"))
+
+	initial, err := NewConfig(ctx, configPath)
+	require.NoError(t, err)
+
+	reloaded := make(chan *Config, 1)
+
+	watcher := NewWatcher(configPath, initial, func(ctx context.Context, previous, current *Config) {
+		reloaded <- current
+	})
+
+	go func() {
+		_ = watcher.Run(ctx)
+	}()
+
+	// give the watcher time to start watching the directory
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(buildTestConfig(testSecondUser, "info")), 0o600))
+
+	select {
+	case current := <-reloaded:
+		if current.Users != testSecondUser {
+			t.Errorf("Run() reloaded users = %v, want %v", current.Users, testSecondUser)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Run() did not reload the changed config")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	previous := &Config{
+		Users:      testFirstUser,
+		AdminToken: "old-token",
+		Log:        Log{Level: "info", Format: "text"},
+	}
+
+	current := &Config{
+		Users:      testSecondUser,
+		AdminToken: "new-token",
+		Log:        Log{Level: "debug", Format: "text"},
+	}
+
+	want := []string{
+		"ADMIN_TOKEN: changed",
+		"LOG_LEVEL: info -> debug",
+		"USERS: added [" + testSecondUser + "], removed [" + testFirstUser + "]",
+	}
+
+	if got := Diff(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
+
+func buildTestConfig(users, logLevel string) string {
+	return fmt.Sprintf(testConfigTemplate, users, logLevel)
+}
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "vars.env")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+
+	return configPath
+}
@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+)
+
+// Relay publishes the events recorded in the outbox to the bus in the order they were recorded, run next to the
+// HTTP server. The entry is removed from the outbox only after its event is published, so the events recorded
+// before a crash are published after the restart, and the event published but not removed is published again and
+// skipped by the consumers that have handled it.
+type Relay struct {
+	logger    *slog.Logger
+	outbox    service.OutboxRepository
+	publisher service.EventPublisher
+	retrier   *resilience.Retrier
+	// interval is how long the relay waits before it reads the outbox again after finding it empty or failing.
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func NewRelay(
+	logger *slog.Logger,
+	outbox service.OutboxRepository,
+	publisher service.EventPublisher,
+	retrier *resilience.Retrier,
+	interval time.Duration,
+) *Relay {
+	ctx, cancel := context.WithCancel(logging.WithLogger(context.Background(), logger))
+
+	return &Relay{
+		logger:    logger,
+		outbox:    outbox,
+		publisher: publisher,
+		retrier:   retrier,
+		interval:  interval,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Serve relays the events until the relay is shut down.
+func (r *Relay) Serve() error {
+	r.logger.Info("Relaying events from the outbox.")
+
+	for r.ctx.Err() == nil {
+		relayed, err := r.relay(r.ctx)
+		if err != nil && r.ctx.Err() == nil {
+			r.logger.Warn("Failed to relay the events.", slog.Any("err", err))
+		}
+
+		if relayed < readCount || err != nil {
+			sleep(r.ctx, r.interval)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops the relay, the event being published is left in the outbox to be published after the restart.
+func (r *Relay) Shutdown() {
+	r.cancel()
+}
+
+// relay publishes a batch of the oldest events of the outbox, stopping at the first one that keeps failing, so the
+// events are not published out of order. It returns the number of the events published.
+func (r *Relay) relay(ctx context.Context) (int, error) {
+	entries, err := r.outbox.GetOutboxEntries(ctx, readCount)
+	if err != nil {
+		return 0, fmt.Errorf("reading outbox: %w", err)
+	}
+
+	for i, entry := range entries {
+		if err = r.retrier.Do(ctx, func(ctx context.Context) error {
+			return r.publisher.Publish(ctx, entry.Event)
+		}, func(error) bool {
+			return true
+		}); err != nil {
+			return i, fmt.Errorf("publishing event %s: %w", entry.Event.UUID, err)
+		}
+
+		if err = r.outbox.DeleteOutboxEntry(ctx, entry.ID); err != nil {
+			return i, fmt.Errorf("removing published event %s from outbox: %w", entry.Event.UUID, err)
+		}
+	}
+
+	return len(entries), nil
+}
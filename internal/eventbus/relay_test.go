@@ -0,0 +1,95 @@
+//go:build unit
+
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+)
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	first := &model.OutboxEntry{ID: "1-0", Event: newTestEvent()}
+	second := &model.OutboxEntry{ID: "2-0", Event: newTestEvent()}
+
+	tests := map[string]struct {
+		mockHandler func(outbox *mock.MockOutboxRepository, publisher *mock.MockEventPublisher)
+		wantRelayed int
+		wantErr     bool
+	}{
+		"successfully relayed events in order": {
+			mockHandler: func(outbox *mock.MockOutboxRepository, publisher *mock.MockEventPublisher) {
+				gomock.InOrder(
+					outbox.EXPECT().GetOutboxEntries(ctx, int64(readCount)).
+						Return([]*model.OutboxEntry{first, second}, nil),
+					publisher.EXPECT().Publish(ctx, first.Event).Return(nil),
+					outbox.EXPECT().DeleteOutboxEntry(ctx, first.ID).Return(nil),
+					publisher.EXPECT().Publish(ctx, second.Event).Return(nil),
+					outbox.EXPECT().DeleteOutboxEntry(ctx, second.ID).Return(nil),
+				)
+			},
+			wantRelayed: 2,
+			wantErr:     false,
+		},
+		"successfully relayed event after retrying the publishing": {
+			mockHandler: func(outbox *mock.MockOutboxRepository, publisher *mock.MockEventPublisher) {
+				gomock.InOrder(
+					outbox.EXPECT().GetOutboxEntries(ctx, int64(readCount)).
+						Return([]*model.OutboxEntry{first}, nil),
+					publisher.EXPECT().Publish(ctx, first.Event).Return(errors.New("redis down")),
+					publisher.EXPECT().Publish(ctx, first.Event).Return(nil),
+					outbox.EXPECT().DeleteOutboxEntry(ctx, first.ID).Return(nil),
+				)
+			},
+			wantRelayed: 1,
+			wantErr:     false,
+		},
+		"stopped relaying at the event failing to be published, keeping it in the outbox": {
+			mockHandler: func(outbox *mock.MockOutboxRepository, publisher *mock.MockEventPublisher) {
+				outbox.EXPECT().GetOutboxEntries(ctx, int64(readCount)).
+					Return([]*model.OutboxEntry{first, second}, nil).Times(1)
+				publisher.EXPECT().Publish(ctx, first.Event).Return(errors.New("redis down")).Times(2)
+			},
+			wantRelayed: 0,
+			wantErr:     true,
+		},
+		"failed relaying events, because the outbox couldn't be read": {
+			mockHandler: func(outbox *mock.MockOutboxRepository, _ *mock.MockEventPublisher) {
+				outbox.EXPECT().GetOutboxEntries(ctx, int64(readCount)).
+					Return(nil, errors.New("redis down")).Times(1)
+			},
+			wantRelayed: 0,
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockOutbox := mock.NewMockOutboxRepository(controller)
+			mockPublisher := mock.NewMockEventPublisher(controller)
+
+			tt.mockHandler(mockOutbox, mockPublisher)
+
+			r := NewRelay(slog.Default(), mockOutbox, mockPublisher, newTestRetrier(), time.Millisecond)
+
+			relayed, err := r.relay(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("relay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.wantRelayed, relayed)
+		})
+	}
+}
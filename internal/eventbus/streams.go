@@ -0,0 +1,366 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+const (
+	fieldType  = "type"
+	fieldEvent = "event"
+
+	// readCount is the number of the entries read from the stream at once.
+	readCount = 16
+	// blockTimeout is how long the read waits for new entries, bounding the time the stop of the consumer and
+	// the claim of the abandoned entries wait for.
+	blockTimeout = 5 * time.Second
+	// readFromStart reads the entries delivered to the consumer but not acknowledged, from the oldest one.
+	readFromStart = "0"
+	// readLatest creates the group reading only the entries added after it.
+	readLatest = "$"
+	// readNew reads the entries never delivered to the group.
+	readNew = ">"
+
+	errBusyGroup = "BUSYGROUP"
+
+	handledEventKeyPrefix = "handled_event:"
+)
+
+// record is the layout of the event stored as JSON in the entry of the stream.
+type record struct {
+	UUID        uuid.UUID `json:"uuid"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	RentalUUID  uuid.UUID `json:"rental_uuid"`
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	ScooterUUID uuid.UUID `json:"scooter_uuid"`
+	City        string    `json:"city"`
+	Longitude   float64   `json:"longitude"`
+	Latitude    float64   `json:"latitude"`
+}
+
+// streamBus is the event bus on top of a Redis stream. Each consumer group reads every event once and acknowledges
+// it after handling, the entries left unacknowledged by the consumers gone for longer than the claim idle time are
+// claimed by the others. The stream is trimmed to about the max length.
+//
+// The UUIDs of the events handled by the group are remembered for the dedup TTL, so the event published again,
+// e.g. by the outbox relay stopped before it removed the published entry, is skipped.
+type streamBus struct {
+	client       *redis.Client
+	stream       string
+	maxLen       int64
+	claimMinIdle time.Duration
+	dedupTTL     time.Duration
+	// retrier retries the failed handler before the event is given up on.
+	retrier *resilience.Retrier
+}
+
+func NewStreamBus(
+	client *redis.Client,
+	stream string,
+	maxLen int64,
+	claimMinIdle, dedupTTL time.Duration,
+	retrier *resilience.Retrier,
+) *streamBus {
+	return &streamBus{
+		client:       client,
+		stream:       stream,
+		maxLen:       maxLen,
+		claimMinIdle: claimMinIdle,
+		dedupTTL:     dedupTTL,
+		retrier:      retrier,
+	}
+}
+
+func handledEventKey(group string, eventUUID uuid.UUID) string {
+	return handledEventKeyPrefix + group + ":" + eventUUID.String()
+}
+
+func (sb *streamBus) Publish(ctx context.Context, event *model.Event) error {
+	values, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+
+	if err = sb.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: sb.stream,
+		MaxLen: sb.maxLen,
+		Approx: true,
+		Values: values,
+	}).Err(); err != nil {
+		return fmt.Errorf("adding event to redis stream: %w", err)
+	}
+
+	return nil
+}
+
+func (sb *streamBus) Consume(
+	ctx context.Context,
+	group, consumer string,
+	start service.GroupStart,
+	handler service.EventHandler,
+) error {
+	if err := sb.createGroup(ctx, group, start); err != nil {
+		return err
+	}
+
+	ctxLogger := logging.FromContext(ctx).With(slog.String("group", group), slog.String("consumer", consumer))
+	ctx = logging.WithLogger(ctx, ctxLogger)
+
+	// the entries delivered before the restart of the consumer are handled before the new ones
+	if err := sb.consumePending(ctx, group, consumer, handler); err != nil && ctx.Err() == nil {
+		ctxLogger.Warn("Failed to handle the pending events.", slog.Any("err", err))
+	}
+
+	claimStart := readFromStart
+
+	for ctx.Err() == nil {
+		var err error
+
+		claimStart, err = sb.claimAbandoned(ctx, group, consumer, claimStart, handler)
+		if err != nil && ctx.Err() == nil {
+			ctxLogger.Warn("Failed to claim the abandoned events.", slog.Any("err", err))
+		}
+
+		streams, err := sb.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{sb.stream, readNew},
+			Count:    readCount,
+			Block:    blockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+			continue
+		}
+
+		if err != nil {
+			ctxLogger.Warn("Failed to read the events.", slog.Any("err", err))
+
+			sleep(ctx, blockTimeout)
+
+			continue
+		}
+
+		for _, stream := range streams {
+			sb.handle(ctx, group, stream.Messages, handler)
+		}
+	}
+
+	return nil
+}
+
+// createGroup creates the group reading the stream from the given start, unless it already exists.
+func (sb *streamBus) createGroup(ctx context.Context, group string, start service.GroupStart) error {
+	id := readFromStart
+	if start == service.GroupStartLatest {
+		id = readLatest
+	}
+
+	err := sb.client.XGroupCreateMkStream(ctx, sb.stream, group, id).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), errBusyGroup) {
+		return fmt.Errorf("creating consumer group in redis: %w", err)
+	}
+
+	return nil
+}
+
+// consumePending handles the entries delivered to the consumer but not acknowledged, the oldest first.
+func (sb *streamBus) consumePending(ctx context.Context, group, consumer string, handler service.EventHandler) error {
+	start := readFromStart
+
+	for {
+		streams, err := sb.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{sb.stream, start},
+			Count:    readCount,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("reading pending events from redis: %w", err)
+		}
+
+		if len(streams) == 0 || len(streams[0].Messages) == 0 {
+			return nil
+		}
+
+		messages := streams[0].Messages
+
+		if !sb.handle(ctx, group, messages, handler) {
+			return nil
+		}
+
+		// the scan goes on after the last entry, so the one that failed to be acknowledged is not read again
+		start = messages[len(messages)-1].ID
+	}
+}
+
+// claimAbandoned takes over and handles the entries of the other consumers left unacknowledged for longer than the
+// claim idle time. It returns the ID to continue the scan of the pending entries from.
+func (sb *streamBus) claimAbandoned(
+	ctx context.Context,
+	group, consumer, start string,
+	handler service.EventHandler,
+) (string, error) {
+	messages, next, err := sb.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   sb.stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  sb.claimMinIdle,
+		Start:    start,
+		Count:    readCount,
+	}).Result()
+	if err != nil {
+		return readFromStart, fmt.Errorf("claiming pending events in redis: %w", err)
+	}
+
+	sb.handle(ctx, group, messages, handler)
+
+	return next, nil
+}
+
+// handle hands the entries to the handler and acknowledges them, skipping the events the group has already handled.
+// The handler failing is retried, and the event it keeps failing on is given up on, so it doesn't block the ones
+// after it. It returns false when it was stopped by the done context, leaving the rest of the entries pending.
+func (sb *streamBus) handle(
+	ctx context.Context,
+	group string,
+	messages []redis.XMessage,
+	handler service.EventHandler,
+) bool {
+	ctxLogger := logging.FromContext(ctx)
+
+	for _, message := range messages {
+		messageLogger := ctxLogger.With(slog.String("entry_id", message.ID))
+
+		event, err := unmarshalEvent(message.Values)
+		if err != nil {
+			messageLogger.Error("Failed to decode the event, it is skipped.", slog.Any("err", err))
+		} else if !sb.handleOnce(ctx, group, event, messageLogger, handler) {
+			return false
+		}
+
+		if err = sb.client.XAck(ctx, sb.stream, group, message.ID).Err(); err != nil {
+			messageLogger.Warn("Failed to acknowledge the event.", slog.Any("err", err))
+		}
+	}
+
+	return true
+}
+
+// handleOnce hands the event to the handler, unless the group has already handled it. It returns false when it was
+// stopped by the done context.
+func (sb *streamBus) handleOnce(
+	ctx context.Context,
+	group string,
+	event *model.Event,
+	logger *slog.Logger,
+	handler service.EventHandler,
+) bool {
+	logger = logger.With(slog.String("event_id", event.UUID.String()), slog.String("event_type", string(event.Type)))
+	key := handledEventKey(group, event.UUID)
+
+	// the event is handled when it can't be told whether it already was, the handlers being idempotent
+	handled, err := sb.client.Exists(ctx, key).Result()
+	if err != nil && ctx.Err() == nil {
+		logger.Warn("Failed to check whether the event was handled.", slog.Any("err", err))
+	}
+
+	if handled > 0 {
+		logger.Debug("Skipped the event handled before.")
+
+		return true
+	}
+
+	err = sb.retrier.Do(logging.WithLogger(ctx, logger), func(ctx context.Context) error {
+		return handler(ctx, event)
+	}, func(error) bool {
+		return true
+	})
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		logger.Error("Failed to handle the event, it is skipped.", slog.Any("err", err))
+
+		return true
+	}
+
+	if err = sb.client.Set(ctx, key, 1, sb.dedupTTL).Err(); err != nil {
+		logger.Warn("Failed to mark the event as handled.", slog.Any("err", err))
+	}
+
+	return true
+}
+
+func marshalEvent(event *model.Event) ([]string, error) {
+	eventJSON, err := json.Marshal(record{
+		UUID:        event.UUID,
+		OccurredAt:  event.OccurredAt,
+		RentalUUID:  event.RentalUUID,
+		UserUUID:    event.UserUUID,
+		ScooterUUID: event.ScooterUUID,
+		City:        event.City,
+		Longitude:   event.Longitude,
+		Latitude:    event.Latitude,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling event: %w", err)
+	}
+
+	// the values are given in a slice, so the fields keep their order
+	return []string{fieldType, string(event.Type), fieldEvent, string(eventJSON)}, nil
+}
+
+func unmarshalEvent(values map[string]any) (*model.Event, error) {
+	eventType, ok := values[fieldType].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing %s field of event", fieldType)
+	}
+
+	eventJSON, ok := values[fieldEvent].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing %s field of event", fieldEvent)
+	}
+
+	var r record
+
+	if err := json.Unmarshal([]byte(eventJSON), &r); err != nil {
+		return nil, fmt.Errorf("unmarshaling event: %w", err)
+	}
+
+	return &model.Event{
+		UUID:        r.UUID,
+		Type:        model.Type(eventType),
+		OccurredAt:  r.OccurredAt,
+		RentalUUID:  r.RentalUUID,
+		UserUUID:    r.UserUUID,
+		ScooterUUID: r.ScooterUUID,
+		City:        r.City,
+		Longitude:   r.Longitude,
+		Latitude:    r.Latitude,
+	}, nil
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,263 @@
+//go:build unit
+
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+const (
+	testStream = "domain_events"
+	testGroup  = "tracker"
+	testMaxLen = 1000
+
+	testDedupTTL = time.Hour
+)
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+
+	event := newTestEvent()
+
+	values, err := marshalEvent(event)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		wantErr   bool
+	}{
+		"successfully published event": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXAdd(&redis.XAddArgs{
+					Stream: testStream,
+					MaxLen: testMaxLen,
+					Approx: true,
+					Values: values,
+				}).SetVal("1-0")
+			},
+			wantErr: false,
+		},
+		"failed publishing event, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXAdd(&redis.XAddArgs{
+					Stream: testStream,
+					MaxLen: testMaxLen,
+					Approx: true,
+					Values: values,
+				}).SetErr(errors.New("redis down"))
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			sb := NewStreamBus(redisClient, testStream, testMaxLen, time.Minute, testDedupTTL, newTestRetrier())
+
+			if err := sb.Publish(ctx, event); (err != nil) != tt.wantErr {
+				t.Errorf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateGroup(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		start     service.GroupStart
+		redisMock func(mock redismock.ClientMock)
+		wantErr   bool
+	}{
+		"successfully created group reading from oldest event": {
+			start: service.GroupStartOldest,
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXGroupCreateMkStream(testStream, testGroup, "0").SetVal("OK")
+			},
+			wantErr: false,
+		},
+		"successfully created group reading only events published after it": {
+			start: service.GroupStartLatest,
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXGroupCreateMkStream(testStream, testGroup, "$").SetVal("OK")
+			},
+			wantErr: false,
+		},
+		"successfully kept existing group": {
+			start: service.GroupStartLatest,
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXGroupCreateMkStream(testStream, testGroup, "$").
+					SetErr(errors.New("BUSYGROUP Consumer Group name already exists"))
+			},
+			wantErr: false,
+		},
+		"failed creating group, because redis failed": {
+			start: service.GroupStartOldest,
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXGroupCreateMkStream(testStream, testGroup, "0").SetErr(errors.New("redis down"))
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			sb := NewStreamBus(redisClient, testStream, testMaxLen, time.Minute, testDedupTTL, newTestRetrier())
+
+			if err := sb.createGroup(ctx, testGroup, tt.start); (err != nil) != tt.wantErr {
+				t.Errorf("createGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	ctx := context.Background()
+
+	event := newTestEvent()
+
+	values, err := marshalEvent(event)
+	require.NoError(t, err)
+
+	message := redis.XMessage{
+		ID:     "1-0",
+		Values: map[string]any{values[0]: values[1], values[2]: values[3]},
+	}
+	handledKey := handledEventKey(testGroup, event.UUID)
+
+	malformedMessage := redis.XMessage{
+		ID:     "2-0",
+		Values: map[string]any{fieldType: string(model.TypeRentalStarted), fieldEvent: "{"},
+	}
+
+	tests := map[string]struct {
+		messages  []redis.XMessage
+		handler   func(calls *int) func(context.Context, *model.Event) error
+		redisMock func(mock redismock.ClientMock)
+		wantCalls int
+	}{
+		"handled and acknowledged event": {
+			messages: []redis.XMessage{message},
+			handler: func(calls *int) func(context.Context, *model.Event) error {
+				return func(_ context.Context, got *model.Event) error {
+					*calls++
+
+					require.Equal(t, event, got)
+
+					return nil
+				}
+			},
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectExists(handledKey).SetVal(0)
+				mock.ExpectSet(handledKey, 1, testDedupTTL).SetVal("OK")
+				mock.ExpectXAck(testStream, testGroup, message.ID).SetVal(1)
+			},
+			wantCalls: 1,
+		},
+		"skipped and acknowledged the event handled before": {
+			messages: []redis.XMessage{message},
+			handler: func(calls *int) func(context.Context, *model.Event) error {
+				return func(context.Context, *model.Event) error {
+					*calls++
+
+					return nil
+				}
+			},
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectExists(handledKey).SetVal(1)
+				mock.ExpectXAck(testStream, testGroup, message.ID).SetVal(1)
+			},
+			wantCalls: 0,
+		},
+		"retried the failing handler and acknowledged the event given up on": {
+			messages: []redis.XMessage{message},
+			handler: func(calls *int) func(context.Context, *model.Event) error {
+				return func(context.Context, *model.Event) error {
+					*calls++
+
+					return errors.New("handler failed")
+				}
+			},
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectExists(handledKey).SetVal(0)
+				mock.ExpectXAck(testStream, testGroup, message.ID).SetVal(1)
+			},
+			wantCalls: 2,
+		},
+		"skipped and acknowledged the malformed event": {
+			messages: []redis.XMessage{malformedMessage, message},
+			handler: func(calls *int) func(context.Context, *model.Event) error {
+				return func(context.Context, *model.Event) error {
+					*calls++
+
+					return nil
+				}
+			},
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXAck(testStream, testGroup, malformedMessage.ID).SetVal(1)
+				mock.ExpectExists(handledKey).SetVal(0)
+				mock.ExpectSet(handledKey, 1, testDedupTTL).SetVal("OK")
+				mock.ExpectXAck(testStream, testGroup, message.ID).SetVal(1)
+			},
+			wantCalls: 1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			sb := NewStreamBus(redisClient, testStream, testMaxLen, time.Minute, testDedupTTL, newTestRetrier())
+
+			var calls int
+
+			if !sb.handle(ctx, testGroup, tt.messages, tt.handler(&calls)) {
+				t.Errorf("handle() stopped, want all the events handled")
+			}
+
+			require.Equal(t, tt.wantCalls, calls)
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func newTestEvent() *model.Event {
+	return &model.Event{
+		UUID:        uuid.New(),
+		Type:        model.TypeRentalStarted,
+		OccurredAt:  time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+		RentalUUID:  uuid.New(),
+		UserUUID:    uuid.New(),
+		ScooterUUID: uuid.New(),
+		City:        "Montreal",
+		Longitude:   70.0,
+		Latitude:    60.0,
+	}
+}
+
+func newTestRetrier() *resilience.Retrier {
+	return resilience.NewRetrier(2, time.Millisecond, time.Millisecond)
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+)
+
+// Worker consumes the events of the bus as a member of the group, run next to the HTTP server.
+type Worker struct {
+	logger   *slog.Logger
+	bus      service.EventBus
+	group    string
+	consumer string
+	start    service.GroupStart
+	handler  service.EventHandler
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func NewWorker(
+	logger *slog.Logger,
+	bus service.EventBus,
+	group, consumer string,
+	start service.GroupStart,
+	handler service.EventHandler,
+) *Worker {
+	ctx, cancel := context.WithCancel(logging.WithLogger(context.Background(), logger))
+
+	return &Worker{
+		logger:   logger,
+		bus:      bus,
+		group:    group,
+		consumer: consumer,
+		start:    start,
+		handler:  handler,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Serve consumes the events until the worker is shut down.
+func (w *Worker) Serve() error {
+	w.logger.Info("Consuming events.", slog.String("group", w.group), slog.String("consumer", w.consumer))
+
+	return w.bus.Consume(w.ctx, w.group, w.consumer, w.start, w.handler)
+}
+
+// Shutdown stops the consumption, the event being handled is left unacknowledged to be handled again.
+func (w *Worker) Shutdown() {
+	w.cancel()
+}
@@ -0,0 +1,35 @@
+//go:build unit
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := map[string]struct {
+		ctx  context.Context
+		want *slog.Logger
+	}{
+		"returns the logger carried by the context": {
+			ctx:  WithLogger(context.Background(), logger),
+			want: logger,
+		},
+		"returns the default logger when the context carries none": {
+			ctx:  context.Background(),
+			want: slog.Default(),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
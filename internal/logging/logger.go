@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// NewHandler creates a handler writing records in the given format, dropping the ones below the level. Passing
+// a *slog.LevelVar lets the level be changed while the application runs.
+func NewHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	options := &slog.HandlerOptions{
+		Level: level,
+	}
+
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.NewTextHandler(w, options), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, options), nil
+	default:
+		return nil, fmt.Errorf("creating handler for %q: %w", format, ErrUnknownFormat)
+	}
+}
+
+// ParseLevel parses level names like debug, info, warn or error (case-insensitive).
+func ParseLevel(level string) (slog.Level, error) {
+	var result slog.Level
+
+	if err := result.UnmarshalText([]byte(level)); err != nil {
+		return result, fmt.Errorf("parsing log level: %w", err)
+	}
+
+	return result, nil
+}
+
+// NewFileSink creates a writer appending to the given file and rotating it once it grows over maxSizeMB. Rotated
+// files are removed when there are more than maxBackups of them or they are older than maxAgeDays.
+func NewFileSink(path string, maxSizeMB, maxBackups, maxAgeDays int) io.WriteCloser {
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAgeDays,
+		Compress:   true,
+	}
+}
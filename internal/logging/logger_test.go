@@ -0,0 +1,106 @@
+//go:build unit
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	tests := map[string]struct {
+		format     string
+		wantPrefix string
+		wantErr    error
+	}{
+		"text handler": {
+			format:     FormatText,
+			wantPrefix: "time=",
+		},
+		"json handler": {
+			format:     "JSON",
+			wantPrefix: `{"time":`,
+		},
+		"unknown format": {
+			format:  "xml",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var output bytes.Buffer
+
+			handler, err := NewHandler(&output, tt.format, slog.LevelInfo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			slog.New(handler).Info("message")
+
+			if !strings.HasPrefix(output.String(), tt.wantPrefix) {
+				t.Errorf("NewHandler() wrote %q, want prefix %q", output.String(), tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestNewHandlerLevelChange(t *testing.T) {
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(slog.LevelInfo)
+
+	handler, err := NewHandler(&bytes.Buffer{}, FormatText, levelVar)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+
+	if handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("Enabled() = true for debug records at info level")
+	}
+
+	levelVar.Set(slog.LevelDebug)
+
+	if !handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("Enabled() = false for debug records after changing the level to debug")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]struct {
+		level   string
+		want    slog.Level
+		wantErr bool
+	}{
+		"debug level": {
+			level: "debug",
+			want:  slog.LevelDebug,
+		},
+		"warn level in upper case": {
+			level: "WARN",
+			want:  slog.LevelWarn,
+		},
+		"unknown level": {
+			level:   "verbose",
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
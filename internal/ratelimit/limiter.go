@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+)
+
+type Bucket string
+
+const (
+	BucketSearch   Bucket = "search"
+	BucketMutation Bucket = "mutation"
+
+	keyPrefix = "rate_limit:"
+)
+
+// slidingWindowScript keeps the times of the requests let through within the window in a sorted set and lets the
+// next one through only if there are fewer of them than the limit. The time is taken from Redis, so the instances of
+// the application don't have to agree on it. It returns whether the request was let through, the number of requests
+// in the window and the milliseconds until the oldest of them leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	count = count + 1
+	allowed = 1
+end
+
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, count, reset}
+`)
+
+// Limit is the number of requests allowed within the window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result describes the state of the client's budget after the request.
+type Result struct {
+	Allowed   bool
+	Limit     Limit
+	Remaining int
+	// Reset is the time until the oldest request leaves the window, freeing a slot for the next one.
+	Reset time.Duration
+}
+
+// Limiter enforces per client budgets of requests shared by all the instances of the application.
+type Limiter struct {
+	client    *redis.Client
+	window    time.Duration
+	limits    map[Bucket]map[auth.Role]int
+	newMember func() string
+}
+
+// NewLimiter creates the limiter with the number of requests each role may send to each bucket within the window.
+func NewLimiter(client *redis.Client, window time.Duration, limits map[Bucket]map[auth.Role]int) *Limiter {
+	return &Limiter{
+		client:    client,
+		window:    window,
+		limits:    limits,
+		newMember: uuid.NewString,
+	}
+}
+
+// LimitFor returns the most generous limit of the roles in the bucket. Roles without a configured limit are not
+// limited, so false is returned when none of the roles is.
+func (l *Limiter) LimitFor(bucket Bucket, roles []auth.Role) (Limit, bool) {
+	var (
+		limit   Limit
+		limited bool
+	)
+
+	for _, role := range roles {
+		requests, found := l.limits[bucket][role]
+		if !found {
+			return Limit{}, false
+		}
+
+		if requests > limit.Requests {
+			limit = Limit{Requests: requests, Window: l.window}
+		}
+
+		limited = true
+	}
+
+	return limit, limited
+}
+
+// Allow records the request of the client in the bucket if the client has not used up its limit.
+func (l *Limiter) Allow(ctx context.Context, bucket Bucket, clientKey string, limit Limit) (*Result, error) {
+	key := keyPrefix + string(bucket) + ":" + clientKey
+
+	values, err := slidingWindowScript.Run(
+		ctx,
+		l.client,
+		[]string{key},
+		limit.Window.Milliseconds(),
+		limit.Requests,
+		l.newMember(),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("running rate limit script: %w", err)
+	}
+
+	if len(values) != 3 {
+		return nil, fmt.Errorf("rate limit script returned %d values, want 3", len(values))
+	}
+
+	return &Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: max(limit.Requests-int(values[1]), 0),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,119 @@
+//go:build unit
+
+package ratelimit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+)
+
+const (
+	testWindow    = time.Minute
+	testClientKey = "8212d8ba-74d1-49af-8a84-6d6c392ec71c"
+	testMember    = "request"
+)
+
+var testLimits = map[Bucket]map[auth.Role]int{
+	BucketSearch: {
+		auth.RoleRider:    60,
+		auth.RoleOperator: 600,
+	},
+}
+
+func TestLimiterLimitFor(t *testing.T) {
+	tests := map[string]struct {
+		roles       []auth.Role
+		want        Limit
+		wantLimited bool
+	}{
+		"rider is limited": {
+			roles:       []auth.Role{auth.RoleRider},
+			want:        Limit{Requests: 60, Window: testWindow},
+			wantLimited: true,
+		},
+		"the most generous limit of the roles is used": {
+			roles:       []auth.Role{auth.RoleRider, auth.RoleOperator},
+			want:        Limit{Requests: 600, Window: testWindow},
+			wantLimited: true,
+		},
+		"role without limit is not limited": {
+			roles:       []auth.Role{auth.RoleRider, auth.RoleAdmin},
+			want:        Limit{},
+			wantLimited: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiter := NewLimiter(nil, testWindow, testLimits)
+
+			got, limited := limiter.LimitFor(BucketSearch, tt.roles)
+			if got != tt.want || limited != tt.wantLimited {
+				t.Errorf("LimitFor() = %v, %v, want %v, %v", got, limited, tt.want, tt.wantLimited)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+
+	limit := Limit{Requests: 2, Window: testWindow}
+	key := keyPrefix + string(BucketSearch) + ":" + testClientKey
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      *Result
+		wantErr   bool
+	}{
+		"request within the limit is allowed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{key}, int64(60000), 2, testMember).
+					SetVal([]interface{}{int64(1), int64(1), int64(60000)})
+			},
+			want:    &Result{Allowed: true, Limit: limit, Remaining: 1, Reset: testWindow},
+			wantErr: false,
+		},
+		"request over the limit is denied": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{key}, int64(60000), 2, testMember).
+					SetVal([]interface{}{int64(0), int64(2), int64(1500)})
+			},
+			want:    &Result{Allowed: false, Limit: limit, Remaining: 0, Reset: 1500 * time.Millisecond},
+			wantErr: false,
+		},
+		"failed because redis is closed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(slidingWindowScript.Hash(), []string{key}, int64(60000), 2, testMember).
+					SetErr(redis.ErrClosed)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			limiter := NewLimiter(redisClient, testWindow, testLimits)
+			limiter.newMember = func() string { return testMember }
+
+			got, err := limiter.Allow(ctx, BucketSearch, testClientKey, limit)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Allow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allow() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+const (
+	// eventOutboxKey is the stream holding the events recorded along with the changes they come from, until they
+	// are published to the event bus.
+	eventOutboxKey = "event_outbox"
+
+	outboxEventField = "event"
+)
+
+// outboxRecord is the layout of the event stored as JSON in the entry of the outbox.
+type outboxRecord struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	RentalID   string    `json:"rental_id"`
+	UserID     string    `json:"user_id"`
+	ScooterID  string    `json:"scooter_id"`
+	City       string    `json:"city"`
+	Longitude  float64   `json:"longitude"`
+	Latitude   float64   `json:"latitude"`
+}
+
+// addToOutbox queues the events in the transaction of the change they come from.
+func addToOutbox(ctx context.Context, pipe redis.Pipeliner, events []*eventmodel.Event) error {
+	for _, event := range events {
+		eventJSON, err := marshalOutboxEvent(event)
+		if err != nil {
+			return err
+		}
+
+		// the pipeline of this version of the client has no stream commands, and the outbox is not trimmed, as
+		// the entries are removed once they are published
+		if err = pipe.Do(ctx, "xadd", eventOutboxKey, "*", outboxEventField, string(eventJSON)).Err(); err != nil {
+			return fmt.Errorf("adding event to outbox in redis: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func getOutboxEntries(ctx context.Context, client *redis.Client, count int64) ([]*eventmodel.OutboxEntry, error) {
+	messages, err := client.XRangeN(ctx, eventOutboxKey, "-", "+", count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting outbox entries from redis: %w", err)
+	}
+
+	entries := make([]*eventmodel.OutboxEntry, len(messages))
+
+	for i, message := range messages {
+		eventJSON, ok := message.Values[outboxEventField].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing %s field of outbox entry %s", outboxEventField, message.ID)
+		}
+
+		event, err := unmarshalOutboxEvent(eventJSON)
+		if err != nil {
+			return nil, fmt.Errorf("outbox entry %s: %w", message.ID, err)
+		}
+
+		entries[i] = &eventmodel.OutboxEntry{
+			ID:    message.ID,
+			Event: event,
+		}
+	}
+
+	return entries, nil
+}
+
+func deleteOutboxEntry(ctx context.Context, client *redis.Client, entryID string) error {
+	if err := client.XDel(ctx, eventOutboxKey, entryID).Err(); err != nil {
+		return fmt.Errorf("deleting outbox entry from redis: %w", err)
+	}
+
+	return nil
+}
+
+func marshalOutboxEvent(event *eventmodel.Event) ([]byte, error) {
+	eventJSON, err := json.Marshal(outboxRecord{
+		ID:         event.UUID.String(),
+		Type:       string(event.Type),
+		OccurredAt: event.OccurredAt,
+		RentalID:   event.RentalUUID.String(),
+		UserID:     event.UserUUID.String(),
+		ScooterID:  event.ScooterUUID.String(),
+		City:       event.City,
+		Longitude:  event.Longitude,
+		Latitude:   event.Latitude,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling outbox event: %w", err)
+	}
+
+	return eventJSON, nil
+}
+
+func unmarshalOutboxEvent(eventJSON string) (*eventmodel.Event, error) {
+	var record outboxRecord
+
+	if err := json.Unmarshal([]byte(eventJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling outbox event: %w", err)
+	}
+
+	eventUUID, err := uuid.Parse(record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing event's uuid: %w", err)
+	}
+
+	rentalUUID, err := uuid.Parse(record.RentalID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing event's rental uuid: %w", err)
+	}
+
+	userUUID, err := uuid.Parse(record.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing event's user uuid: %w", err)
+	}
+
+	scooterUUID, err := uuid.Parse(record.ScooterID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing event's scooter uuid: %w", err)
+	}
+
+	return &eventmodel.Event{
+		UUID:        eventUUID,
+		Type:        eventmodel.Type(record.Type),
+		OccurredAt:  record.OccurredAt,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: scooterUUID,
+		City:        record.City,
+		Longitude:   record.Longitude,
+		Latitude:    record.Latitude,
+	}, nil
+}
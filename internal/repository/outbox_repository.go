@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+type outboxRepository struct {
+	client *redis.Client
+}
+
+func NewOutboxRepository(client *redis.Client) *outboxRepository {
+	return &outboxRepository{
+		client: client,
+	}
+}
+
+func (or *outboxRepository) GetOutboxEntries(ctx context.Context, count int64) ([]*eventmodel.OutboxEntry, error) {
+	entries, err := getOutboxEntries(ctx, or.client, count)
+	if err != nil {
+		return nil, fmt.Errorf("getting outbox entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (or *outboxRepository) DeleteOutboxEntry(ctx context.Context, entryID string) error {
+	if err := deleteOutboxEntry(ctx, or.client, entryID); err != nil {
+		return fmt.Errorf("deleting outbox entry: %w", err)
+	}
+
+	return nil
+}
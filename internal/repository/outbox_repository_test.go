@@ -0,0 +1,94 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+func TestGetOutboxEntries(t *testing.T) {
+	ctx := context.Background()
+
+	event := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalStarted,
+		OccurredAt:  time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+		RentalUUID:  uuid.New(),
+		UserUUID:    uuid.New(),
+		ScooterUUID: uuid.New(),
+		City:        "Montreal",
+		Longitude:   70.0,
+		Latitude:    60.0,
+	}
+
+	eventJSON, err := marshalOutboxEvent(event)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      []*eventmodel.OutboxEntry
+		wantErr   bool
+	}{
+		"successfully got outbox entries": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXRangeN(eventOutboxKey, "-", "+", 10).SetVal([]redis.XMessage{
+					{ID: "1-0", Values: map[string]any{outboxEventField: string(eventJSON)}},
+				})
+			},
+			want:    []*eventmodel.OutboxEntry{{ID: "1-0", Event: event}},
+			wantErr: false,
+		},
+		"successfully got no outbox entries": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXRangeN(eventOutboxKey, "-", "+", 10).SetVal([]redis.XMessage{})
+			},
+			want:    []*eventmodel.OutboxEntry{},
+			wantErr: false,
+		},
+		"failed getting outbox entries, because the entry is malformed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXRangeN(eventOutboxKey, "-", "+", 10).SetVal([]redis.XMessage{
+					{ID: "1-0", Values: map[string]any{outboxEventField: "{"}},
+				})
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		"failed getting outbox entries, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectXRangeN(eventOutboxKey, "-", "+", 10).SetErr(errors.New("redis down"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			or := NewOutboxRepository(redisClient)
+
+			got, err := or.GetOutboxEntries(ctx, 10)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOutboxEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetOutboxEntries() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,16 +5,33 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 )
 
-const unitOfLength = "m" // in meters
+const (
+	unitOfLength = "m" // in meters
 
-var ErrScooterNotAvailable = errors.New("scooter with given ScooterUUID is not available")
+	schemaVersionKey = "schema_version"
+	// SchemaVersion is the version of the key layout this code reads and writes. Bump it whenever the layout changes.
+	SchemaVersion = 7
+
+	// ScooterCitiesKey is the hash holding the city of every scooter, so it can be found by its UUID alone.
+	ScooterCitiesKey = "scooter_cities"
+	// scooterUpdatesKey is the hash holding the time of the last update of every scooter, in Unix milliseconds.
+	scooterUpdatesKey = "scooter_updates"
+)
+
+var (
+	ErrScooterNotAvailable   = errors.New("scooter with given ScooterUUID is not available")
+	ErrScooterNotFound       = errors.New("scooter with given ScooterUUID was not found")
+	ErrSchemaVersionMismatch = errors.New("redis schema version does not match the expected one")
+)
 
 func getScooters(
 	ctx context.Context,
@@ -39,6 +56,51 @@ func getScooters(
 	return scootersDB, err
 }
 
+func getScooterCity(ctx context.Context, client *redis.Client, scooterUUID uuid.UUID) (string, error) {
+	city, err := client.HGet(ctx, ScooterCitiesKey, scooterUUID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrScooterNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("getting scooter's city from redis: %w", err)
+	}
+
+	return city, nil
+}
+
+func getScooterPosition(
+	ctx context.Context,
+	client *redis.Client,
+	city string,
+	scooterUUID uuid.UUID,
+) (*redis.GeoPos, error) {
+	positions, err := client.GeoPos(ctx, city, scooterUUID.String()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's position from redis: %w", err)
+	}
+
+	if len(positions) == 0 || positions[0] == nil {
+		return nil, ErrScooterNotFound
+	}
+
+	return positions[0], nil
+}
+
+func getScooterUpdatedAt(ctx context.Context, client *redis.Client, scooterUUID uuid.UUID) (time.Time, error) {
+	updatedAt, err := client.HGet(ctx, scooterUpdatesKey, scooterUUID.String()).Int64()
+	if errors.Is(err, redis.Nil) {
+		// the scooter hasn't been updated since it was added
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("getting scooter's last update from redis: %w", err)
+	}
+
+	return time.UnixMilli(updatedAt).UTC(), nil
+}
+
 func getScooterAvailability(
 	ctx context.Context,
 	client *redis.Client,
@@ -67,12 +129,21 @@ func updateScooterLocation(
 	client *redis.Client,
 	scooter *redis.GeoLocation,
 	city string,
+	updatedAt time.Time,
 ) error {
 	// Update the Geo index with scooter information
 	if _, err := client.GeoAdd(ctx, city, scooter).Result(); err != nil {
 		return fmt.Errorf("adding scooter's location to redis: %w", err)
 	}
 
+	if err := client.HSet(ctx, ScooterCitiesKey, scooter.Name, city).Err(); err != nil {
+		return fmt.Errorf("adding scooter's city to redis: %w", err)
+	}
+
+	if err := client.HSet(ctx, scooterUpdatesKey, scooter.Name, updatedAt.UnixMilli()).Err(); err != nil {
+		return fmt.Errorf("updating scooter's last update in redis: %w", err)
+	}
+
 	return nil
 }
 
@@ -81,6 +152,8 @@ func updateScooterAvailability(
 	client *redis.Client,
 	scooterUUID uuid.UUID,
 	availability bool,
+	updatedAt time.Time,
+	events []*eventmodel.Event,
 ) error {
 	key := scooterUUID.String()
 
@@ -88,6 +161,10 @@ func updateScooterAvailability(
 	// users won't be able to use the same scooter at the same time)
 	if err := client.Watch(ctx, func(tx *redis.Tx) error {
 		scooterAvailability, err := tx.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return ErrScooterNotFound
+		}
+
 		if err != nil {
 			return fmt.Errorf("getting scooter's availability from redis: %w", err)
 		}
@@ -102,10 +179,15 @@ func updateScooterAvailability(
 				return fmt.Errorf("updating scooter's availability in redis: %w", err)
 			}
 
-			return nil
+			if err = pipe.HSet(ctx, scooterUpdatesKey, key, updatedAt.UnixMilli()).Err(); err != nil {
+				return fmt.Errorf("updating scooter's last update in redis: %w", err)
+			}
+
+			// the events are published only when the change they come from is made
+			return addToOutbox(ctx, pipe, events)
 		})
 		if err != nil {
-			return fmt.Errorf("error while executing the pipeline: %v", err)
+			return fmt.Errorf("error while executing the pipeline: %w", err)
 		}
 
 		return nil
@@ -123,3 +205,27 @@ func willAvailabilityChange(wantAvailability bool, redisAvailability string) boo
 
 	return true
 }
+
+func getSchemaVersion(ctx context.Context, client *redis.Client) (int, error) {
+	version, err := client.Get(ctx, schemaVersionKey).Int()
+	if err != nil {
+		return 0, fmt.Errorf("getting schema version from redis: %w", err)
+	}
+
+	return version, nil
+}
+
+// setSchemaVersionIfMissing stores the version in the database holding none yet and returns the version the database
+// holds afterwards.
+func setSchemaVersionIfMissing(ctx context.Context, client *redis.Client, version int) (int, error) {
+	set, err := client.SetNX(ctx, schemaVersionKey, version, 0).Result()
+	if err != nil {
+		return 0, fmt.Errorf("setting schema version in redis: %w", err)
+	}
+
+	if set {
+		return version, nil
+	}
+
+	return getSchemaVersion(ctx, client)
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+const (
+	rentalKeyPrefix            = "rental:"
+	userRentalsKeyPrefix       = "user_rentals:"
+	userActiveRentalsKeyPrefix = "user_active_rentals:"
+	scooterRentalKeyPrefix     = "scooter_rental:"
+)
+
+// rentalRecord is the layout of the rental stored as JSON under the rental key. The rentals of the user are indexed
+// in a sorted set scored by their start time, and the active ones in another, from which they are removed when they
+// end along with the active rental of the scooter kept under the scooter rental key.
+type rentalRecord struct {
+	UserID         string     `json:"user_id"`
+	ScooterID      string     `json:"scooter_id"`
+	City           string     `json:"city"`
+	StartLongitude float64    `json:"start_longitude"`
+	StartLatitude  float64    `json:"start_latitude"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+}
+
+// endRentalScript stores the ended rental and clears it from the indexes of the active rentals at once, so the rental
+// is never left ended yet active. The active rental of the scooter is cleared only if it is still this one, as the
+// scooter may be rented again as soon as it is available. It returns 0 when there is no such rental.
+var endRentalScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+
+redis.call('SET', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[2])
+
+if redis.call('GET', KEYS[3]) == ARGV[2] then
+	redis.call('DEL', KEYS[3])
+end
+
+return 1
+`)
+
+func rentalKey(rentalUUID uuid.UUID) string {
+	return rentalKeyPrefix + rentalUUID.String()
+}
+
+func userRentalsKey(userUUID uuid.UUID) string {
+	return userRentalsKeyPrefix + userUUID.String()
+}
+
+func userActiveRentalsKey(userUUID uuid.UUID) string {
+	return userActiveRentalsKeyPrefix + userUUID.String()
+}
+
+func scooterRentalKey(scooterUUID uuid.UUID) string {
+	return scooterRentalKeyPrefix + scooterUUID.String()
+}
+
+func createRental(ctx context.Context, client *redis.Client, rental *rentalmodel.Rental) error {
+	rentalJSON, err := marshalRental(rental)
+	if err != nil {
+		return err
+	}
+
+	if _, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, rentalKey(rental.UUID), rentalJSON, 0)
+		userRental := redis.Z{
+			Score:  float64(rental.StartedAt.UnixMilli()),
+			Member: rental.UUID.String(),
+		}
+
+		pipe.ZAdd(ctx, userRentalsKey(rental.UserUUID), userRental)
+		pipe.ZAdd(ctx, userActiveRentalsKey(rental.UserUUID), userRental)
+		pipe.Set(ctx, scooterRentalKey(rental.ScooterUUID), rental.UUID.String(), 0)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("creating rental in redis: %w", err)
+	}
+
+	return nil
+}
+
+func getRental(ctx context.Context, client *redis.Client, rentalUUID uuid.UUID) (*rentalmodel.Rental, error) {
+	rentalJSON, err := client.Get(ctx, rentalKey(rentalUUID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.ErrRentalNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting rental from redis: %w", err)
+	}
+
+	return unmarshalRental(rentalUUID, rentalJSON)
+}
+
+func getActiveRentalUUID(ctx context.Context, client *redis.Client, scooterUUID uuid.UUID) (uuid.UUID, error) {
+	rentalID, err := client.Get(ctx, scooterRentalKey(scooterUUID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return uuid.Nil, service.ErrRentalNotFound
+	}
+
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("getting scooter's rental from redis: %w", err)
+	}
+
+	rentalUUID, err := uuid.Parse(rentalID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("parsing rental's uuid: %w", err)
+	}
+
+	return rentalUUID, nil
+}
+
+// getRentalUUIDs returns the rentals indexed in the sorted set under the key, the latest first.
+func getRentalUUIDs(ctx context.Context, client *redis.Client, key string) ([]uuid.UUID, error) {
+	rentalIDs, err := client.ZRevRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting rentals from redis: %w", err)
+	}
+
+	rentalUUIDs := make([]uuid.UUID, len(rentalIDs))
+
+	for i := range rentalIDs {
+		if rentalUUIDs[i], err = uuid.Parse(rentalIDs[i]); err != nil {
+			return nil, fmt.Errorf("parsing rental's uuid: %w", err)
+		}
+	}
+
+	return rentalUUIDs, nil
+}
+
+func endRental(ctx context.Context, client *redis.Client, rental *rentalmodel.Rental) error {
+	rentalJSON, err := marshalRental(rental)
+	if err != nil {
+		return err
+	}
+
+	ended, err := endRentalScript.Run(
+		ctx,
+		client,
+		[]string{rentalKey(rental.UUID), userActiveRentalsKey(rental.UserUUID), scooterRentalKey(rental.ScooterUUID)},
+		rentalJSON,
+		rental.UUID.String(),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("ending rental in redis: %w", err)
+	}
+
+	if ended == 0 {
+		return service.ErrRentalNotFound
+	}
+
+	return nil
+}
+
+func marshalRental(rental *rentalmodel.Rental) ([]byte, error) {
+	rentalJSON, err := json.Marshal(rentalRecord{
+		UserID:         rental.UserUUID.String(),
+		ScooterID:      rental.ScooterUUID.String(),
+		City:           rental.City,
+		StartLongitude: rental.StartLongitude,
+		StartLatitude:  rental.StartLatitude,
+		StartedAt:      rental.StartedAt,
+		EndedAt:        rental.EndedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling rental: %w", err)
+	}
+
+	return rentalJSON, nil
+}
+
+func unmarshalRental(rentalUUID uuid.UUID, rentalJSON string) (*rentalmodel.Rental, error) {
+	var record rentalRecord
+
+	if err := json.Unmarshal([]byte(rentalJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling rental: %w", err)
+	}
+
+	userUUID, err := uuid.Parse(record.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing rental's user uuid: %w", err)
+	}
+
+	scooterUUID, err := uuid.Parse(record.ScooterID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing rental's scooter uuid: %w", err)
+	}
+
+	return &rentalmodel.Rental{
+		UUID:           rentalUUID,
+		UserUUID:       userUUID,
+		ScooterUUID:    scooterUUID,
+		City:           record.City,
+		StartLongitude: record.StartLongitude,
+		StartLatitude:  record.StartLatitude,
+		StartedAt:      record.StartedAt,
+		EndedAt:        record.EndedAt,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+type rentalRepository struct {
+	client *redis.Client
+}
+
+func NewRentalRepository(client *redis.Client) *rentalRepository {
+	return &rentalRepository{
+		client: client,
+	}
+}
+
+func (rr *rentalRepository) CreateRental(ctx context.Context, rental *rentalmodel.Rental) error {
+	if err := createRental(ctx, rr.client, rental); err != nil {
+		return fmt.Errorf("creating rental: %w", err)
+	}
+
+	return nil
+}
+
+func (rr *rentalRepository) GetRental(ctx context.Context, rentalUUID uuid.UUID) (*rentalmodel.Rental, error) {
+	rental, err := getRental(ctx, rr.client, rentalUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting rental: %w", err)
+	}
+
+	return rental, nil
+}
+
+func (rr *rentalRepository) GetActiveRental(ctx context.Context, scooterUUID uuid.UUID) (*rentalmodel.Rental, error) {
+	rentalUUID, err := getActiveRentalUUID(ctx, rr.client, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's active rental: %w", err)
+	}
+
+	rental, err := getRental(ctx, rr.client, rentalUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's active rental: %w", err)
+	}
+
+	return rental, nil
+}
+
+func (rr *rentalRepository) GetUserRentals(ctx context.Context, userUUID uuid.UUID) ([]*rentalmodel.Rental, error) {
+	rentals, err := rr.getRentals(ctx, userRentalsKey(userUUID))
+	if err != nil {
+		return nil, fmt.Errorf("getting user's rentals: %w", err)
+	}
+
+	return rentals, nil
+}
+
+func (rr *rentalRepository) GetUserActiveRentals(
+	ctx context.Context,
+	userUUID uuid.UUID,
+) ([]*rentalmodel.Rental, error) {
+	rentals, err := rr.getRentals(ctx, userActiveRentalsKey(userUUID))
+	if err != nil {
+		return nil, fmt.Errorf("getting user's active rentals: %w", err)
+	}
+
+	return rentals, nil
+}
+
+func (rr *rentalRepository) EndRental(ctx context.Context, rental *rentalmodel.Rental) error {
+	if err := endRental(ctx, rr.client, rental); err != nil {
+		return fmt.Errorf("ending rental: %w", err)
+	}
+
+	return nil
+}
+
+// getRentals returns the rentals indexed under the key, the latest first.
+func (rr *rentalRepository) getRentals(ctx context.Context, key string) ([]*rentalmodel.Rental, error) {
+	rentalUUIDs, err := getRentalUUIDs(ctx, rr.client, key)
+	if err != nil {
+		return nil, err
+	}
+
+	rentals := make([]*rentalmodel.Rental, len(rentalUUIDs))
+
+	for i := range rentalUUIDs {
+		if rentals[i], err = getRental(ctx, rr.client, rentalUUIDs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return rentals, nil
+}
@@ -0,0 +1,248 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+func TestCreateRental(t *testing.T) {
+	ctx := context.Background()
+
+	rental := newTestRental(t)
+
+	rentalJSON, err := marshalRental(rental)
+	require.NoError(t, err)
+
+	userRental := redis.Z{
+		Score:  float64(rental.StartedAt.UnixMilli()),
+		Member: rental.UUID.String(),
+	}
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	redisMock.ExpectTxPipeline()
+	redisMock.ExpectSet(rentalKey(rental.UUID), rentalJSON, 0).SetVal("OK")
+	redisMock.ExpectZAdd(userRentalsKey(rental.UserUUID), userRental).SetVal(1)
+	redisMock.ExpectZAdd(userActiveRentalsKey(rental.UserUUID), userRental).SetVal(1)
+	redisMock.ExpectSet(scooterRentalKey(rental.ScooterUUID), rental.UUID.String(), 0).SetVal("OK")
+	redisMock.ExpectTxPipelineExec()
+
+	rr := NewRentalRepository(redisClient)
+
+	require.NoError(t, rr.CreateRental(ctx, rental))
+	require.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetActiveRental(t *testing.T) {
+	ctx := context.Background()
+
+	rental := newTestRental(t)
+
+	rentalJSON, err := marshalRental(rental)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      *rentalmodel.Rental
+		wantErr   error
+	}{
+		"successfully got active rental": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectGet(scooterRentalKey(rental.ScooterUUID)).SetVal(rental.UUID.String())
+				mock.ExpectGet(rentalKey(rental.UUID)).SetVal(string(rentalJSON))
+			},
+			want:    rental,
+			wantErr: nil,
+		},
+		"failed getting active rental, because scooter is not rented": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectGet(scooterRentalKey(rental.ScooterUUID)).RedisNil()
+			},
+			want:    nil,
+			wantErr: service.ErrRentalNotFound,
+		},
+		"failed getting active rental, because repository threw an error": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectGet(scooterRentalKey(rental.ScooterUUID)).SetErr(redis.ErrClosed)
+			},
+			want:    nil,
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			rr := NewRentalRepository(redisClient)
+
+			got, err := rr.GetActiveRental(ctx, rental.ScooterUUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetActiveRental() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetActiveRental() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUserRentals(t *testing.T) {
+	ctx := context.Background()
+
+	rental := newTestRental(t)
+
+	rentalJSON, err := marshalRental(rental)
+	require.NoError(t, err)
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	redisMock.ExpectZRevRange(userRentalsKey(rental.UserUUID), 0, -1).SetVal([]string{rental.UUID.String()})
+	redisMock.ExpectGet(rentalKey(rental.UUID)).SetVal(string(rentalJSON))
+
+	rr := NewRentalRepository(redisClient)
+
+	got, err := rr.GetUserRentals(ctx, rental.UserUUID)
+	require.NoError(t, err)
+	require.Equal(t, []*rentalmodel.Rental{rental}, got)
+}
+
+func TestGetUserActiveRentals(t *testing.T) {
+	ctx := context.Background()
+
+	rental := newTestRental(t)
+
+	rentalJSON, err := marshalRental(rental)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      []*rentalmodel.Rental
+		wantErr   error
+	}{
+		"successfully got active rentals": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectZRevRange(userActiveRentalsKey(rental.UserUUID), 0, -1).SetVal([]string{rental.UUID.String()})
+				mock.ExpectGet(rentalKey(rental.UUID)).SetVal(string(rentalJSON))
+			},
+			want:    []*rentalmodel.Rental{rental},
+			wantErr: nil,
+		},
+		"failed getting active rentals, because indexed rental does not exist": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectZRevRange(userActiveRentalsKey(rental.UserUUID), 0, -1).SetVal([]string{rental.UUID.String()})
+				mock.ExpectGet(rentalKey(rental.UUID)).RedisNil()
+			},
+			want:    nil,
+			wantErr: service.ErrRentalNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			rr := NewRentalRepository(redisClient)
+
+			got, err := rr.GetUserActiveRentals(ctx, rental.UserUUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetUserActiveRentals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetUserActiveRentals() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndRentalRepo(t *testing.T) {
+	ctx := context.Background()
+
+	rental := newTestRental(t)
+
+	endedAt := rental.StartedAt.Add(time.Hour)
+	rental.EndedAt = &endedAt
+
+	rentalJSON, err := marshalRental(rental)
+	require.NoError(t, err)
+
+	keys := []string{
+		rentalKey(rental.UUID),
+		userActiveRentalsKey(rental.UserUUID),
+		scooterRentalKey(rental.ScooterUUID),
+	}
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		wantErr   error
+	}{
+		"successfully ended rental": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(endRentalScript.Hash(), keys, rentalJSON, rental.UUID.String()).SetVal(int64(1))
+			},
+			wantErr: nil,
+		},
+		"failed ending rental, because it does not exist": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(endRentalScript.Hash(), keys, rentalJSON, rental.UUID.String()).SetVal(int64(0))
+			},
+			wantErr: service.ErrRentalNotFound,
+		},
+		"failed ending rental, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(endRentalScript.Hash(), keys, rentalJSON, rental.UUID.String()).
+					SetErr(redis.ErrClosed)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			rr := NewRentalRepository(redisClient)
+
+			if err = rr.EndRental(ctx, rental); !errors.Is(err, tt.wantErr) {
+				t.Errorf("EndRental() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func newTestRental(t *testing.T) *rentalmodel.Rental {
+	t.Helper()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+
+	return rentalmodel.NewRental(
+		uuid.New(),
+		uuid.New(),
+		scooterUUID,
+		rentInfo,
+		time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+	)
+}
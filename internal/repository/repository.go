@@ -3,21 +3,25 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 )
 
 type redisService struct {
 	client *redis.Client
+	now    func() time.Time
 }
 
 func NewRedisService(client *redis.Client) *redisService {
 	return &redisService{
 		client: client,
+		now:    time.Now,
 	}
 }
 
@@ -62,6 +66,33 @@ func (rs *redisService) GetScooters(
 	return results, nil
 }
 
+func (rs *redisService) GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*rentalmodel.Scooter, error) {
+	city, err := getScooterCity(ctx, rs.client, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's city: %w", err)
+	}
+
+	position, err := getScooterPosition(ctx, rs.client, city, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's position: %w", err)
+	}
+
+	availability, err := getScooterAvailability(ctx, rs.client, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's availability: %w", err)
+	}
+
+	updatedAt, err := getScooterUpdatedAt(ctx, rs.client, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's last update: %w", err)
+	}
+
+	scooter := rentalmodel.NewScooter(scooterUUID.String(), city, position.Longitude, position.Latitude, availability)
+	scooter.UpdatedAt = updatedAt
+
+	return scooter, nil
+}
+
 func (rs *redisService) UpdateScooterLocation(ctx context.Context, scooter *trackermodel.Scooter) error {
 	redisLocation := &redis.GeoLocation{
 		Name:      scooter.Name,
@@ -69,7 +100,7 @@ func (rs *redisService) UpdateScooterLocation(ctx context.Context, scooter *trac
 		Latitude:  scooter.Latitude,
 	}
 
-	err := updateScooterLocation(ctx, rs.client, redisLocation, scooter.City)
+	err := updateScooterLocation(ctx, rs.client, redisLocation, scooter.City, rs.now())
 	if err != nil {
 		return fmt.Errorf("updating scooter's location: %w", err)
 	}
@@ -77,11 +108,55 @@ func (rs *redisService) UpdateScooterLocation(ctx context.Context, scooter *trac
 	return nil
 }
 
-func (rs *redisService) UpdateScooterAvailability(ctx context.Context, scooterUUID uuid.UUID, availability bool) error {
-	err := updateScooterAvailability(ctx, rs.client, scooterUUID, availability)
+func (rs *redisService) UpdateScooterAvailability(
+	ctx context.Context,
+	scooterUUID uuid.UUID,
+	availability bool,
+	events ...*eventmodel.Event,
+) error {
+	err := updateScooterAvailability(ctx, rs.client, scooterUUID, availability, rs.now(), events)
 	if err != nil {
 		return fmt.Errorf("updating scooter's availability: %w", err)
 	}
 
 	return nil
 }
+
+// Ping checks whether Redis accepts connections.
+func (rs *redisService) Ping(ctx context.Context) error {
+	if err := rs.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("pinging redis: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureSchemaVersion marks the database holding no version yet as holding keys in the layout of the current
+// SchemaVersion. The version already held is never overwritten, it has to match the current one instead, so the code
+// doesn't start on a database whose keys it can't read.
+func (rs *redisService) EnsureSchemaVersion(ctx context.Context) error {
+	version, err := setSchemaVersionIfMissing(ctx, rs.client, SchemaVersion)
+	if err != nil {
+		return fmt.Errorf("ensuring schema version: %w", err)
+	}
+
+	if version != SchemaVersion {
+		return fmt.Errorf("found version %d, want %d: %w", version, SchemaVersion, ErrSchemaVersionMismatch)
+	}
+
+	return nil
+}
+
+// CheckSchema verifies that the database holds keys in the layout this code expects.
+func (rs *redisService) CheckSchema(ctx context.Context) error {
+	version, err := getSchemaVersion(ctx, rs.client)
+	if err != nil {
+		return fmt.Errorf("checking schema version: %w", err)
+	}
+
+	if version != SchemaVersion {
+		return fmt.Errorf("found version %d, want %d: %w", version, SchemaVersion, ErrSchemaVersionMismatch)
+	}
+
+	return nil
+}
@@ -4,15 +4,19 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redismock/v9"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 )
@@ -25,6 +29,8 @@ const (
 	testWidth     = 15000.0
 )
 
+var testUpdatedAt = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
 func TestGetScootersRepo(t *testing.T) {
 	ctx := context.Background()
 
@@ -164,6 +170,88 @@ func TestGetScootersRepo(t *testing.T) {
 	}
 }
 
+func TestGetScooterRepo(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      *rentalmodel.Scooter
+		wantErr   error
+	}{
+		"successfully got scooter": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(ScooterCitiesKey, scooterUUID.String()).SetVal(testCity)
+				mock.ExpectGeoPos(testCity, scooterUUID.String()).
+					SetVal([]*redis.GeoPos{{Longitude: testLongitude, Latitude: testLatitude}})
+				mock.ExpectGet(scooterUUID.String()).SetVal("1")
+				mock.ExpectHGet(scooterUpdatesKey, scooterUUID.String()).
+					SetVal(strconv.FormatInt(testUpdatedAt.UnixMilli(), 10))
+			},
+			want: &rentalmodel.Scooter{
+				Name:         scooterUUID.String(),
+				City:         testCity,
+				Longitude:    testLongitude,
+				Latitude:     testLatitude,
+				Availability: true,
+				UpdatedAt:    testUpdatedAt,
+			},
+			wantErr: nil,
+		},
+		"successfully got scooter that was never updated": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(ScooterCitiesKey, scooterUUID.String()).SetVal(testCity)
+				mock.ExpectGeoPos(testCity, scooterUUID.String()).
+					SetVal([]*redis.GeoPos{{Longitude: testLongitude, Latitude: testLatitude}})
+				mock.ExpectGet(scooterUUID.String()).SetVal("0")
+				mock.ExpectHGet(scooterUpdatesKey, scooterUUID.String()).RedisNil()
+			},
+			want:    rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, false),
+			wantErr: nil,
+		},
+		"failed getting scooter, because its city is unknown": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(ScooterCitiesKey, scooterUUID.String()).RedisNil()
+			},
+			wantErr: ErrScooterNotFound,
+		},
+		"failed getting scooter, because it has no position": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(ScooterCitiesKey, scooterUUID.String()).SetVal(testCity)
+				mock.ExpectGeoPos(testCity, scooterUUID.String()).SetVal([]*redis.GeoPos{nil})
+			},
+			wantErr: ErrScooterNotFound,
+		},
+		"failed getting scooter, because repository threw an error when getting its availability": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(ScooterCitiesKey, scooterUUID.String()).SetVal(testCity)
+				mock.ExpectGeoPos(testCity, scooterUUID.String()).
+					SetVal([]*redis.GeoPos{{Longitude: testLongitude, Latitude: testLatitude}})
+				mock.ExpectGet(scooterUUID.String()).SetErr(redis.ErrClosed)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			rs := NewRedisService(redisClient)
+
+			got, err := rs.GetScooter(ctx, scooterUUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetScooter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestUpdateScooterLocation(t *testing.T) {
 	ctx := context.Background()
 
@@ -189,6 +277,8 @@ func TestUpdateScooterLocation(t *testing.T) {
 			logger: logger,
 			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
 				mock.ExpectGeoAdd(testCity, scooter).SetVal(1)
+				mock.ExpectHSet(ScooterCitiesKey, scooter.Name, testCity).SetVal(1)
+				mock.ExpectHSet(scooterUpdatesKey, scooter.Name, testUpdatedAt.UnixMilli()).SetVal(1)
 			},
 			wantErr: false,
 		},
@@ -207,6 +297,7 @@ func TestUpdateScooterLocation(t *testing.T) {
 			tt.mockRedisDatabaseHandler(redisMock)
 
 			rs := NewRedisService(redisClient)
+			rs.now = func() time.Time { return testUpdatedAt }
 
 			if err = rs.UpdateScooterLocation(ctx, trackerScooter); (err != nil) != tt.wantErr {
 				t.Errorf("UpdateScooterLocation() error = %v, wantErr %v", err, tt.wantErr)
@@ -225,8 +316,22 @@ func TestUpdateScooterAvailability(t *testing.T) {
 
 	scooterAvailability := true
 
+	endedEvent := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalEnded,
+		OccurredAt:  testUpdatedAt,
+		RentalUUID:  uuid.New(),
+		UserUUID:    uuid.New(),
+		ScooterUUID: firstScooterUUID,
+		City:        "Montreal",
+	}
+
+	endedEventJSON, err := marshalOutboxEvent(endedEvent)
+	require.NoError(t, err)
+
 	tests := map[string]struct {
 		logger                   *log.Logger
+		events                   []*eventmodel.Event
 		mockRedisDatabaseHandler func(mock redismock.ClientMock)
 		wantErr                  bool
 	}{
@@ -237,6 +342,21 @@ func TestUpdateScooterAvailability(t *testing.T) {
 				mock.ExpectGet(firstScooterUUID.String()).SetVal("0")
 				mock.ExpectTxPipeline()
 				mock.ExpectSet(firstScooterUUID.String(), scooterAvailability, 0).SetVal("status")
+				mock.ExpectHSet(scooterUpdatesKey, firstScooterUUID.String(), testUpdatedAt.UnixMilli()).SetVal(1)
+				mock.ExpectTxPipelineExec()
+			},
+			wantErr: false,
+		},
+		"updating scooter availability successfully, recording the event in the outbox": {
+			logger: logger,
+			events: []*eventmodel.Event{endedEvent},
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(firstScooterUUID.String())
+				mock.ExpectGet(firstScooterUUID.String()).SetVal("0")
+				mock.ExpectTxPipeline()
+				mock.ExpectSet(firstScooterUUID.String(), scooterAvailability, 0).SetVal("status")
+				mock.ExpectHSet(scooterUpdatesKey, firstScooterUUID.String(), testUpdatedAt.UnixMilli()).SetVal(1)
+				mock.ExpectDo("xadd", eventOutboxKey, "*", outboxEventField, string(endedEventJSON)).SetVal("1-0")
 				mock.ExpectTxPipelineExec()
 			},
 			wantErr: false,
@@ -251,6 +371,14 @@ func TestUpdateScooterAvailability(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		"updating scooter failed, because scooter does not exist": {
+			logger: logger,
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(firstScooterUUID.String())
+				mock.ExpectGet(firstScooterUUID.String()).RedisNil()
+			},
+			wantErr: true,
+		},
 		"updating scooter failed, because repository threw an error when getting scooter's availability": {
 			logger: logger,
 			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
@@ -287,9 +415,122 @@ func TestUpdateScooterAvailability(t *testing.T) {
 			tt.mockRedisDatabaseHandler(redisMock)
 
 			rs := NewRedisService(redisClient)
-			if err = rs.UpdateScooterAvailability(ctx, firstScooterUUID, scooterAvailability); (err != nil) != tt.wantErr {
+			rs.now = func() time.Time { return testUpdatedAt }
+
+			err = rs.UpdateScooterAvailability(ctx, firstScooterUUID, scooterAvailability, tt.events...)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("UpdateScooterAvailability() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestUpdateScooterAvailabilityLostRace(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	redisClient, redisMock := redismock.NewClientMock()
+	redisMock.ExpectWatch(scooterUUID.String())
+	redisMock.ExpectGet(scooterUUID.String()).SetVal("1")
+	redisMock.ExpectTxPipeline()
+	redisMock.ExpectSet(scooterUUID.String(), false, 0).SetErr(redis.TxFailedErr)
+
+	rs := NewRedisService(redisClient)
+
+	// the scooter rented by another client meanwhile is reported as the lost race, not as a failure of redis
+	if err = rs.UpdateScooterAvailability(ctx, scooterUUID, false); !errors.Is(err, redis.TxFailedErr) {
+		t.Errorf("UpdateScooterAvailability() error = %v, wantErr %v", err, redis.TxFailedErr)
+	}
+}
+
+func TestEnsureSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		mockRedisDatabaseHandler func(mock redismock.ClientMock)
+		wantErr                  error
+	}{
+		"schema version set in the empty database": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(schemaVersionKey, SchemaVersion, 0).SetVal(true)
+			},
+			wantErr: nil,
+		},
+		"schema version already held matches": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(schemaVersionKey, SchemaVersion, 0).SetVal(false)
+				mock.ExpectGet(schemaVersionKey).SetVal(strconv.Itoa(SchemaVersion))
+			},
+			wantErr: nil,
+		},
+		"schema version check failed, because the version already held differs": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(schemaVersionKey, SchemaVersion, 0).SetVal(false)
+				mock.ExpectGet(schemaVersionKey).SetVal(strconv.Itoa(SchemaVersion - 1))
+			},
+			wantErr: ErrSchemaVersionMismatch,
+		},
+		"schema version check failed, because redis failed": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(schemaVersionKey, SchemaVersion, 0).SetErr(redis.ErrClosed)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.mockRedisDatabaseHandler(redisMock)
+
+			rs := NewRedisService(redisClient)
+			if err := rs.EnsureSchemaVersion(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("EnsureSchemaVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		mockRedisDatabaseHandler func(mock redismock.ClientMock)
+		wantErr                  bool
+	}{
+		"schema version matches": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectGet(schemaVersionKey).SetVal(strconv.Itoa(SchemaVersion))
+			},
+			wantErr: false,
+		},
+		"schema version check failed, because the version differs": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectGet(schemaVersionKey).SetVal(strconv.Itoa(SchemaVersion + 1))
+			},
+			wantErr: true,
+		},
+		"schema version check failed, because the version is missing": {
+			mockRedisDatabaseHandler: func(mock redismock.ClientMock) {
+				mock.ExpectGet(schemaVersionKey).RedisNil()
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.mockRedisDatabaseHandler(redisMock)
+
+			rs := NewRedisService(redisClient)
+			if err := rs.CheckSchema(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
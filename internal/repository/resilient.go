@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+// unavailableReplyPrefixes start the replies of the Redis server that can't serve the command for the moment, e.g.
+// while it loads the data set or fails over.
+var unavailableReplyPrefixes = []string{"LOADING", "READONLY", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"}
+
+// resilientRepository guards the wrapped repository with a circuit breaker and retries the calls that are safe to
+// repeat (reads and location writes). Availability updates are not retried, as the caller has to decide whether
+// the scooter should still be rented after a failure.
+type resilientRepository struct {
+	repository service.ScooterRepository
+	retrier    *resilience.Retrier
+	breaker    *resilience.CircuitBreaker
+}
+
+func NewResilientRepository(
+	repository service.ScooterRepository,
+	retrier *resilience.Retrier,
+	breaker *resilience.CircuitBreaker,
+) *resilientRepository {
+	return &resilientRepository{
+		repository: repository,
+		retrier:    retrier,
+		breaker:    breaker,
+	}
+}
+
+func (rr *resilientRepository) GetScooters(
+	ctx context.Context,
+	geoRectangle *rentalmodel.GeoRectangle,
+) ([]*rentalmodel.Scooter, error) {
+	var scooters []*rentalmodel.Scooter
+
+	err := rr.retrier.Do(ctx, func(ctx context.Context) error {
+		return rr.breaker.Execute(func() error {
+			var err error
+
+			scooters, err = rr.repository.GetScooters(ctx, geoRectangle)
+
+			return err
+		}, IsTransientError)
+	}, IsTransientError)
+	if err != nil {
+		return nil, err
+	}
+
+	return scooters, nil
+}
+
+func (rr *resilientRepository) GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*rentalmodel.Scooter, error) {
+	var scooter *rentalmodel.Scooter
+
+	err := rr.retrier.Do(ctx, func(ctx context.Context) error {
+		return rr.breaker.Execute(func() error {
+			var err error
+
+			scooter, err = rr.repository.GetScooter(ctx, scooterUUID)
+
+			return err
+		}, IsTransientError)
+	}, IsTransientError)
+	if err != nil {
+		return nil, err
+	}
+
+	return scooter, nil
+}
+
+func (rr *resilientRepository) UpdateScooterLocation(ctx context.Context, scooter *trackermodel.Scooter) error {
+	return rr.retrier.Do(ctx, func(ctx context.Context) error {
+		return rr.breaker.Execute(func() error {
+			return rr.repository.UpdateScooterLocation(ctx, scooter)
+		}, IsTransientError)
+	}, IsTransientError)
+}
+
+func (rr *resilientRepository) UpdateScooterAvailability(
+	ctx context.Context,
+	scooterUUID uuid.UUID,
+	availability bool,
+	events ...*eventmodel.Event,
+) error {
+	return rr.breaker.Execute(func() error {
+		return rr.repository.UpdateScooterAvailability(ctx, scooterUUID, availability, events...)
+	}, IsTransientError)
+}
+
+// IsTransientError reports whether the error comes from the connection with Redis or from the server being
+// temporarily unable to serve, so repeating the call later may succeed. Any other error, e.g. of the data or of the
+// business rules, is not transient.
+func IsTransientError(err error) bool {
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, redis.ErrClosed),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr):
+		return true
+	}
+
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false
+	}
+
+	for _, prefix := range unavailableReplyPrefixes {
+		if redis.HasErrorPrefix(redisErr, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
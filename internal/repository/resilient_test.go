@@ -0,0 +1,227 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+const (
+	testRetryAttempts    = 3
+	testFailureThreshold = 5
+)
+
+func TestResilientGetScooters(t *testing.T) {
+	ctx := context.Background()
+
+	geoRectangle := rentalmodel.NewRectangle(testCity, testLongitude, testLatitude, testHeight, testWidth)
+
+	tests := map[string]struct {
+		mockRepositoryHandler func(mock *mock.MockScooterRepository)
+		wantErr               error
+	}{
+		"getting scooters successfully after a transient error": {
+			mockRepositoryHandler: func(mock *mock.MockScooterRepository) {
+				gomock.InOrder(
+					mock.EXPECT().GetScooters(ctx, geoRectangle).Return(nil, redis.ErrClosed),
+					mock.EXPECT().GetScooters(ctx, geoRectangle).Return(nil, nil),
+				)
+			},
+			wantErr: nil,
+		},
+		"getting scooters failed, because all attempts met transient errors": {
+			mockRepositoryHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().GetScooters(ctx, geoRectangle).Return(nil, redis.ErrClosed).Times(testRetryAttempts)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockRepository := mock.NewMockScooterRepository(controller)
+
+			tt.mockRepositoryHandler(mockRepository)
+
+			rr := newTestResilientRepository(mockRepository)
+
+			if _, err := rr.GetScooters(ctx, geoRectangle); !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetScooters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResilientUpdateScooterLocation(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooter := trackermodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRepository := mock.NewMockScooterRepository(controller)
+	mockRepository.EXPECT().UpdateScooterLocation(ctx, scooter).Return(redis.ErrClosed).Times(testFailureThreshold)
+
+	rr := newTestResilientRepository(mockRepository)
+
+	// the first call uses up three attempts, the second one opens the circuit after two more
+	for i := 0; i < 2; i++ {
+		if err = rr.UpdateScooterLocation(ctx, scooter); err == nil {
+			t.Fatalf("UpdateScooterLocation() error = nil, want error")
+		}
+	}
+
+	if err = rr.UpdateScooterLocation(ctx, scooter); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("UpdateScooterLocation() error = %v, wantErr %v", err, resilience.ErrCircuitOpen)
+	}
+}
+
+func TestResilientUpdateScooterAvailability(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		mockRepositoryHandler func(mock *mock.MockScooterRepository)
+		wantErr               error
+	}{
+		"updating availability is not retried after a transient error": {
+			mockRepositoryHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, false).Return(redis.ErrClosed).Times(1)
+			},
+			wantErr: redis.ErrClosed,
+		},
+		"updating availability passes the domain error through": {
+			mockRepositoryHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, false).
+					Return(ErrScooterNotAvailable).Times(1)
+			},
+			wantErr: ErrScooterNotAvailable,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockRepository := mock.NewMockScooterRepository(controller)
+
+			tt.mockRepositoryHandler(mockRepository)
+
+			rr := newTestResilientRepository(mockRepository)
+
+			if err = rr.UpdateScooterAvailability(ctx, scooterUUID, false); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateScooterAvailability() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"no error": {
+			err:  nil,
+			want: false,
+		},
+		"closed client": {
+			err:  fmt.Errorf("getting scooter's city: %w", redis.ErrClosed),
+			want: true,
+		},
+		"connection refused": {
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			want: true,
+		},
+		"connection dropped": {
+			err:  io.EOF,
+			want: true,
+		},
+		"timed out call": {
+			err:  context.DeadlineExceeded,
+			want: true,
+		},
+		"server loading the data set": {
+			err:  fmt.Errorf("setting scooter's location: %w", testRedisError("LOADING Redis is loading the dataset")),
+			want: true,
+		},
+		"server rejecting the command": {
+			err:  testRedisError("WRONGTYPE Operation against a key holding the wrong kind of value"),
+			want: false,
+		},
+		"malformed data": {
+			err:  fmt.Errorf("unmarshaling rental: %w", &json.SyntaxError{}),
+			want: false,
+		},
+		"malformed uuid": {
+			err:  fmt.Errorf("parsing scooter's uuid: %w", errors.New("invalid UUID length: 3")),
+			want: false,
+		},
+		"missing key": {
+			err:  redis.Nil,
+			want: false,
+		},
+		"lost watch race": {
+			err:  redis.TxFailedErr,
+			want: false,
+		},
+		"business rule": {
+			err:  ErrScooterNotAvailable,
+			want: false,
+		},
+		"open circuit": {
+			err:  resilience.ErrCircuitOpen,
+			want: false,
+		},
+		"cancelled call": {
+			err:  context.Canceled,
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsTransientError(tt.err))
+		})
+	}
+}
+
+// testRedisError is the error reply of the Redis server.
+type testRedisError string
+
+func (e testRedisError) Error() string { return string(e) }
+
+func (testRedisError) RedisError() {}
+
+func newTestResilientRepository(repository *mock.MockScooterRepository) *resilientRepository {
+	return NewResilientRepository(
+		repository,
+		resilience.NewRetrier(testRetryAttempts, time.Millisecond, time.Millisecond),
+		resilience.NewCircuitBreaker(testFailureThreshold, time.Minute),
+	)
+}
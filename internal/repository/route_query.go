@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+const rentalRouteKeyPrefix = "rental_route:"
+
+// routePointRecord is the layout of the point of the route stored as JSON in the list under the rental route key.
+type routePointRecord struct {
+	Longitude float64   `json:"longitude"`
+	Latitude  float64   `json:"latitude"`
+	At        time.Time `json:"at"`
+}
+
+func rentalRouteKey(rentalUUID uuid.UUID) string {
+	return rentalRouteKeyPrefix + rentalUUID.String()
+}
+
+func appendRoutePoint(
+	ctx context.Context,
+	client redis.Cmdable,
+	rentalUUID uuid.UUID,
+	point *trackermodel.RoutePoint,
+) error {
+	pointJSON, err := marshalRoutePoint(point)
+	if err != nil {
+		return err
+	}
+
+	if err = client.RPush(ctx, rentalRouteKey(rentalUUID), pointJSON).Err(); err != nil {
+		return fmt.Errorf("appending point to rental's route in redis: %w", err)
+	}
+
+	return nil
+}
+
+func getRoute(ctx context.Context, client redis.Cmdable, rentalUUID uuid.UUID) ([]*trackermodel.RoutePoint, error) {
+	pointsJSON, err := client.LRange(ctx, rentalRouteKey(rentalUUID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting rental's route from redis: %w", err)
+	}
+
+	route := make([]*trackermodel.RoutePoint, len(pointsJSON))
+
+	for i, pointJSON := range pointsJSON {
+		if route[i], err = unmarshalRoutePoint(pointJSON); err != nil {
+			return nil, err
+		}
+	}
+
+	return route, nil
+}
+
+func marshalRoutePoint(point *trackermodel.RoutePoint) ([]byte, error) {
+	pointJSON, err := json.Marshal(&routePointRecord{
+		Longitude: point.Longitude,
+		Latitude:  point.Latitude,
+		At:        point.At,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling route point: %w", err)
+	}
+
+	return pointJSON, nil
+}
+
+func unmarshalRoutePoint(pointJSON string) (*trackermodel.RoutePoint, error) {
+	var record routePointRecord
+
+	if err := json.Unmarshal([]byte(pointJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling route point: %w", err)
+	}
+
+	return &trackermodel.RoutePoint{
+		Longitude: record.Longitude,
+		Latitude:  record.Latitude,
+		At:        record.At,
+	}, nil
+}
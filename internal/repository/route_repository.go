@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+type routeRepository struct {
+	client *redis.Client
+}
+
+func NewRouteRepository(client *redis.Client) *routeRepository {
+	return &routeRepository{
+		client: client,
+	}
+}
+
+func (rr *routeRepository) AppendRoutePoint(
+	ctx context.Context,
+	rentalUUID uuid.UUID,
+	point *trackermodel.RoutePoint,
+) error {
+	if err := appendRoutePoint(ctx, rr.client, rentalUUID, point); err != nil {
+		return fmt.Errorf("appending route point: %w", err)
+	}
+
+	return nil
+}
+
+func (rr *routeRepository) GetRoute(ctx context.Context, rentalUUID uuid.UUID) ([]*trackermodel.RoutePoint, error) {
+	route, err := getRoute(ctx, rr.client, rentalUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting route: %w", err)
+	}
+
+	return route, nil
+}
@@ -0,0 +1,92 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestGetRoute(t *testing.T) {
+	ctx := context.Background()
+
+	rentalUUID := uuid.New()
+	key := rentalRouteKey(rentalUUID)
+
+	first := &trackermodel.RoutePoint{
+		Longitude: 70.0,
+		Latitude:  60.0,
+		At:        time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+	}
+	second := &trackermodel.RoutePoint{
+		Longitude: 70.0,
+		Latitude:  60.001,
+		At:        time.Date(2024, time.May, 1, 12, 0, 3, 0, time.UTC),
+	}
+
+	firstJSON, err := marshalRoutePoint(first)
+	require.NoError(t, err)
+
+	secondJSON, err := marshalRoutePoint(second)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      []*trackermodel.RoutePoint
+		wantErr   bool
+	}{
+		"got route in the recorded order": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange(key, 0, -1).SetVal([]string{string(firstJSON), string(secondJSON)})
+			},
+			want:    []*trackermodel.RoutePoint{first, second},
+			wantErr: false,
+		},
+		"got empty route of the ride not moved yet": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange(key, 0, -1).SetVal([]string{})
+			},
+			want:    []*trackermodel.RoutePoint{},
+			wantErr: false,
+		},
+		"failed getting route, because a point is malformed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange(key, 0, -1).SetVal([]string{string(firstJSON), "{"})
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		"failed getting route, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange(key, 0, -1).SetErr(errors.New("redis down"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			rr := NewRouteRepository(redisClient)
+
+			got, err := rr.GetRoute(ctx, rentalUUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRoute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+const (
+	trackingSessionsKeyPrefix     = "tracking_sessions:"
+	trackingSessionOwnerKeyPrefix = "tracking_session_owner:"
+
+	// sessionClaimRetention is how long the claim of the rental outlives its tracking, so the start of the rental
+	// read again, e.g. by the consumer group of an instance started later, is not tracked once more.
+	sessionClaimRetention = 24 * time.Hour
+)
+
+// claimSessionScript sets the owner of the rental's tracking, unless another owner has set it first. The owner's
+// claim set to expire belongs to the tracking already ended. It returns 1 if the claim is held by the owner.
+var claimSessionScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if not owner then
+	redis.call('SET', KEYS[1], ARGV[1])
+	return 1
+end
+
+if owner == ARGV[1] and redis.call('TTL', KEYS[1]) == -1 then
+	return 1
+end
+
+return 0
+`)
+
+// deleteSessionScript sets the owner's claim of the rental to expire and removes the session of the scooter from the
+// hash only if it still tracks the rental, as the session of the next rental may have replaced it in the meantime.
+// It returns the number of the sessions removed.
+var deleteSessionScript = redis.NewScript(`
+if redis.call('GET', KEYS[2]) == ARGV[3] then
+	redis.call('EXPIRE', KEYS[2], ARGV[4])
+end
+
+local stored = redis.call('HGET', KEYS[1], ARGV[1])
+if not stored or cjson.decode(stored).rentalId ~= ARGV[2] then
+	return 0
+end
+
+return redis.call('HDEL', KEYS[1], ARGV[1])
+`)
+
+// sessionRecord is the layout of the tracking session stored as JSON in the hash of the owner's sessions, under the
+// UUID of the scooter.
+type sessionRecord struct {
+	ScooterUUID uuid.UUID `json:"scooterId"`
+	UserUUID    uuid.UUID `json:"userId"`
+	RentalUUID  uuid.UUID `json:"rentalId"`
+	City        string    `json:"city"`
+	Longitude   float64   `json:"longitude"`
+	Latitude    float64   `json:"latitude"`
+	StartedAt   time.Time `json:"startedAt"`
+}
+
+// trackingSessionsKey is the hash of the sessions tracked by the owner, the instance of the application.
+func trackingSessionsKey(owner string) string {
+	return trackingSessionsKeyPrefix + owner
+}
+
+// trackingSessionOwnerKey holds the owner tracking the rental, shared by all the instances of the application.
+func trackingSessionOwnerKey(rentalUUID uuid.UUID) string {
+	return trackingSessionOwnerKeyPrefix + rentalUUID.String()
+}
+
+func claimSession(ctx context.Context, client redis.Scripter, owner string, rentalUUID uuid.UUID) (bool, error) {
+	claimed, err := claimSessionScript.Run(ctx, client, []string{trackingSessionOwnerKey(rentalUUID)}, owner).Int()
+	if err != nil {
+		return false, fmt.Errorf("claiming tracking session in redis: %w", err)
+	}
+
+	return claimed == 1, nil
+}
+
+func saveSession(ctx context.Context, client redis.Cmdable, owner string, session *trackermodel.Session) error {
+	sessionJSON, err := marshalSession(session)
+	if err != nil {
+		return err
+	}
+
+	if err = client.HSet(ctx, trackingSessionsKey(owner), session.ScooterUUID.String(), sessionJSON).Err(); err != nil {
+		return fmt.Errorf("setting tracking session in redis: %w", err)
+	}
+
+	return nil
+}
+
+func deleteSession(ctx context.Context, client redis.Scripter, owner string, scooterUUID, rentalUUID uuid.UUID) error {
+	err := deleteSessionScript.Run(
+		ctx,
+		client,
+		[]string{trackingSessionsKey(owner), trackingSessionOwnerKey(rentalUUID)},
+		scooterUUID.String(),
+		rentalUUID.String(),
+		owner,
+		int(sessionClaimRetention.Seconds()),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("deleting tracking session from redis: %w", err)
+	}
+
+	return nil
+}
+
+func getSessions(ctx context.Context, client redis.Cmdable, owner string) ([]*trackermodel.Session, error) {
+	sessionsJSON, err := client.HGetAll(ctx, trackingSessionsKey(owner)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting tracking sessions from redis: %w", err)
+	}
+
+	sessions := make([]*trackermodel.Session, 0, len(sessionsJSON))
+
+	for _, sessionJSON := range sessionsJSON {
+		session, unmarshalErr := unmarshalSession(sessionJSON)
+		if unmarshalErr != nil {
+			return nil, unmarshalErr
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func marshalSession(session *trackermodel.Session) ([]byte, error) {
+	sessionJSON, err := json.Marshal(&sessionRecord{
+		ScooterUUID: session.ScooterUUID,
+		UserUUID:    session.UserUUID,
+		RentalUUID:  session.RentalUUID,
+		City:        session.City,
+		Longitude:   session.Longitude,
+		Latitude:    session.Latitude,
+		StartedAt:   session.StartedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling tracking session: %w", err)
+	}
+
+	return sessionJSON, nil
+}
+
+func unmarshalSession(sessionJSON string) (*trackermodel.Session, error) {
+	var record sessionRecord
+
+	if err := json.Unmarshal([]byte(sessionJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling tracking session: %w", err)
+	}
+
+	return &trackermodel.Session{
+		ScooterUUID: record.ScooterUUID,
+		UserUUID:    record.UserUUID,
+		RentalUUID:  record.RentalUUID,
+		City:        record.City,
+		Longitude:   record.Longitude,
+		Latitude:    record.Latitude,
+		StartedAt:   record.StartedAt,
+	}, nil
+}
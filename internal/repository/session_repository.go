@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+type sessionRepository struct {
+	client *redis.Client
+	owner  string
+}
+
+// NewSessionRepository creates the repository of the tracking sessions of the owner, so every instance of the
+// application resumes only the rides it tracked itself.
+func NewSessionRepository(client *redis.Client, owner string) *sessionRepository {
+	return &sessionRepository{
+		client: client,
+		owner:  owner,
+	}
+}
+
+func (sr *sessionRepository) SaveSession(ctx context.Context, session *trackermodel.Session) error {
+	if err := saveSession(ctx, sr.client, sr.owner, session); err != nil {
+		return fmt.Errorf("saving tracking session: %w", err)
+	}
+
+	return nil
+}
+
+func (sr *sessionRepository) ClaimSession(ctx context.Context, rentalUUID uuid.UUID) (bool, error) {
+	claimed, err := claimSession(ctx, sr.client, sr.owner, rentalUUID)
+	if err != nil {
+		return false, fmt.Errorf("claiming tracking session: %w", err)
+	}
+
+	return claimed, nil
+}
+
+func (sr *sessionRepository) DeleteSession(ctx context.Context, scooterUUID, rentalUUID uuid.UUID) error {
+	if err := deleteSession(ctx, sr.client, sr.owner, scooterUUID, rentalUUID); err != nil {
+		return fmt.Errorf("deleting tracking session: %w", err)
+	}
+
+	return nil
+}
+
+func (sr *sessionRepository) GetSessions(ctx context.Context) ([]*trackermodel.Session, error) {
+	sessions, err := getSessions(ctx, sr.client, sr.owner)
+	if err != nil {
+		return nil, fmt.Errorf("getting tracking sessions: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,210 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+const testSessionOwner = "tracker-1"
+
+func TestGetSessions(t *testing.T) {
+	ctx := context.Background()
+
+	key := trackingSessionsKey(testSessionOwner)
+
+	first := &trackermodel.Session{
+		ScooterUUID: uuid.New(),
+		UserUUID:    uuid.New(),
+		RentalUUID:  uuid.New(),
+		City:        "Montreal",
+		Longitude:   70.0,
+		Latitude:    60.0,
+		StartedAt:   time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+	}
+	second := &trackermodel.Session{
+		ScooterUUID: uuid.New(),
+		UserUUID:    uuid.New(),
+		RentalUUID:  uuid.New(),
+		City:        "Ottawa",
+		Longitude:   69.99,
+		Latitude:    59.99,
+		StartedAt:   time.Date(2024, time.May, 1, 12, 5, 0, 0, time.UTC),
+	}
+
+	firstJSON, err := marshalSession(first)
+	require.NoError(t, err)
+
+	secondJSON, err := marshalSession(second)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      []*trackermodel.Session
+		wantErr   bool
+	}{
+		"got sessions of the owner": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(key).SetVal(map[string]string{
+					first.ScooterUUID.String():  string(firstJSON),
+					second.ScooterUUID.String(): string(secondJSON),
+				})
+			},
+			want:    []*trackermodel.Session{first, second},
+			wantErr: false,
+		},
+		"got no sessions, as no scooter is tracked": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(key).SetVal(map[string]string{})
+			},
+			want:    []*trackermodel.Session{},
+			wantErr: false,
+		},
+		"failed getting sessions, because a session is malformed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(key).SetVal(map[string]string{first.ScooterUUID.String(): "{"})
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		"failed getting sessions, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(key).SetErr(errors.New("redis down"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			sr := NewSessionRepository(redisClient, testSessionOwner)
+
+			got, err := sr.GetSessions(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSessions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// the sessions come from the hash, so they are in no particular order
+			require.ElementsMatch(t, tt.want, got)
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID, rentalUUID := uuid.New(), uuid.New()
+
+	keys := []string{trackingSessionsKey(testSessionOwner), trackingSessionOwnerKey(rentalUUID)}
+	args := []any{scooterUUID.String(), rentalUUID.String(), testSessionOwner, 86400}
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		wantErr   bool
+	}{
+		"deleted session of the rental": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(deleteSessionScript.Hash(), keys, args...).
+					SetVal(int64(1))
+			},
+			wantErr: false,
+		},
+		"kept session of another rental": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(deleteSessionScript.Hash(), keys, args...).
+					SetVal(int64(0))
+			},
+			wantErr: false,
+		},
+		"failed deleting session, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(deleteSessionScript.Hash(), keys, args...).
+					SetErr(errors.New("redis down"))
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			sr := NewSessionRepository(redisClient, testSessionOwner)
+
+			err := sr.DeleteSession(ctx, scooterUUID, rentalUUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClaimSession(t *testing.T) {
+	ctx := context.Background()
+
+	rentalUUID := uuid.New()
+	key := trackingSessionOwnerKey(rentalUUID)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      bool
+		wantErr   bool
+	}{
+		"claimed session of the rental": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(claimSessionScript.Hash(), []string{key}, testSessionOwner).SetVal(int64(1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		"failed claiming session, because another owner claimed it": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(claimSessionScript.Hash(), []string{key}, testSessionOwner).SetVal(int64(0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		"failed claiming session, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(claimSessionScript.Hash(), []string{key}, testSessionOwner).
+					SetErr(errors.New("redis down"))
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			sr := NewSessionRepository(redisClient, testSessionOwner)
+
+			got, err := sr.ClaimSession(ctx, rentalUUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ClaimSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+const scooterTelemetryKeyPrefix = "scooter_telemetry:"
+
+// telemetryRecord is the layout of the latest report of the scooter stored as JSON under the scooter telemetry key.
+type telemetryRecord struct {
+	Longitude float64   `json:"longitude"`
+	Latitude  float64   `json:"latitude"`
+	At        time.Time `json:"at"`
+	Speed     float64   `json:"speed"`
+	Battery   int       `json:"battery"`
+	Locked    bool      `json:"locked"`
+}
+
+func scooterTelemetryKey(scooterUUID uuid.UUID) string {
+	return scooterTelemetryKeyPrefix + scooterUUID.String()
+}
+
+func getTelemetry(ctx context.Context, client redis.Cmdable, scooterUUID uuid.UUID) (*trackermodel.Telemetry, error) {
+	telemetryJSON, err := client.Get(ctx, scooterTelemetryKey(scooterUUID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.ErrTelemetryNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's telemetry from redis: %w", err)
+	}
+
+	return unmarshalTelemetry(telemetryJSON)
+}
+
+func saveTelemetry(
+	ctx context.Context,
+	client *redis.Client,
+	scooterUUID uuid.UUID,
+	telemetry *trackermodel.Telemetry,
+) error {
+	telemetryJSON, err := marshalTelemetry(telemetry)
+	if err != nil {
+		return err
+	}
+
+	key := scooterTelemetryKey(scooterUUID)
+
+	// make sure the report stored by the concurrent request of the same scooter is not replaced by an earlier one
+	if err = client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, getErr := getTelemetry(ctx, tx, scooterUUID)
+		if getErr != nil && !errors.Is(getErr, service.ErrTelemetryNotFound) {
+			return getErr
+		}
+
+		if stored != nil && !telemetry.At.After(stored.At) {
+			return nil
+		}
+
+		if _, pipeErr := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, key, telemetryJSON, 0).Err()
+		}); pipeErr != nil {
+			return fmt.Errorf("setting scooter's telemetry in redis: %w", pipeErr)
+		}
+
+		return nil
+	}, key); err != nil {
+		return fmt.Errorf("saving scooter's telemetry: %w", err)
+	}
+
+	return nil
+}
+
+func marshalTelemetry(telemetry *trackermodel.Telemetry) ([]byte, error) {
+	telemetryJSON, err := json.Marshal(&telemetryRecord{
+		Longitude: telemetry.Longitude,
+		Latitude:  telemetry.Latitude,
+		At:        telemetry.At,
+		Speed:     telemetry.Speed,
+		Battery:   telemetry.Battery,
+		Locked:    telemetry.Locked,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling telemetry: %w", err)
+	}
+
+	return telemetryJSON, nil
+}
+
+func unmarshalTelemetry(telemetryJSON string) (*trackermodel.Telemetry, error) {
+	var record telemetryRecord
+
+	if err := json.Unmarshal([]byte(telemetryJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling telemetry: %w", err)
+	}
+
+	return &trackermodel.Telemetry{
+		Longitude: record.Longitude,
+		Latitude:  record.Latitude,
+		At:        record.At,
+		Speed:     record.Speed,
+		Battery:   record.Battery,
+		Locked:    record.Locked,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+type telemetryRepository struct {
+	client *redis.Client
+}
+
+func NewTelemetryRepository(client *redis.Client) *telemetryRepository {
+	return &telemetryRepository{
+		client: client,
+	}
+}
+
+func (tr *telemetryRepository) GetTelemetry(
+	ctx context.Context,
+	scooterUUID uuid.UUID,
+) (*trackermodel.Telemetry, error) {
+	telemetry, err := getTelemetry(ctx, tr.client, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting telemetry: %w", err)
+	}
+
+	return telemetry, nil
+}
+
+func (tr *telemetryRepository) SaveTelemetry(
+	ctx context.Context,
+	scooterUUID uuid.UUID,
+	telemetry *trackermodel.Telemetry,
+) error {
+	if err := saveTelemetry(ctx, tr.client, scooterUUID, telemetry); err != nil {
+		return fmt.Errorf("saving telemetry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestSaveTelemetry(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID := uuid.New()
+	key := scooterTelemetryKey(scooterUUID)
+
+	telemetry := &trackermodel.Telemetry{
+		Longitude: 70.0,
+		Latitude:  60.0,
+		At:        time.Date(2024, time.May, 1, 12, 0, 10, 0, time.UTC),
+		Speed:     18.5,
+		Battery:   80,
+	}
+
+	telemetryJSON, err := marshalTelemetry(telemetry)
+	require.NoError(t, err)
+
+	earlierJSON, err := marshalTelemetry(&trackermodel.Telemetry{At: telemetry.At.Add(-time.Second)})
+	require.NoError(t, err)
+
+	laterJSON, err := marshalTelemetry(&trackermodel.Telemetry{At: telemetry.At.Add(time.Second)})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		wantErr   bool
+	}{
+		"saved the first telemetry of the scooter": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).RedisNil()
+				mock.ExpectTxPipeline()
+				mock.ExpectSet(key, telemetryJSON, 0).SetVal("OK")
+				mock.ExpectTxPipelineExec()
+			},
+			wantErr: false,
+		},
+		"saved the telemetry later than the stored one": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).SetVal(string(earlierJSON))
+				mock.ExpectTxPipeline()
+				mock.ExpectSet(key, telemetryJSON, 0).SetVal("OK")
+				mock.ExpectTxPipelineExec()
+			},
+			wantErr: false,
+		},
+		"kept the stored telemetry, because it is later": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).SetVal(string(laterJSON))
+			},
+			wantErr: false,
+		},
+		"failed saving telemetry, because the stored one is malformed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).SetVal("{")
+			},
+			wantErr: true,
+		},
+		"failed saving telemetry, because redis failed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).SetErr(errors.New("redis down"))
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			tr := NewTelemetryRepository(redisClient)
+
+			if err := tr.SaveTelemetry(ctx, scooterUUID, telemetry); (err != nil) != tt.wantErr {
+				t.Errorf("SaveTelemetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+const userKeyPrefix = "user:"
+
+// userRecord is the layout of the user stored as JSON under the user key.
+type userRecord struct {
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Phone         string    `json:"phone"`
+	PreferredCity string    `json:"preferred_city"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func userKey(userUUID uuid.UUID) string {
+	return userKeyPrefix + userUUID.String()
+}
+
+func createUser(ctx context.Context, client *redis.Client, user *usermodel.User) error {
+	userJSON, err := marshalUser(user)
+	if err != nil {
+		return err
+	}
+
+	created, err := client.SetNX(ctx, userKey(user.UUID), userJSON, 0).Result()
+	if err != nil {
+		return fmt.Errorf("creating user in redis: %w", err)
+	}
+
+	if !created {
+		return service.ErrUserAlreadyExists
+	}
+
+	return nil
+}
+
+func getUser(ctx context.Context, client redis.Cmdable, userUUID uuid.UUID) (*usermodel.User, error) {
+	userJSON, err := client.Get(ctx, userKey(userUUID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting user from redis: %w", err)
+	}
+
+	var record userRecord
+
+	if err = json.Unmarshal([]byte(userJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling user: %w", err)
+	}
+
+	return &usermodel.User{
+		UUID: userUUID,
+		Profile: usermodel.Profile{
+			Name:          record.Name,
+			Email:         record.Email,
+			Phone:         record.Phone,
+			PreferredCity: record.PreferredCity,
+		},
+		Status:    usermodel.Status(record.Status),
+		CreatedAt: record.CreatedAt,
+	}, nil
+}
+
+func updateUser(
+	ctx context.Context,
+	client *redis.Client,
+	userUUID uuid.UUID,
+	update func(user *usermodel.User),
+) (*usermodel.User, error) {
+	key := userKey(userUUID)
+
+	var user *usermodel.User
+
+	// make sure the user changed by the concurrent request, e.g. suspended by an admin, is not overwritten by the
+	// user read before the change
+	err := client.Watch(ctx, func(tx *redis.Tx) error {
+		var err error
+
+		if user, err = getUser(ctx, tx, userUUID); err != nil {
+			return err
+		}
+
+		update(user)
+
+		userJSON, err := marshalUser(user)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, key, userJSON, 0).Err()
+		}); err != nil {
+			return fmt.Errorf("setting user in redis: %w", err)
+		}
+
+		return nil
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil, service.ErrUserConflict
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func marshalUser(user *usermodel.User) ([]byte, error) {
+	userJSON, err := json.Marshal(userRecord{
+		Name:          user.Profile.Name,
+		Email:         user.Profile.Email,
+		Phone:         user.Profile.Phone,
+		PreferredCity: user.Profile.PreferredCity,
+		Status:        string(user.Status),
+		CreatedAt:     user.CreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling user: %w", err)
+	}
+
+	return userJSON, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+type userRepository struct {
+	client *redis.Client
+}
+
+func NewUserRepository(client *redis.Client) *userRepository {
+	return &userRepository{
+		client: client,
+	}
+}
+
+func (ur *userRepository) CreateUser(ctx context.Context, user *usermodel.User) error {
+	if err := createUser(ctx, ur.client, user); err != nil {
+		return fmt.Errorf("creating user: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *userRepository) GetUser(ctx context.Context, userUUID uuid.UUID) (*usermodel.User, error) {
+	user, err := getUser(ctx, ur.client, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (ur *userRepository) UpdateUser(
+	ctx context.Context,
+	userUUID uuid.UUID,
+	update func(user *usermodel.User),
+) (*usermodel.User, error) {
+	user, err := updateUser(ctx, ur.client, userUUID, update)
+	if err != nil {
+		return nil, fmt.Errorf("updating user: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,194 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
+
+	user := newTestUser(t)
+
+	userJSON, err := marshalUser(user)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		wantErr   error
+	}{
+		"successfully created user": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(userKey(user.UUID), userJSON, 0).SetVal(true)
+			},
+			wantErr: nil,
+		},
+		"failed creating user, because it already exists": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectSetNX(userKey(user.UUID), userJSON, 0).SetVal(false)
+			},
+			wantErr: service.ErrUserAlreadyExists,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			ur := NewUserRepository(redisClient)
+
+			if err = ur.CreateUser(ctx, user); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	ctx := context.Background()
+
+	user := newTestUser(t)
+
+	userJSON, err := marshalUser(user)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      *usermodel.User
+		wantErr   error
+	}{
+		"successfully got user": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectGet(userKey(user.UUID)).SetVal(string(userJSON))
+			},
+			want:    user,
+			wantErr: nil,
+		},
+		"failed getting user, because it does not exist": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectGet(userKey(user.UUID)).RedisNil()
+			},
+			want:    nil,
+			wantErr: service.ErrUserNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			ur := NewUserRepository(redisClient)
+
+			got, err := ur.GetUser(ctx, user.UUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetUser() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	ctx := context.Background()
+
+	user := newTestUser(t)
+	key := userKey(user.UUID)
+
+	userJSON, err := marshalUser(user)
+	require.NoError(t, err)
+
+	suspendedUser := *user
+	suspendedUser.Status = usermodel.StatusSuspended
+
+	suspendedUserJSON, err := marshalUser(&suspendedUser)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      *usermodel.User
+		wantErr   error
+	}{
+		"updated user": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).SetVal(string(userJSON))
+				mock.ExpectTxPipeline()
+				mock.ExpectSet(key, suspendedUserJSON, 0).SetVal("OK")
+				mock.ExpectTxPipelineExec()
+			},
+			want:    &suspendedUser,
+			wantErr: nil,
+		},
+		"failed updating user, because it does not exist": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).RedisNil()
+			},
+			want:    nil,
+			wantErr: service.ErrUserNotFound,
+		},
+		"failed updating user, because it was changed concurrently": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectWatch(key)
+				mock.ExpectGet(key).SetVal(string(userJSON))
+				mock.ExpectTxPipeline()
+				mock.ExpectSet(key, suspendedUserJSON, 0).SetErr(redis.TxFailedErr)
+			},
+			want:    nil,
+			wantErr: service.ErrUserConflict,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			ur := NewUserRepository(redisClient)
+
+			got, err := ur.UpdateUser(ctx, user.UUID, func(user *usermodel.User) {
+				user.Status = usermodel.StatusSuspended
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func newTestUser(t *testing.T) *usermodel.User {
+	t.Helper()
+
+	userUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	return usermodel.NewUser(userUUID, usermodel.Profile{
+		Name:          "Jane Doe",
+		Email:         "jane@example.com",
+		Phone:         "+15551234567",
+		PreferredCity: testCity,
+	}, time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	webhookmodel "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+const (
+	// webhookSubscriptionsKey is the hash holding every webhook subscription as JSON, by its UUID.
+	webhookSubscriptionsKey = "webhook_subscriptions"
+	// webhookDeadLettersKey is the hash holding every delivery that kept failing as JSON, by its UUID.
+	webhookDeadLettersKey = "webhook_dead_letters"
+)
+
+// subscriptionRecord is the layout of the webhook subscription stored in the subscriptions hash.
+type subscriptionRecord struct {
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// deadLetterRecord is the layout of the failed delivery stored in the dead letters hash. It carries the whole event,
+// so it can be replayed after the rental has changed.
+type deadLetterRecord struct {
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	RentalID       string          `json:"rental_id"`
+	Rental         json.RawMessage `json:"rental"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	FailedAt       time.Time       `json:"failed_at"`
+}
+
+func createSubscription(ctx context.Context, client *redis.Client, subscription *webhookmodel.Subscription) error {
+	subscriptionJSON, err := marshalSubscription(subscription)
+	if err != nil {
+		return err
+	}
+
+	if err = client.HSet(ctx, webhookSubscriptionsKey, subscription.UUID.String(), subscriptionJSON).Err(); err != nil {
+		return fmt.Errorf("creating webhook subscription in redis: %w", err)
+	}
+
+	return nil
+}
+
+func getSubscription(
+	ctx context.Context,
+	client *redis.Client,
+	subscriptionUUID uuid.UUID,
+) (*webhookmodel.Subscription, error) {
+	subscriptionJSON, err := client.HGet(ctx, webhookSubscriptionsKey, subscriptionUUID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.ErrWebhookNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook subscription from redis: %w", err)
+	}
+
+	return unmarshalSubscription(subscriptionUUID, subscriptionJSON)
+}
+
+func getSubscriptions(ctx context.Context, client *redis.Client) ([]*webhookmodel.Subscription, error) {
+	subscriptionsJSON, err := client.HGetAll(ctx, webhookSubscriptionsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook subscriptions from redis: %w", err)
+	}
+
+	subscriptions := make([]*webhookmodel.Subscription, 0, len(subscriptionsJSON))
+
+	for subscriptionID, subscriptionJSON := range subscriptionsJSON {
+		subscriptionUUID, err := uuid.Parse(subscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("parsing webhook subscription UUID: %w", err)
+		}
+
+		subscription, err := unmarshalSubscription(subscriptionUUID, subscriptionJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	slices.SortFunc(subscriptions, func(a, b *webhookmodel.Subscription) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return subscriptions, nil
+}
+
+func deleteSubscription(ctx context.Context, client *redis.Client, subscriptionUUID uuid.UUID) error {
+	deleted, err := client.HDel(ctx, webhookSubscriptionsKey, subscriptionUUID.String()).Result()
+	if err != nil {
+		return fmt.Errorf("deleting webhook subscription from redis: %w", err)
+	}
+
+	if deleted == 0 {
+		return service.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func saveDeadLetter(ctx context.Context, client *redis.Client, delivery *webhookmodel.Delivery) error {
+	deadLetterJSON, err := marshalDeadLetter(delivery)
+	if err != nil {
+		return err
+	}
+
+	if err = client.HSet(ctx, webhookDeadLettersKey, delivery.UUID.String(), deadLetterJSON).Err(); err != nil {
+		return fmt.Errorf("saving dead letter in redis: %w", err)
+	}
+
+	return nil
+}
+
+func getDeadLetter(ctx context.Context, client *redis.Client, deliveryUUID uuid.UUID) (*webhookmodel.Delivery, error) {
+	deadLetterJSON, err := client.HGet(ctx, webhookDeadLettersKey, deliveryUUID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, service.ErrDeadLetterNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting dead letter from redis: %w", err)
+	}
+
+	return unmarshalDeadLetter(deliveryUUID, deadLetterJSON)
+}
+
+func getDeadLetters(ctx context.Context, client *redis.Client) ([]*webhookmodel.Delivery, error) {
+	deadLettersJSON, err := client.HGetAll(ctx, webhookDeadLettersKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("getting dead letters from redis: %w", err)
+	}
+
+	deliveries := make([]*webhookmodel.Delivery, 0, len(deadLettersJSON))
+
+	for deliveryID, deadLetterJSON := range deadLettersJSON {
+		deliveryUUID, err := uuid.Parse(deliveryID)
+		if err != nil {
+			return nil, fmt.Errorf("parsing dead letter UUID: %w", err)
+		}
+
+		delivery, err := unmarshalDeadLetter(deliveryUUID, deadLetterJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	slices.SortFunc(deliveries, func(a, b *webhookmodel.Delivery) int {
+		return b.FailedAt.Compare(a.FailedAt)
+	})
+
+	return deliveries, nil
+}
+
+func deleteDeadLetter(ctx context.Context, client *redis.Client, deliveryUUID uuid.UUID) error {
+	deleted, err := client.HDel(ctx, webhookDeadLettersKey, deliveryUUID.String()).Result()
+	if err != nil {
+		return fmt.Errorf("deleting dead letter from redis: %w", err)
+	}
+
+	if deleted == 0 {
+		return service.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+func marshalSubscription(subscription *webhookmodel.Subscription) ([]byte, error) {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	subscriptionJSON, err := json.Marshal(subscriptionRecord{
+		URL:        subscription.URL,
+		EventTypes: eventTypes,
+		Secret:     subscription.Secret,
+		CreatedAt:  subscription.CreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling webhook subscription: %w", err)
+	}
+
+	return subscriptionJSON, nil
+}
+
+func unmarshalSubscription(subscriptionUUID uuid.UUID, subscriptionJSON string) (*webhookmodel.Subscription, error) {
+	var record subscriptionRecord
+
+	if err := json.Unmarshal([]byte(subscriptionJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling webhook subscription: %w", err)
+	}
+
+	eventTypes := make([]webhookmodel.EventType, 0, len(record.EventTypes))
+	for _, eventType := range record.EventTypes {
+		eventTypes = append(eventTypes, webhookmodel.EventType(eventType))
+	}
+
+	return webhookmodel.NewSubscription(subscriptionUUID, record.URL, eventTypes, record.Secret, record.CreatedAt), nil
+}
+
+func marshalDeadLetter(delivery *webhookmodel.Delivery) ([]byte, error) {
+	rentalJSON, err := marshalRental(delivery.Event.Rental)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetterJSON, err := json.Marshal(deadLetterRecord{
+		SubscriptionID: delivery.SubscriptionUUID.String(),
+		EventID:        delivery.Event.UUID.String(),
+		EventType:      string(delivery.Event.Type),
+		OccurredAt:     delivery.Event.OccurredAt,
+		RentalID:       delivery.Event.Rental.UUID.String(),
+		Rental:         rentalJSON,
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		FailedAt:       delivery.FailedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling dead letter: %w", err)
+	}
+
+	return deadLetterJSON, nil
+}
+
+func unmarshalDeadLetter(deliveryUUID uuid.UUID, deadLetterJSON string) (*webhookmodel.Delivery, error) {
+	var record deadLetterRecord
+
+	if err := json.Unmarshal([]byte(deadLetterJSON), &record); err != nil {
+		return nil, fmt.Errorf("unmarshaling dead letter: %w", err)
+	}
+
+	subscriptionUUID, err := uuid.Parse(record.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing webhook subscription UUID: %w", err)
+	}
+
+	eventUUID, err := uuid.Parse(record.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing event UUID: %w", err)
+	}
+
+	rentalUUID, err := uuid.Parse(record.RentalID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing rental UUID: %w", err)
+	}
+
+	rental, err := unmarshalRental(rentalUUID, string(record.Rental))
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookmodel.Delivery{
+		UUID:             deliveryUUID,
+		SubscriptionUUID: subscriptionUUID,
+		Event: &webhookmodel.Event{
+			UUID:       eventUUID,
+			Type:       webhookmodel.EventType(record.EventType),
+			OccurredAt: record.OccurredAt,
+			Rental:     rental,
+		},
+		Attempts:  record.Attempts,
+		LastError: record.LastError,
+		FailedAt:  record.FailedAt,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	webhookmodel "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+type webhookRepository struct {
+	client *redis.Client
+}
+
+func NewWebhookRepository(client *redis.Client) *webhookRepository {
+	return &webhookRepository{
+		client: client,
+	}
+}
+
+func (wr *webhookRepository) CreateSubscription(ctx context.Context, subscription *webhookmodel.Subscription) error {
+	if err := createSubscription(ctx, wr.client, subscription); err != nil {
+		return fmt.Errorf("creating webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (wr *webhookRepository) GetSubscription(
+	ctx context.Context,
+	subscriptionUUID uuid.UUID,
+) (*webhookmodel.Subscription, error) {
+	subscription, err := getSubscription(ctx, wr.client, subscriptionUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (wr *webhookRepository) GetSubscriptions(ctx context.Context) ([]*webhookmodel.Subscription, error) {
+	subscriptions, err := getSubscriptions(ctx, wr.client)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (wr *webhookRepository) DeleteSubscription(ctx context.Context, subscriptionUUID uuid.UUID) error {
+	if err := deleteSubscription(ctx, wr.client, subscriptionUUID); err != nil {
+		return fmt.Errorf("deleting webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (wr *webhookRepository) SaveDeadLetter(ctx context.Context, delivery *webhookmodel.Delivery) error {
+	if err := saveDeadLetter(ctx, wr.client, delivery); err != nil {
+		return fmt.Errorf("saving dead letter: %w", err)
+	}
+
+	return nil
+}
+
+func (wr *webhookRepository) GetDeadLetter(
+	ctx context.Context,
+	deliveryUUID uuid.UUID,
+) (*webhookmodel.Delivery, error) {
+	delivery, err := getDeadLetter(ctx, wr.client, deliveryUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting dead letter: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (wr *webhookRepository) GetDeadLetters(ctx context.Context) ([]*webhookmodel.Delivery, error) {
+	deliveries, err := getDeadLetters(ctx, wr.client)
+	if err != nil {
+		return nil, fmt.Errorf("getting dead letters: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (wr *webhookRepository) DeleteDeadLetter(ctx context.Context, deliveryUUID uuid.UUID) error {
+	if err := deleteDeadLetter(ctx, wr.client, deliveryUUID); err != nil {
+		return fmt.Errorf("deleting dead letter: %w", err)
+	}
+
+	return nil
+}
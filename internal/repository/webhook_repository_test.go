@@ -0,0 +1,207 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	webhookmodel "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+func TestGetSubscriptions(t *testing.T) {
+	ctx := context.Background()
+
+	older := newTestSubscription(t, time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC))
+	newer := newTestSubscription(t, time.Date(2024, time.May, 2, 12, 0, 0, 0, time.UTC))
+
+	olderJSON, err := marshalSubscription(older)
+	require.NoError(t, err)
+
+	newerJSON, err := marshalSubscription(newer)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      []*webhookmodel.Subscription
+		wantErr   bool
+	}{
+		"successfully got subscriptions, the oldest first": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(webhookSubscriptionsKey).SetVal(map[string]string{
+					newer.UUID.String(): string(newerJSON),
+					older.UUID.String(): string(olderJSON),
+				})
+			},
+			want:    []*webhookmodel.Subscription{older, newer},
+			wantErr: false,
+		},
+		"successfully got no subscriptions": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(webhookSubscriptionsKey).SetVal(map[string]string{})
+			},
+			want:    []*webhookmodel.Subscription{},
+			wantErr: false,
+		},
+		"failed getting subscriptions, because the record is malformed": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGetAll(webhookSubscriptionsKey).SetVal(map[string]string{
+					older.UUID.String(): "{",
+				})
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			wr := NewWebhookRepository(redisClient)
+
+			got, err := wr.GetSubscriptions(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSubscriptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSubscriptions() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	subscriptionUUID := uuid.New()
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		wantErr   error
+	}{
+		"successfully deleted subscription": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHDel(webhookSubscriptionsKey, subscriptionUUID.String()).SetVal(1)
+			},
+			wantErr: nil,
+		},
+		"failed deleting subscription, because it does not exist": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHDel(webhookSubscriptionsKey, subscriptionUUID.String()).SetVal(0)
+			},
+			wantErr: service.ErrWebhookNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			wr := NewWebhookRepository(redisClient)
+
+			if err := wr.DeleteSubscription(ctx, subscriptionUUID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteSubscription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetDeadLetter(t *testing.T) {
+	ctx := context.Background()
+
+	delivery := newTestDelivery(t)
+
+	deadLetterJSON, err := marshalDeadLetter(delivery)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		redisMock func(mock redismock.ClientMock)
+		want      *webhookmodel.Delivery
+		wantErr   error
+	}{
+		"successfully got dead letter": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(webhookDeadLettersKey, delivery.UUID.String()).SetVal(string(deadLetterJSON))
+			},
+			want:    delivery,
+			wantErr: nil,
+		},
+		"failed getting dead letter, because it does not exist": {
+			redisMock: func(mock redismock.ClientMock) {
+				mock.ExpectHGet(webhookDeadLettersKey, delivery.UUID.String()).RedisNil()
+			},
+			want:    nil,
+			wantErr: service.ErrDeadLetterNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			tt.redisMock(redisMock)
+
+			wr := NewWebhookRepository(redisClient)
+
+			got, err := wr.GetDeadLetter(ctx, delivery.UUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetDeadLetter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetDeadLetter() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestSubscription(t *testing.T, createdAt time.Time) *webhookmodel.Subscription {
+	t.Helper()
+
+	subscriptionUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	return webhookmodel.NewSubscription(
+		subscriptionUUID,
+		"https://partner.example.com/hooks",
+		[]webhookmodel.EventType{webhookmodel.EventRentalStarted, webhookmodel.EventRentalEnded},
+		"0123456789abcdef",
+		createdAt,
+	)
+}
+
+func newTestDelivery(t *testing.T) *webhookmodel.Delivery {
+	t.Helper()
+
+	deliveryUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rental := newTestRental(t)
+
+	return &webhookmodel.Delivery{
+		UUID:             deliveryUUID,
+		SubscriptionUUID: uuid.New(),
+		Event: &webhookmodel.Event{
+			UUID:       uuid.New(),
+			Type:       webhookmodel.EventRentalStarted,
+			OccurredAt: rental.StartedAt,
+			Rental:     rental,
+		},
+		Attempts:  5,
+		LastError: "receiver answered with 503 Service Unavailable",
+		FailedAt:  rental.StartedAt.Add(time.Minute),
+	}
+}
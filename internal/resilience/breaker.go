@@ -0,0 +1,167 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State int
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// OpenError is returned instead of calling the protected operation while the circuit is open. It matches
+// ErrCircuitOpen and tells the caller when the next attempt is going to be let through.
+type OpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitBreaker stops calling a failing dependency after a number of consecutive failures. Once the open timeout
+// passes a single probe call is let through, which closes the circuit on success and opens it again on failure.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            State
+	failures         int
+	openedAt         time.Time
+	probing          bool
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &CircuitBreaker{
+		state:            StateClosed,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// Execute calls fn unless the circuit is open. Errors for which isFailure returns false (e.g. domain errors) are
+// passed through without affecting the state of the circuit.
+func (cb *CircuitBreaker) Execute(fn func() error, isFailure func(err error) bool) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+
+	cb.record(err != nil && isFailure(err))
+
+	return err
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateOpen && cb.now().Sub(cb.openedAt) >= cb.openTimeout {
+		return StateHalfOpen
+	}
+
+	return cb.state
+}
+
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case StateOpen:
+		elapsed := cb.now().Sub(cb.openedAt)
+		if elapsed < cb.openTimeout {
+			return &OpenError{RetryAfter: cb.openTimeout - elapsed}
+		}
+
+		cb.state = StateHalfOpen
+		cb.probing = true
+
+		return nil
+	case StateHalfOpen:
+		if cb.probing {
+			return &OpenError{RetryAfter: cb.openTimeout}
+		}
+
+		cb.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (cb *CircuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	// calls started before the circuit opened must not extend the open period
+	if cb.state == StateOpen {
+		return
+	}
+
+	if cb.state == StateHalfOpen {
+		cb.probing = false
+
+		if failed {
+			cb.trip()
+
+			return
+		}
+
+		cb.state = StateClosed
+		cb.failures = 0
+
+		return
+	}
+
+	if !failed {
+		cb.failures = 0
+
+		return
+	}
+
+	cb.failures++
+	if cb.failures >= cb.failureThreshold {
+		cb.trip()
+	}
+}
+
+func (cb *CircuitBreaker) trip() {
+	cb.state = StateOpen
+	cb.openedAt = cb.now()
+	cb.failures = 0
+}
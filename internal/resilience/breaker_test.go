@@ -0,0 +1,109 @@
+//go:build unit
+
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	testFailureThreshold = 2
+	testOpenTimeout      = 10 * time.Second
+)
+
+func TestCircuitBreakerExecute(t *testing.T) {
+	tests := map[string]struct {
+		results   []error
+		wantState State
+		wantErr   error
+	}{
+		"stays closed after successful calls": {
+			results:   []error{nil, nil, nil},
+			wantState: StateClosed,
+			wantErr:   nil,
+		},
+		"stays closed when failures are not consecutive": {
+			results:   []error{errTransient, nil, errTransient},
+			wantState: StateClosed,
+			wantErr:   nil,
+		},
+		"stays closed when errors are not failures": {
+			results:   []error{errPermanent, errPermanent, errPermanent},
+			wantState: StateClosed,
+			wantErr:   nil,
+		},
+		"opens after consecutive failures and rejects the next call": {
+			results:   []error{errTransient, errTransient, nil},
+			wantState: StateOpen,
+			wantErr:   ErrCircuitOpen,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			breaker := NewCircuitBreaker(testFailureThreshold, testOpenTimeout)
+
+			var err error
+
+			for i := range tt.results {
+				err = breaker.Execute(func() error {
+					return tt.results[i]
+				}, isTransient)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := breaker.State(); got != tt.wantState {
+				t.Errorf("State() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := map[string]struct {
+		probeResult error
+		wantState   State
+	}{
+		"closes after a successful probe": {
+			probeResult: nil,
+			wantState:   StateClosed,
+		},
+		"opens again after a failed probe": {
+			probeResult: errTransient,
+			wantState:   StateOpen,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+
+			breaker := NewCircuitBreaker(1, testOpenTimeout)
+			breaker.now = func() time.Time { return now }
+
+			_ = breaker.Execute(func() error { return errTransient }, isTransient)
+
+			err := breaker.Execute(func() error { return nil }, isTransient)
+
+			var openErr *OpenError
+			if !errors.As(err, &openErr) || openErr.RetryAfter != testOpenTimeout {
+				t.Fatalf("Execute() error = %v, want open circuit retrying after %v", err, testOpenTimeout)
+			}
+
+			now = now.Add(testOpenTimeout)
+
+			if got := breaker.State(); got != StateHalfOpen {
+				t.Errorf("State() = %v, want %v", got, StateHalfOpen)
+			}
+
+			_ = breaker.Execute(func() error { return tt.probeResult }, isTransient)
+
+			if got := breaker.State(); got != tt.wantState {
+				t.Errorf("State() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const backoffMultiplier = 2
+
+// Retrier retries an operation with a bounded exponential backoff between the attempts.
+type Retrier struct {
+	attempts       int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func NewRetrier(attempts int, initialBackoff, maxBackoff time.Duration) *Retrier {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &Retrier{
+		attempts:       attempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+	}
+}
+
+// Do runs fn until it succeeds, returns an error that is not retryable or the attempts are used up. The wait before
+// each retry doubles (with jitter) up to the maximum backoff and is cut short when the context is done.
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error, retryable func(err error) bool) error {
+	var err error
+
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if attempt == r.attempts || !retryable(err) {
+			break
+		}
+
+		timer := time.NewTimer(r.backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("waiting for retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	return err
+}
+
+// backoff returns the wait before the retry following the given attempt. The result lies between the half and the
+// whole of the exponential delay, so concurrent callers do not retry in lockstep.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	delay := r.initialBackoff
+
+	for i := 1; i < attempt && delay < r.maxBackoff; i++ {
+		delay *= backoffMultiplier
+	}
+
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
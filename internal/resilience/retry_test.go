@@ -0,0 +1,121 @@
+//go:build unit
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errTransient = errors.New("transient error")
+	errPermanent = errors.New("permanent error")
+)
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func TestRetrierDo(t *testing.T) {
+	tests := map[string]struct {
+		attempts  int
+		results   []error
+		wantCalls int
+		wantErr   error
+	}{
+		"succeeded at the first attempt": {
+			attempts:  3,
+			results:   []error{nil},
+			wantCalls: 1,
+			wantErr:   nil,
+		},
+		"succeeded after transient errors": {
+			attempts:  3,
+			results:   []error{errTransient, errTransient, nil},
+			wantCalls: 3,
+			wantErr:   nil,
+		},
+		"failed after using up all attempts": {
+			attempts:  3,
+			results:   []error{errTransient, errTransient, errTransient},
+			wantCalls: 3,
+			wantErr:   errTransient,
+		},
+		"failed without retrying, because the error is not retryable": {
+			attempts:  3,
+			results:   []error{errPermanent},
+			wantCalls: 1,
+			wantErr:   errPermanent,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			retrier := NewRetrier(tt.attempts, time.Millisecond, 2*time.Millisecond)
+
+			var calls int
+
+			err := retrier.Do(context.Background(), func(ctx context.Context) error {
+				calls++
+
+				return tt.results[calls-1]
+			}, isTransient)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("Do() calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetrierDoStopsOnCancelledContext(t *testing.T) {
+	retrier := NewRetrier(3, time.Hour, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := retrier.Do(ctx, func(ctx context.Context) error {
+		cancel()
+
+		return errTransient
+	}, isTransient)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRetrierBackoff(t *testing.T) {
+	retrier := NewRetrier(10, 100*time.Millisecond, time.Second)
+
+	tests := map[string]struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		"first retry": {
+			attempt: 1,
+			min:     50 * time.Millisecond,
+			max:     100 * time.Millisecond,
+		},
+		"third retry": {
+			attempt: 3,
+			min:     200 * time.Millisecond,
+			max:     400 * time.Millisecond,
+		},
+		"retry capped by the maximum backoff": {
+			attempt: 8,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := retrier.backoff(tt.attempt); got < tt.min || got > tt.max {
+				t.Errorf("backoff() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
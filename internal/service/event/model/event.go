@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	// TypeRentalStarted is published when the scooter is rented, at its position at the time.
+	TypeRentalStarted Type = "rental.started"
+	// TypeRentalEnded is published when the scooter is freed, without its position.
+	TypeRentalEnded Type = "rental.ended"
+	// TypeScooterMoved is published for every new position of the tracked scooter.
+	TypeScooterMoved Type = "scooter.moved"
+)
+
+// Event is the change in the domain told to the other services through the event bus. Its UUID is kept when the
+// event is delivered again, so the handlers can recognise the ones they have already handled. The rental is not
+// known to the events of the moves.
+type Event struct {
+	UUID                uuid.UUID
+	Type                Type
+	OccurredAt          time.Time
+	RentalUUID          uuid.UUID
+	UserUUID            uuid.UUID
+	ScooterUUID         uuid.UUID
+	City                string
+	Longitude, Latitude float64
+}
+
+// OutboxEntry is the event recorded in the outbox along with the change it comes from, waiting to be published to
+// the event bus. The IDs of the entries follow the order they were recorded in.
+type OutboxEntry struct {
+	ID    string
+	Event *Event
+}
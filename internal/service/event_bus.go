@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+// EventHandler handles the event delivered by the bus. The events may be delivered more than once, so the handlers
+// must be idempotent.
+type EventHandler func(ctx context.Context, event *eventmodel.Event) error
+
+// GroupStart tells which events the group created by the consumer reads first.
+type GroupStart string
+
+const (
+	// GroupStartOldest makes the new group read the events kept by the bus from the oldest one, so the group shared
+	// by the instances doesn't miss the events published before it was created.
+	GroupStartOldest GroupStart = "oldest"
+	// GroupStartLatest makes the new group read only the events published after it was created, so the group of
+	// a single instance doesn't replay the history of the bus.
+	GroupStartLatest GroupStart = "latest"
+)
+
+//go:generate mockgen -source=event_bus.go -destination=mock/event_bus_mock.go -package=mock
+type EventPublisher interface {
+	Publish(ctx context.Context, event *eventmodel.Event) error
+}
+
+type EventBus interface {
+	EventPublisher
+	// Consume hands the events to the handler as the named consumer of the group, each group getting every event
+	// once, until the context is done. The event is acknowledged after the handler is done with it, so the events
+	// of a consumer stopped in the middle are handed to it again after its restart. The start is used only when the
+	// group doesn't exist yet.
+	Consume(ctx context.Context, group, consumer string, start GroupStart, handler EventHandler) error
+}
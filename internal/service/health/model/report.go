@@ -0,0 +1,24 @@
+package model
+
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusReady        = "ready"
+	StatusNotReady     = "not_ready"
+	StatusShuttingDown = "shutting_down"
+)
+
+type ComponentReport struct {
+	Status string
+	Error  string
+}
+
+type Report struct {
+	Status     string
+	Components map[string]ComponentReport
+}
+
+// Ready tells whether the service should receive traffic.
+func (r *Report) Ready() bool {
+	return r.Status == StatusReady
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/health/model"
+)
+
+// CheckFunc reports the health of a single component, returning an error when the component can't serve requests.
+type CheckFunc func(ctx context.Context) error
+
+type component struct {
+	name  string
+	check CheckFunc
+}
+
+type Service struct {
+	timeout      time.Duration
+	components   []component
+	shuttingDown atomic.Bool
+}
+
+func NewService(timeout time.Duration) *Service {
+	return &Service{
+		timeout: timeout,
+	}
+}
+
+// Register adds a component to the readiness check. It is meant to be called while setting up the application,
+// before the checks are served.
+func (s *Service) Register(name string, check CheckFunc) {
+	s.components = append(s.components, component{
+		name:  name,
+		check: check,
+	})
+}
+
+// SetShuttingDown makes the readiness check fail from now on, so load balancers stop sending traffic before the
+// server closes its connections.
+func (s *Service) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Readiness runs the checks of all registered components concurrently, each limited by the service timeout.
+func (s *Service) Readiness(ctx context.Context) *model.Report {
+	if s.shuttingDown.Load() {
+		return &model.Report{
+			Status:     model.StatusShuttingDown,
+			Components: map[string]model.ComponentReport{},
+		}
+	}
+
+	reports := make([]model.ComponentReport, len(s.components))
+	waitGroup := sync.WaitGroup{}
+
+	for i := range s.components {
+		waitGroup.Add(1)
+
+		go func(i int) {
+			defer waitGroup.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+
+			reports[i] = model.ComponentReport{Status: model.StatusUp}
+
+			if err := s.components[i].check(checkCtx); err != nil {
+				reports[i] = model.ComponentReport{
+					Status: model.StatusDown,
+					Error:  err.Error(),
+				}
+			}
+		}(i)
+	}
+
+	waitGroup.Wait()
+
+	result := &model.Report{
+		Status:     model.StatusReady,
+		Components: make(map[string]model.ComponentReport, len(s.components)),
+	}
+
+	for i := range s.components {
+		result.Components[s.components[i].name] = reports[i]
+
+		if reports[i].Status != model.StatusUp {
+			result.Status = model.StatusNotReady
+		}
+	}
+
+	return result
+}
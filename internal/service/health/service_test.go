@@ -0,0 +1,100 @@
+//go:build unit
+
+package health
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/health/model"
+)
+
+func TestReadiness(t *testing.T) {
+	healthyCheck := func(ctx context.Context) error {
+		return nil
+	}
+
+	failingCheck := func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}
+
+	hangingCheck := func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	tests := map[string]struct {
+		checks       map[string]CheckFunc
+		shuttingDown bool
+		want         *model.Report
+	}{
+		"ready when all components are up": {
+			checks: map[string]CheckFunc{
+				"redis":   healthyCheck,
+				"tracker": healthyCheck,
+			},
+			want: &model.Report{
+				Status: model.StatusReady,
+				Components: map[string]model.ComponentReport{
+					"redis":   {Status: model.StatusUp},
+					"tracker": {Status: model.StatusUp},
+				},
+			},
+		},
+		"not ready when one of the components is down": {
+			checks: map[string]CheckFunc{
+				"redis":   failingCheck,
+				"tracker": healthyCheck,
+			},
+			want: &model.Report{
+				Status: model.StatusNotReady,
+				Components: map[string]model.ComponentReport{
+					"redis":   {Status: model.StatusDown, Error: "connection refused"},
+					"tracker": {Status: model.StatusUp},
+				},
+			},
+		},
+		"not ready when a component check times out": {
+			checks: map[string]CheckFunc{
+				"redis": hangingCheck,
+			},
+			want: &model.Report{
+				Status: model.StatusNotReady,
+				Components: map[string]model.ComponentReport{
+					"redis": {Status: model.StatusDown, Error: context.DeadlineExceeded.Error()},
+				},
+			},
+		},
+		"not ready while shutting down": {
+			checks: map[string]CheckFunc{
+				"redis": healthyCheck,
+			},
+			shuttingDown: true,
+			want: &model.Report{
+				Status:     model.StatusShuttingDown,
+				Components: map[string]model.ComponentReport{},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewService(10 * time.Millisecond)
+
+			for componentName, check := range tt.checks {
+				s.Register(componentName, check)
+			}
+
+			if tt.shuttingDown {
+				s.SetShuttingDown()
+			}
+
+			if got := s.Readiness(context.Background()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Readiness() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package livemap
+
+import (
+	"sync"
+
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+// changesBuffer is the number of changes a subscriber can fall behind before it is dropped.
+const changesBuffer = 64
+
+// Feed fans the changes of the scooters out to the subscribers watching their cities. A subscriber that falls behind
+// is dropped rather than sent a partial picture, so it has to subscribe again and start from a fresh snapshot.
+type Feed struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan *rentalmodel.Scooter]struct{}
+}
+
+func NewFeed() *Feed {
+	return &Feed{
+		subscribers: make(map[string]map[chan *rentalmodel.Scooter]struct{}),
+	}
+}
+
+// Subscribe returns the channel of the changed scooters of the city and the function that cancels the subscription.
+// The channel is closed when the subscription is cancelled or the subscriber falls behind.
+func (f *Feed) Subscribe(city string) (<-chan *rentalmodel.Scooter, func()) {
+	changes := make(chan *rentalmodel.Scooter, changesBuffer)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subscribers[city] == nil {
+		f.subscribers[city] = make(map[chan *rentalmodel.Scooter]struct{})
+	}
+
+	f.subscribers[city][changes] = struct{}{}
+
+	return changes, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.drop(city, changes)
+	}
+}
+
+// Watched tells whether anyone subscribed to the changes of the city.
+func (f *Feed) Watched(city string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.subscribers[city]) > 0
+}
+
+// Publish sends the changed scooter to the subscribers of its city without waiting for them.
+func (f *Feed) Publish(scooter *rentalmodel.Scooter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for changes := range f.subscribers[scooter.City] {
+		changed := *scooter
+
+		select {
+		case changes <- &changed:
+		default:
+			f.drop(scooter.City, changes)
+		}
+	}
+}
+
+// drop removes the subscriber and closes its channel, unless it is already gone. The lock must be held.
+func (f *Feed) drop(city string, changes chan *rentalmodel.Scooter) {
+	if _, ok := f.subscribers[city][changes]; !ok {
+		return
+	}
+
+	delete(f.subscribers[city], changes)
+
+	if len(f.subscribers[city]) == 0 {
+		delete(f.subscribers, city)
+	}
+
+	close(changes)
+}
@@ -0,0 +1,79 @@
+//go:build unit
+
+package livemap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+const (
+	testCity      = "Montreal"
+	testOtherCity = "Ottawa"
+)
+
+func TestFeed(t *testing.T) {
+	scooter := rentalmodel.NewScooter("bad9f260-e3f5-4375-a4b3-3f6e258eb21f", testCity, 65.5637, 30.5234, true)
+
+	tests := map[string]struct {
+		run func(t *testing.T, f *Feed)
+	}{
+		"successfully published change to subscribers of its city": {
+			run: func(t *testing.T, f *Feed) {
+				first, _ := f.Subscribe(testCity)
+				second, _ := f.Subscribe(testCity)
+				other, _ := f.Subscribe(testOtherCity)
+
+				f.Publish(scooter)
+
+				require.Equal(t, scooter, <-first)
+				require.Equal(t, scooter, <-second)
+				require.Empty(t, other)
+			},
+		},
+		"successfully dropped subscriber that fell behind": {
+			run: func(t *testing.T, f *Feed) {
+				changes, unsubscribe := f.Subscribe(testCity)
+
+				for i := 0; i <= changesBuffer; i++ {
+					f.Publish(scooter)
+				}
+
+				require.False(t, f.Watched(testCity))
+
+				for i := 0; i < changesBuffer; i++ {
+					<-changes
+				}
+
+				_, open := <-changes
+				require.False(t, open)
+
+				// unsubscribing after being dropped must not close the channel again
+				unsubscribe()
+			},
+		},
+		"successfully unsubscribed": {
+			run: func(t *testing.T, f *Feed) {
+				changes, unsubscribe := f.Subscribe(testCity)
+				require.True(t, f.Watched(testCity))
+
+				unsubscribe()
+				unsubscribe()
+
+				f.Publish(scooter)
+
+				_, open := <-changes
+				require.False(t, open)
+				require.False(t, f.Watched(testCity))
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.run(t, NewFeed())
+		})
+	}
+}
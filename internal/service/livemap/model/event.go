@@ -0,0 +1,22 @@
+package model
+
+import rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+
+// EventType tells how a change of the scooter looks from the viewport.
+type EventType string
+
+const (
+	// EventAdded is sent when the scooter enters the viewport, by moving or by becoming of the watched availability.
+	EventAdded EventType = "added"
+	// EventMoved is sent when the scooter moves within the viewport.
+	EventMoved EventType = "moved"
+	// EventStateChanged is sent when the scooter within the viewport is rented or freed.
+	EventStateChanged EventType = "state"
+	// EventRemoved is sent when the scooter leaves the viewport.
+	EventRemoved EventType = "removed"
+)
+
+type Event struct {
+	Type    EventType
+	Scooter *rentalmodel.Scooter
+}
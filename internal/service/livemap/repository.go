@@ -0,0 +1,92 @@
+package livemap
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+// feedingRepository publishes the scooters changed through the wrapped repository to the feed. The changed scooter
+// is read back whole, as the updates carry only a part of it, and only when its city is watched.
+type feedingRepository struct {
+	repository service.ScooterRepository
+	feed       *Feed
+}
+
+func NewFeedingRepository(repository service.ScooterRepository, feed *Feed) *feedingRepository {
+	return &feedingRepository{
+		repository: repository,
+		feed:       feed,
+	}
+}
+
+func (fr *feedingRepository) GetScooters(
+	ctx context.Context,
+	geoRectangle *rentalmodel.GeoRectangle,
+) ([]*rentalmodel.Scooter, error) {
+	return fr.repository.GetScooters(ctx, geoRectangle)
+}
+
+func (fr *feedingRepository) GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*rentalmodel.Scooter, error) {
+	return fr.repository.GetScooter(ctx, scooterUUID)
+}
+
+func (fr *feedingRepository) UpdateScooterLocation(ctx context.Context, scooter *trackermodel.Scooter) error {
+	if err := fr.repository.UpdateScooterLocation(ctx, scooter); err != nil {
+		return err
+	}
+
+	if !fr.feed.Watched(scooter.City) {
+		return nil
+	}
+
+	scooterUUID, err := uuid.Parse(scooter.Name)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to parse the UUID of the moved scooter.", slog.Any("err", err))
+
+		return nil
+	}
+
+	fr.publish(ctx, scooterUUID)
+
+	return nil
+}
+
+func (fr *feedingRepository) UpdateScooterAvailability(
+	ctx context.Context,
+	scooterUUID uuid.UUID,
+	availability bool,
+	events ...*eventmodel.Event,
+) error {
+	if err := fr.repository.UpdateScooterAvailability(ctx, scooterUUID, availability, events...); err != nil {
+		return err
+	}
+
+	fr.publish(ctx, scooterUUID)
+
+	return nil
+}
+
+// publish reads the changed scooter and publishes it. The change is already stored, so a failure is only logged and
+// the subscribers catch up with the next change or snapshot.
+func (fr *feedingRepository) publish(ctx context.Context, scooterUUID uuid.UUID) {
+	scooter, err := fr.repository.GetScooter(ctx, scooterUUID)
+	if err != nil {
+		logging.FromContext(ctx).Warn(
+			"Failed to read the changed scooter for the live map.",
+			slog.String("scooter_id", scooterUUID.String()),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	fr.feed.Publish(scooter)
+}
@@ -0,0 +1,141 @@
+//go:build unit
+
+package livemap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestUpdateScooterLocation(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID := uuid.MustParse(testName)
+
+	movedScooter := trackermodel.NewScooter(testName, testCity, testLongitude, testLatitude)
+	storedScooter := rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false)
+
+	tests := map[string]struct {
+		watchedCity   string
+		mockHandler   func(mock *mock.MockScooterRepository)
+		wantPublished bool
+		wantErr       error
+	}{
+		"successfully published moved scooter of watched city": {
+			watchedCity: testCity,
+			mockHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterLocation(ctx, movedScooter).Return(nil).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(storedScooter, nil).Times(1)
+			},
+			wantPublished: true,
+			wantErr:       nil,
+		},
+		"successfully skipped moved scooter of unwatched city": {
+			watchedCity: testOtherCity,
+			mockHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterLocation(ctx, movedScooter).Return(nil).Times(1)
+			},
+			wantPublished: false,
+			wantErr:       nil,
+		},
+		"successfully updated location, despite failing to read moved scooter": {
+			watchedCity: testCity,
+			mockHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterLocation(ctx, movedScooter).Return(nil).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(nil, redis.ErrClosed).Times(1)
+			},
+			wantPublished: false,
+			wantErr:       nil,
+		},
+		"failed updating location, because repository threw an error": {
+			watchedCity: testCity,
+			mockHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterLocation(ctx, movedScooter).Return(redis.ErrClosed).Times(1)
+			},
+			wantPublished: false,
+			wantErr:       redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repository := mock.NewMockScooterRepository(gomock.NewController(t))
+			tt.mockHandler(repository)
+
+			feed := NewFeed()
+			changes, _ := feed.Subscribe(tt.watchedCity)
+
+			fr := NewFeedingRepository(repository, feed)
+
+			if err := fr.UpdateScooterLocation(ctx, movedScooter); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateScooterLocation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantPublished {
+				require.Equal(t, storedScooter, <-changes)
+			} else {
+				require.Empty(t, changes)
+			}
+		})
+	}
+}
+
+func TestUpdateScooterAvailability(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID := uuid.MustParse(testName)
+
+	storedScooter := rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false)
+
+	tests := map[string]struct {
+		mockHandler   func(mock *mock.MockScooterRepository)
+		wantPublished bool
+		wantErr       error
+	}{
+		"successfully published rented scooter": {
+			mockHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, false).Return(nil).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(storedScooter, nil).Times(1)
+			},
+			wantPublished: true,
+			wantErr:       nil,
+		},
+		"failed updating availability, because repository threw an error": {
+			mockHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, false).Return(redis.ErrClosed).Times(1)
+			},
+			wantPublished: false,
+			wantErr:       redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repository := mock.NewMockScooterRepository(gomock.NewController(t))
+			tt.mockHandler(repository)
+
+			feed := NewFeed()
+			changes, _ := feed.Subscribe(testCity)
+
+			fr := NewFeedingRepository(repository, feed)
+
+			if err := fr.UpdateScooterAvailability(ctx, scooterUUID, false); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateScooterAvailability() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantPublished {
+				require.Equal(t, storedScooter, <-changes)
+			} else {
+				require.Empty(t, changes)
+			}
+		})
+	}
+}
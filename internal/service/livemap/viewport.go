@@ -0,0 +1,92 @@
+package livemap
+
+import (
+	"math"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/livemap/model"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+// metersPerDegree is the length of a degree of latitude, and of longitude on the equator.
+const metersPerDegree = 111195
+
+// Viewport keeps the scooters a client sees in the rectangle of the map and turns the changes of the scooters into
+// the events of the client.
+type Viewport struct {
+	rectangle    *rentalmodel.GeoRectangle
+	availability *bool
+	visible      map[string]*rentalmodel.Scooter
+}
+
+// NewViewport creates the viewport of the rectangle showing only the scooters of the availability, unless it is nil.
+func NewViewport(rectangle *rentalmodel.GeoRectangle, availability *bool) *Viewport {
+	return &Viewport{
+		rectangle:    rectangle,
+		availability: availability,
+		visible:      make(map[string]*rentalmodel.Scooter),
+	}
+}
+
+// Snapshot sets the scooters found in the rectangle as the visible ones and returns those the viewport shows.
+func (v *Viewport) Snapshot(scooters []*rentalmodel.Scooter) []*rentalmodel.Scooter {
+	clear(v.visible)
+
+	shown := make([]*rentalmodel.Scooter, 0, len(scooters))
+
+	for _, scooter := range scooters {
+		if v.availability != nil && scooter.Availability != *v.availability {
+			continue
+		}
+
+		v.visible[scooter.Name] = scooter
+		shown = append(shown, scooter)
+	}
+
+	return shown
+}
+
+// Apply updates the viewport with the changed scooter, returning the event of the client and false when the client
+// doesn't see the change.
+func (v *Viewport) Apply(scooter *rentalmodel.Scooter) (model.Event, bool) {
+	previous, wasVisible := v.visible[scooter.Name]
+	isVisible := v.shows(scooter)
+
+	if isVisible {
+		v.visible[scooter.Name] = scooter
+	} else {
+		delete(v.visible, scooter.Name)
+	}
+
+	switch {
+	case !wasVisible && isVisible:
+		return model.Event{Type: model.EventAdded, Scooter: scooter}, true
+	case wasVisible && !isVisible:
+		return model.Event{Type: model.EventRemoved, Scooter: scooter}, true
+	case !isVisible:
+		return model.Event{}, false
+	case previous.Availability != scooter.Availability:
+		return model.Event{Type: model.EventStateChanged, Scooter: scooter}, true
+	case previous.Longitude != scooter.Longitude || previous.Latitude != scooter.Latitude:
+		return model.Event{Type: model.EventMoved, Scooter: scooter}, true
+	default:
+		return model.Event{}, false
+	}
+}
+
+// shows tells whether the scooter is of the watched availability and within the rectangle, measured on the plane
+// tangent to its center, which is accurate enough for the rectangles of a map screen.
+func (v *Viewport) shows(scooter *rentalmodel.Scooter) bool {
+	if scooter.City != v.rectangle.City {
+		return false
+	}
+
+	if v.availability != nil && scooter.Availability != *v.availability {
+		return false
+	}
+
+	north := (scooter.Latitude - v.rectangle.CenterLatitude) * metersPerDegree
+	east := (scooter.Longitude - v.rectangle.CenterLongitude) * metersPerDegree *
+		math.Cos(v.rectangle.CenterLatitude*math.Pi/180)
+
+	return math.Abs(north) <= v.rectangle.Height/2 && math.Abs(east) <= v.rectangle.Width/2
+}
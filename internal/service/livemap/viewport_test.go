@@ -0,0 +1,123 @@
+//go:build unit
+
+package livemap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/livemap/model"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+const (
+	testName      = "bad9f260-e3f5-4375-a4b3-3f6e258eb21f"
+	testLongitude = 65.5
+	testLatitude  = 30.5
+	// testStep moves the scooter by around 100 meters
+	testStep = 0.001
+)
+
+func TestViewportApply(t *testing.T) {
+	available := true
+
+	// the viewport spans around 500 meters in every direction from its center
+	rectangle := rentalmodel.NewRectangle(testCity, testLongitude, testLatitude, 1000, 1000)
+
+	tests := map[string]struct {
+		availability *bool
+		snapshot     []*rentalmodel.Scooter
+		change       *rentalmodel.Scooter
+		want         model.EventType
+		wantVisible  bool
+	}{
+		"successfully added scooter entering viewport": {
+			change:      rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude+4*testStep, true),
+			want:        model.EventAdded,
+			wantVisible: true,
+		},
+		"successfully moved scooter within viewport": {
+			snapshot: []*rentalmodel.Scooter{
+				rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false),
+			},
+			change:      rentalmodel.NewScooter(testName, testCity, testLongitude+testStep, testLatitude, false),
+			want:        model.EventMoved,
+			wantVisible: true,
+		},
+		"successfully changed state of scooter within viewport": {
+			snapshot: []*rentalmodel.Scooter{
+				rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, true),
+			},
+			change:      rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false),
+			want:        model.EventStateChanged,
+			wantVisible: true,
+		},
+		"successfully removed scooter leaving viewport": {
+			snapshot: []*rentalmodel.Scooter{
+				rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false),
+			},
+			change:      rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude+6*testStep, false),
+			want:        model.EventRemoved,
+			wantVisible: true,
+		},
+		"successfully removed scooter no longer of watched availability": {
+			availability: &available,
+			snapshot: []*rentalmodel.Scooter{
+				rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, true),
+			},
+			change:      rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false),
+			want:        model.EventRemoved,
+			wantVisible: true,
+		},
+		"successfully skipped scooter of unwatched availability": {
+			availability: &available,
+			change:       rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, false),
+			wantVisible:  false,
+		},
+		"successfully skipped scooter moving outside viewport": {
+			change:      rentalmodel.NewScooter(testName, testCity, testLongitude+8*testStep, testLatitude, true),
+			wantVisible: false,
+		},
+		"successfully skipped scooter of other city": {
+			change:      rentalmodel.NewScooter(testName, testOtherCity, testLongitude, testLatitude, true),
+			wantVisible: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			viewport := NewViewport(rectangle, tt.availability)
+			viewport.Snapshot(tt.snapshot)
+
+			got, visible := viewport.Apply(tt.change)
+			require.Equal(t, tt.wantVisible, visible)
+
+			if tt.wantVisible {
+				require.Equal(t, model.Event{Type: tt.want, Scooter: tt.change}, got)
+			}
+		})
+	}
+}
+
+func TestViewportSnapshot(t *testing.T) {
+	available := true
+
+	rectangle := rentalmodel.NewRectangle(testCity, testLongitude, testLatitude, 1000, 1000)
+
+	availableScooter := rentalmodel.NewScooter(testName, testCity, testLongitude, testLatitude, true)
+	rentedScooter := rentalmodel.NewScooter(
+		"32341255-c86a-4106-94e0-28dd9b3f88f2",
+		testCity,
+		testLongitude,
+		testLatitude,
+		false,
+	)
+
+	viewport := NewViewport(rectangle, &available)
+
+	require.Equal(
+		t,
+		[]*rentalmodel.Scooter{availableScooter},
+		viewport.Snapshot([]*rentalmodel.Scooter{availableScooter, rentedScooter}),
+	)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteOutboxEntry mocks base method.
+func (m *MockOutboxRepository) DeleteOutboxEntry(ctx context.Context, entryID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEntry", ctx, entryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEntry indicates an expected call of DeleteOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) DeleteOutboxEntry(ctx, entryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).DeleteOutboxEntry), ctx, entryID)
+}
+
+// GetOutboxEntries mocks base method.
+func (m *MockOutboxRepository) GetOutboxEntries(ctx context.Context, count int64) ([]*model.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEntries", ctx, count)
+	ret0, _ := ret[0].([]*model.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEntries indicates an expected call of GetOutboxEntries.
+func (mr *MockOutboxRepositoryMockRecorder) GetOutboxEntries(ctx, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEntries", reflect.TypeOf((*MockOutboxRepository)(nil).GetOutboxEntries), ctx, count)
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rental_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockRentalRepository is a mock of RentalRepository interface.
+type MockRentalRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRentalRepositoryMockRecorder
+}
+
+// MockRentalRepositoryMockRecorder is the mock recorder for MockRentalRepository.
+type MockRentalRepositoryMockRecorder struct {
+	mock *MockRentalRepository
+}
+
+// NewMockRentalRepository creates a new mock instance.
+func NewMockRentalRepository(ctrl *gomock.Controller) *MockRentalRepository {
+	mock := &MockRentalRepository{ctrl: ctrl}
+	mock.recorder = &MockRentalRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRentalRepository) EXPECT() *MockRentalRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRental mocks base method.
+func (m *MockRentalRepository) CreateRental(ctx context.Context, rental *model.Rental) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRental", ctx, rental)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRental indicates an expected call of CreateRental.
+func (mr *MockRentalRepositoryMockRecorder) CreateRental(ctx, rental interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRental", reflect.TypeOf((*MockRentalRepository)(nil).CreateRental), ctx, rental)
+}
+
+// EndRental mocks base method.
+func (m *MockRentalRepository) EndRental(ctx context.Context, rental *model.Rental) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndRental", ctx, rental)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndRental indicates an expected call of EndRental.
+func (mr *MockRentalRepositoryMockRecorder) EndRental(ctx, rental interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndRental", reflect.TypeOf((*MockRentalRepository)(nil).EndRental), ctx, rental)
+}
+
+// GetActiveRental mocks base method.
+func (m *MockRentalRepository) GetActiveRental(ctx context.Context, scooterUUID uuid.UUID) (*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveRental", ctx, scooterUUID)
+	ret0, _ := ret[0].(*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveRental indicates an expected call of GetActiveRental.
+func (mr *MockRentalRepositoryMockRecorder) GetActiveRental(ctx, scooterUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRental", reflect.TypeOf((*MockRentalRepository)(nil).GetActiveRental), ctx, scooterUUID)
+}
+
+// GetRental mocks base method.
+func (m *MockRentalRepository) GetRental(ctx context.Context, rentalUUID uuid.UUID) (*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRental", ctx, rentalUUID)
+	ret0, _ := ret[0].(*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRental indicates an expected call of GetRental.
+func (mr *MockRentalRepositoryMockRecorder) GetRental(ctx, rentalUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRental", reflect.TypeOf((*MockRentalRepository)(nil).GetRental), ctx, rentalUUID)
+}
+
+// GetUserActiveRentals mocks base method.
+func (m *MockRentalRepository) GetUserActiveRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserActiveRentals", ctx, userUUID)
+	ret0, _ := ret[0].([]*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserActiveRentals indicates an expected call of GetUserActiveRentals.
+func (mr *MockRentalRepositoryMockRecorder) GetUserActiveRentals(ctx, userUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserActiveRentals", reflect.TypeOf((*MockRentalRepository)(nil).GetUserActiveRentals), ctx, userUUID)
+}
+
+// GetUserRentals mocks base method.
+func (m *MockRentalRepository) GetUserRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRentals", ctx, userUUID)
+	ret0, _ := ret[0].([]*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRentals indicates an expected call of GetUserRentals.
+func (mr *MockRentalRepositoryMockRecorder) GetUserRentals(ctx, userUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRentals", reflect.TypeOf((*MockRentalRepository)(nil).GetUserRentals), ctx, userUUID)
+}
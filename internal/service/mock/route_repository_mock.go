@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: route_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockRouteRepository is a mock of RouteRepository interface.
+type MockRouteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRouteRepositoryMockRecorder
+}
+
+// MockRouteRepositoryMockRecorder is the mock recorder for MockRouteRepository.
+type MockRouteRepositoryMockRecorder struct {
+	mock *MockRouteRepository
+}
+
+// NewMockRouteRepository creates a new mock instance.
+func NewMockRouteRepository(ctrl *gomock.Controller) *MockRouteRepository {
+	mock := &MockRouteRepository{ctrl: ctrl}
+	mock.recorder = &MockRouteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRouteRepository) EXPECT() *MockRouteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AppendRoutePoint mocks base method.
+func (m *MockRouteRepository) AppendRoutePoint(ctx context.Context, rentalUUID uuid.UUID, point *model.RoutePoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendRoutePoint", ctx, rentalUUID, point)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendRoutePoint indicates an expected call of AppendRoutePoint.
+func (mr *MockRouteRepositoryMockRecorder) AppendRoutePoint(ctx, rentalUUID, point interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendRoutePoint", reflect.TypeOf((*MockRouteRepository)(nil).AppendRoutePoint), ctx, rentalUUID, point)
+}
+
+// GetRoute mocks base method.
+func (m *MockRouteRepository) GetRoute(ctx context.Context, rentalUUID uuid.UUID) ([]*model.RoutePoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoute", ctx, rentalUUID)
+	ret0, _ := ret[0].([]*model.RoutePoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoute indicates an expected call of GetRoute.
+func (mr *MockRouteRepositoryMockRecorder) GetRoute(ctx, rentalUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoute", reflect.TypeOf((*MockRouteRepository)(nil).GetRoute), ctx, rentalUUID)
+}
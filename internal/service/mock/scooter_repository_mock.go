@@ -8,8 +8,9 @@ import (
 	context "context"
 	reflect "reflect"
 
-	model "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
-	model0 "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	model "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	model0 "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	model1 "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 )
@@ -37,11 +38,26 @@ func (m *MockScooterRepository) EXPECT() *MockScooterRepositoryMockRecorder {
 	return m.recorder
 }
 
+// GetScooter mocks base method.
+func (m *MockScooterRepository) GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*model0.Scooter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScooter", ctx, scooterUUID)
+	ret0, _ := ret[0].(*model0.Scooter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScooter indicates an expected call of GetScooter.
+func (mr *MockScooterRepositoryMockRecorder) GetScooter(ctx, scooterUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooter", reflect.TypeOf((*MockScooterRepository)(nil).GetScooter), ctx, scooterUUID)
+}
+
 // GetScooters mocks base method.
-func (m *MockScooterRepository) GetScooters(ctx context.Context, geoRectangle *model.GeoRectangle) ([]*model.Scooter, error) {
+func (m *MockScooterRepository) GetScooters(ctx context.Context, geoRectangle *model0.GeoRectangle) ([]*model0.Scooter, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetScooters", ctx, geoRectangle)
-	ret0, _ := ret[0].([]*model.Scooter)
+	ret0, _ := ret[0].([]*model0.Scooter)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -53,21 +69,26 @@ func (mr *MockScooterRepositoryMockRecorder) GetScooters(ctx, geoRectangle inter
 }
 
 // UpdateScooterAvailability mocks base method.
-func (m *MockScooterRepository) UpdateScooterAvailability(ctx context.Context, scooterUUID uuid.UUID, availability bool) error {
+func (m *MockScooterRepository) UpdateScooterAvailability(ctx context.Context, scooterUUID uuid.UUID, availability bool, events ...*model.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateScooterAvailability", ctx, scooterUUID, availability)
+	varargs := []interface{}{ctx, scooterUUID, availability}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateScooterAvailability", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateScooterAvailability indicates an expected call of UpdateScooterAvailability.
-func (mr *MockScooterRepositoryMockRecorder) UpdateScooterAvailability(ctx, scooterUUID, availability interface{}) *gomock.Call {
+func (mr *MockScooterRepositoryMockRecorder) UpdateScooterAvailability(ctx, scooterUUID, availability interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScooterAvailability", reflect.TypeOf((*MockScooterRepository)(nil).UpdateScooterAvailability), ctx, scooterUUID, availability)
+	varargs := append([]interface{}{ctx, scooterUUID, availability}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScooterAvailability", reflect.TypeOf((*MockScooterRepository)(nil).UpdateScooterAvailability), varargs...)
 }
 
 // UpdateScooterLocation mocks base method.
-func (m *MockScooterRepository) UpdateScooterLocation(ctx context.Context, scooter *model0.Scooter) error {
+func (m *MockScooterRepository) UpdateScooterLocation(ctx context.Context, scooter *model1.Scooter) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateScooterLocation", ctx, scooter)
 	ret0, _ := ret[0].(error)
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: session_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockTrackingSessionRepository is a mock of TrackingSessionRepository interface.
+type MockTrackingSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrackingSessionRepositoryMockRecorder
+}
+
+// MockTrackingSessionRepositoryMockRecorder is the mock recorder for MockTrackingSessionRepository.
+type MockTrackingSessionRepositoryMockRecorder struct {
+	mock *MockTrackingSessionRepository
+}
+
+// NewMockTrackingSessionRepository creates a new mock instance.
+func NewMockTrackingSessionRepository(ctrl *gomock.Controller) *MockTrackingSessionRepository {
+	mock := &MockTrackingSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockTrackingSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrackingSessionRepository) EXPECT() *MockTrackingSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimSession mocks base method.
+func (m *MockTrackingSessionRepository) ClaimSession(ctx context.Context, rentalUUID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSession", ctx, rentalUUID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimSession indicates an expected call of ClaimSession.
+func (mr *MockTrackingSessionRepositoryMockRecorder) ClaimSession(ctx, rentalUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSession", reflect.TypeOf((*MockTrackingSessionRepository)(nil).ClaimSession), ctx, rentalUUID)
+}
+
+// DeleteSession mocks base method.
+func (m *MockTrackingSessionRepository) DeleteSession(ctx context.Context, scooterUUID, rentalUUID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, scooterUUID, rentalUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockTrackingSessionRepositoryMockRecorder) DeleteSession(ctx, scooterUUID, rentalUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockTrackingSessionRepository)(nil).DeleteSession), ctx, scooterUUID, rentalUUID)
+}
+
+// GetSessions mocks base method.
+func (m *MockTrackingSessionRepository) GetSessions(ctx context.Context) ([]*model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx)
+	ret0, _ := ret[0].([]*model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockTrackingSessionRepositoryMockRecorder) GetSessions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockTrackingSessionRepository)(nil).GetSessions), ctx)
+}
+
+// SaveSession mocks base method.
+func (m *MockTrackingSessionRepository) SaveSession(ctx context.Context, session *model.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSession indicates an expected call of SaveSession.
+func (mr *MockTrackingSessionRepositoryMockRecorder) SaveSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSession", reflect.TypeOf((*MockTrackingSessionRepository)(nil).SaveSession), ctx, session)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: telemetry_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockTelemetryRepository is a mock of TelemetryRepository interface.
+type MockTelemetryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTelemetryRepositoryMockRecorder
+}
+
+// MockTelemetryRepositoryMockRecorder is the mock recorder for MockTelemetryRepository.
+type MockTelemetryRepositoryMockRecorder struct {
+	mock *MockTelemetryRepository
+}
+
+// NewMockTelemetryRepository creates a new mock instance.
+func NewMockTelemetryRepository(ctrl *gomock.Controller) *MockTelemetryRepository {
+	mock := &MockTelemetryRepository{ctrl: ctrl}
+	mock.recorder = &MockTelemetryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTelemetryRepository) EXPECT() *MockTelemetryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetTelemetry mocks base method.
+func (m *MockTelemetryRepository) GetTelemetry(ctx context.Context, scooterUUID uuid.UUID) (*model.Telemetry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTelemetry", ctx, scooterUUID)
+	ret0, _ := ret[0].(*model.Telemetry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTelemetry indicates an expected call of GetTelemetry.
+func (mr *MockTelemetryRepositoryMockRecorder) GetTelemetry(ctx, scooterUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTelemetry", reflect.TypeOf((*MockTelemetryRepository)(nil).GetTelemetry), ctx, scooterUUID)
+}
+
+// SaveTelemetry mocks base method.
+func (m *MockTelemetryRepository) SaveTelemetry(ctx context.Context, scooterUUID uuid.UUID, telemetry *model.Telemetry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTelemetry", ctx, scooterUUID, telemetry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTelemetry indicates an expected call of SaveTelemetry.
+func (mr *MockTelemetryRepositoryMockRecorder) SaveTelemetry(ctx, scooterUUID, telemetry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTelemetry", reflect.TypeOf((*MockTelemetryRepository)(nil).SaveTelemetry), ctx, scooterUUID, telemetry)
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+//go:generate mockgen -source=outbox_repository.go -destination=mock/outbox_repository_mock.go -package=mock
+type OutboxRepository interface {
+	// GetOutboxEntries returns up to count of the oldest events recorded in the outbox by the ScooterRepository.
+	GetOutboxEntries(ctx context.Context, count int64) ([]*eventmodel.OutboxEntry, error)
+	// DeleteOutboxEntry removes the published entry from the outbox.
+	DeleteOutboxEntry(ctx context.Context, entryID string) error
+}
@@ -36,12 +36,28 @@ func (m *MockRentalService) EXPECT() *MockRentalServiceMockRecorder {
 	return m.recorder
 }
 
+// EndRental mocks base method.
+func (m *MockRentalService) EndRental(ctx context.Context, userUUID, rentalUUID uuid.UUID) (*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndRental", ctx, userUUID, rentalUUID)
+	ret0, _ := ret[0].(*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndRental indicates an expected call of EndRental.
+func (mr *MockRentalServiceMockRecorder) EndRental(ctx, userUUID, rentalUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndRental", reflect.TypeOf((*MockRentalService)(nil).EndRental), ctx, userUUID, rentalUUID)
+}
+
 // Free mocks base method.
-func (m *MockRentalService) Free(ctx context.Context, scooterUUID uuid.UUID) error {
+func (m *MockRentalService) Free(ctx context.Context, scooterUUID uuid.UUID) (*model.Rental, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Free", ctx, scooterUUID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Free indicates an expected call of Free.
@@ -50,6 +66,51 @@ func (mr *MockRentalServiceMockRecorder) Free(ctx, scooterUUID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Free", reflect.TypeOf((*MockRentalService)(nil).Free), ctx, scooterUUID)
 }
 
+// GetActiveRentals mocks base method.
+func (m *MockRentalService) GetActiveRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.ActiveRental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveRentals", ctx, userUUID)
+	ret0, _ := ret[0].([]*model.ActiveRental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveRentals indicates an expected call of GetActiveRentals.
+func (mr *MockRentalServiceMockRecorder) GetActiveRentals(ctx, userUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRentals", reflect.TypeOf((*MockRentalService)(nil).GetActiveRentals), ctx, userUUID)
+}
+
+// GetRental mocks base method.
+func (m *MockRentalService) GetRental(ctx context.Context, userUUID, rentalUUID uuid.UUID) (*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRental", ctx, userUUID, rentalUUID)
+	ret0, _ := ret[0].(*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRental indicates an expected call of GetRental.
+func (mr *MockRentalServiceMockRecorder) GetRental(ctx, userUUID, rentalUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRental", reflect.TypeOf((*MockRentalService)(nil).GetRental), ctx, userUUID, rentalUUID)
+}
+
+// GetScooter mocks base method.
+func (m *MockRentalService) GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*model.Scooter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScooter", ctx, scooterUUID)
+	ret0, _ := ret[0].(*model.Scooter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScooter indicates an expected call of GetScooter.
+func (mr *MockRentalServiceMockRecorder) GetScooter(ctx, scooterUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooter", reflect.TypeOf((*MockRentalService)(nil).GetScooter), ctx, scooterUUID)
+}
+
 // GetScooters mocks base method.
 func (m *MockRentalService) GetScooters(ctx context.Context, rectangle *model.GeoRectangle) ([]*model.Scooter, error) {
 	m.ctrl.T.Helper()
@@ -65,16 +126,32 @@ func (mr *MockRentalServiceMockRecorder) GetScooters(ctx, rectangle interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooters", reflect.TypeOf((*MockRentalService)(nil).GetScooters), ctx, rectangle)
 }
 
+// GetUserRentals mocks base method.
+func (m *MockRentalService) GetUserRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.Rental, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRentals", ctx, userUUID)
+	ret0, _ := ret[0].([]*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRentals indicates an expected call of GetUserRentals.
+func (mr *MockRentalServiceMockRecorder) GetUserRentals(ctx, userUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRentals", reflect.TypeOf((*MockRentalService)(nil).GetUserRentals), ctx, userUUID)
+}
+
 // Rent mocks base method.
-func (m *MockRentalService) Rent(ctx context.Context, info *model.RentInfo) error {
+func (m *MockRentalService) Rent(ctx context.Context, userUUID uuid.UUID, info *model.RentInfo) (*model.Rental, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rent", ctx, info)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Rent", ctx, userUUID, info)
+	ret0, _ := ret[0].(*model.Rental)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Rent indicates an expected call of Rent.
-func (mr *MockRentalServiceMockRecorder) Rent(ctx, info interface{}) *gomock.Call {
+func (mr *MockRentalServiceMockRecorder) Rent(ctx, userUUID, info interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rent", reflect.TypeOf((*MockRentalService)(nil).Rent), ctx, info)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rent", reflect.TypeOf((*MockRentalService)(nil).Rent), ctx, userUUID, info)
 }
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Tariff prices the rides in the minor units of the currency: the unlock fee is charged once per ride and
+// the per-minute price for every started minute of it.
+type Tariff struct {
+	UnlockFee int64
+	PerMinute int64
+	Currency  string
+}
+
+func NewTariff(unlockFee, perMinute int64, currency string) *Tariff {
+	return &Tariff{
+		UnlockFee: unlockFee,
+		PerMinute: perMinute,
+		Currency:  currency,
+	}
+}
+
+// Fare returns the price of the ride lasting for the elapsed time.
+func (t *Tariff) Fare(elapsed time.Duration) int64 {
+	startedMinutes := int64((max(elapsed, 0) + time.Minute - 1) / time.Minute)
+
+	return t.UnlockFee + startedMinutes*t.PerMinute
+}
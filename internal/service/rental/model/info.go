@@ -1,13 +1,16 @@
 package model
 
 type RentInfo struct {
-	ScooterUUID string
-	City        string
+	ScooterUUID         string
+	City                string
+	Longitude, Latitude float64
 }
 
-func NewRentInfo(scooterUUID, city string) *RentInfo {
+func NewRentInfo(scooterUUID, city string, long, lat float64) *RentInfo {
 	return &RentInfo{
 		ScooterUUID: scooterUUID,
 		City:        city,
+		Longitude:   long,
+		Latitude:    lat,
 	}
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rental is a ride of the user on the scooter, active until it ends.
+type Rental struct {
+	UUID           uuid.UUID
+	UserUUID       uuid.UUID
+	ScooterUUID    uuid.UUID
+	City           string
+	StartLongitude float64
+	StartLatitude  float64
+	StartedAt      time.Time
+	EndedAt        *time.Time
+}
+
+func NewRental(rentalUUID, userUUID, scooterUUID uuid.UUID, info *RentInfo, startedAt time.Time) *Rental {
+	return &Rental{
+		UUID:           rentalUUID,
+		UserUUID:       userUUID,
+		ScooterUUID:    scooterUUID,
+		City:           info.City,
+		StartLongitude: info.Longitude,
+		StartLatitude:  info.Latitude,
+		StartedAt:      startedAt,
+	}
+}
+
+// Active tells whether the ride still goes on.
+func (r *Rental) Active() bool {
+	return r.EndedAt == nil
+}
+
+// ActiveRental is the ride in progress along with the last tracked position of its scooter and the fare so far.
+type ActiveRental struct {
+	Rental              *Rental
+	Longitude, Latitude float64
+	Elapsed             time.Duration
+	Fare                int64
+	Currency            string
+}
@@ -1,10 +1,14 @@
 package model
 
+import "time"
+
 type Scooter struct {
 	Name                string
 	Longitude, Latitude float64
 	City                string
 	Availability        bool
+	// UpdatedAt is the time the scooter was last moved or rented, zero when it is unknown.
+	UpdatedAt time.Time
 }
 
 func NewScooter(name, city string, long, lat float64, availability bool) *Scooter {
@@ -2,28 +2,55 @@ package rental
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
 	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 )
 
+var (
+	ErrInvalidScooterUUID = errors.New("scooter's uuid is invalid")
+	ErrRentalEnded        = errors.New("rental has already ended")
+)
+
 //go:generate mockgen -source=service.go -destination=mock/service_mock.go -package=mock
 type RentalService interface {
 	GetScooters(ctx context.Context, rectangle *model.GeoRectangle) ([]*model.Scooter, error)
-	Rent(ctx context.Context, info *model.RentInfo) error
-	Free(ctx context.Context, scooterUUID uuid.UUID) error
+	GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*model.Scooter, error)
+	Rent(ctx context.Context, userUUID uuid.UUID, info *model.RentInfo) (*model.Rental, error)
+	Free(ctx context.Context, scooterUUID uuid.UUID) (*model.Rental, error)
+	GetRental(ctx context.Context, userUUID, rentalUUID uuid.UUID) (*model.Rental, error)
+	GetUserRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.Rental, error)
+	EndRental(ctx context.Context, userUUID, rentalUUID uuid.UUID) (*model.Rental, error)
+	GetActiveRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.ActiveRental, error)
 }
 
 type rentalService struct {
 	scooterRepository service.ScooterRepository
+	rentalRepository  service.RentalRepository
+	tariff            *model.Tariff
+	now               func() time.Time
+	newUUID           func() uuid.UUID
 }
 
-func NewRentalService(repo service.ScooterRepository) *rentalService {
+func NewRentalService(
+	repo service.ScooterRepository,
+	rentals service.RentalRepository,
+	tariff *model.Tariff,
+) *rentalService {
 	return &rentalService{
 		scooterRepository: repo,
+		rentalRepository:  rentals,
+		tariff:            tariff,
+		now:               time.Now,
+		newUUID:           uuid.New,
 	}
 }
 
@@ -33,27 +60,232 @@ func (rs *rentalService) GetScooters(ctx context.Context, rectangle *model.GeoRe
 		return nil, fmt.Errorf("getting scooters in the searched area: %w", err)
 	}
 
+	logging.FromContext(ctx).Debug("Found scooters in the searched area.", slog.Int("scooters", len(scooters)))
+
 	return scooters, err
 }
 
-func (rs *rentalService) Rent(ctx context.Context, info *model.RentInfo) error {
+func (rs *rentalService) GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*model.Scooter, error) {
+	scooter, err := rs.scooterRepository.GetScooter(ctx, scooterUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter: %w", err)
+	}
+
+	return scooter, nil
+}
+
+// Rent marks the scooter as rented and records the rental of the user, recording its start in the outbox along with
+// the change of the availability. When the rental can't be recorded, the scooter is made available again and its end
+// is recorded, so the consumers of the start don't keep the rental going.
+func (rs *rentalService) Rent(ctx context.Context, userUUID uuid.UUID, info *model.RentInfo) (*model.Rental, error) {
 	scooterUUID, err := uuid.Parse(info.ScooterUUID)
 	if err != nil {
-		return fmt.Errorf("parsing scooter's uuid: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidScooterUUID, err)
+	}
+
+	rental := model.NewRental(rs.newUUID(), userUUID, scooterUUID, info, rs.now().UTC())
+
+	err = rs.scooterRepository.UpdateScooterAvailability(
+		ctx,
+		scooterUUID,
+		false,
+		rs.newEvent(eventmodel.TypeRentalStarted, rental),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("updating scooter availability: %w", err)
+	}
+
+	ctxLogger := logging.FromContext(ctx).With(slog.String("scooter_id", scooterUUID.String()))
+
+	ctxLogger.Debug("Marked scooter as rented.")
+
+	if err = rs.rentalRepository.CreateRental(ctx, rental); err != nil {
+		revertErr := rs.scooterRepository.UpdateScooterAvailability(
+			ctx,
+			scooterUUID,
+			true,
+			rs.newEvent(eventmodel.TypeRentalEnded, rental),
+		)
+		if revertErr != nil {
+			ctxLogger.Error("Failed to make scooter available after failed rental.", slog.Any("err", revertErr))
+		}
+
+		return nil, fmt.Errorf("creating rental: %w", err)
+	}
+
+	ctxLogger.Debug("Recorded rental.", slog.String("rental_id", rental.UUID.String()))
+
+	return rental, nil
+}
+
+// Free marks the scooter as available and ends its active rental, which is nil if the scooter was rented before the
+// rentals were recorded. The end of the rental is recorded in the outbox along with the change of the availability.
+func (rs *rentalService) Free(ctx context.Context, scooterUUID uuid.UUID) (*model.Rental, error) {
+	ctxLogger := logging.FromContext(ctx).With(slog.String("scooter_id", scooterUUID.String()))
+
+	// the rental is read first, so its end is recorded only by the call that makes the scooter available
+	rental, err := rs.rentalRepository.GetActiveRental(ctx, scooterUUID)
+	if errors.Is(err, service.ErrRentalNotFound) {
+		if err = rs.scooterRepository.UpdateScooterAvailability(ctx, scooterUUID, true); err != nil {
+			return nil, fmt.Errorf("updating scooter availability: %w", err)
+		}
+
+		ctxLogger.Warn("Freed scooter without recorded rental.")
+
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting scooter's rental: %w", err)
+	}
+
+	if err = rs.makeAvailable(ctx, rental); err != nil {
+		return nil, err
 	}
 
-	err = rs.scooterRepository.UpdateScooterAvailability(ctx, scooterUUID, false)
+	ctxLogger.Debug("Marked scooter as available.")
+
+	if err = rs.endRental(ctx, rental); err != nil {
+		return nil, err
+	}
+
+	return rental, nil
+}
+
+// GetRental returns the rental of the user, hiding the rentals of the others behind ErrRentalNotFound.
+func (rs *rentalService) GetRental(ctx context.Context, userUUID, rentalUUID uuid.UUID) (*model.Rental, error) {
+	rental, err := rs.rentalRepository.GetRental(ctx, rentalUUID)
 	if err != nil {
+		return nil, fmt.Errorf("getting rental: %w", err)
+	}
+
+	if rental.UserUUID != userUUID {
+		return nil, fmt.Errorf("getting rental of another user: %w", service.ErrRentalNotFound)
+	}
+
+	return rental, nil
+}
+
+func (rs *rentalService) GetUserRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.Rental, error) {
+	rentals, err := rs.rentalRepository.GetUserRentals(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user's rentals: %w", err)
+	}
+
+	return rentals, nil
+}
+
+// EndRental ends the active rental of the user and makes its scooter available.
+func (rs *rentalService) EndRental(ctx context.Context, userUUID, rentalUUID uuid.UUID) (*model.Rental, error) {
+	rental, err := rs.GetRental(ctx, userUUID, rentalUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rental.Active() {
+		return nil, ErrRentalEnded
+	}
+
+	if err = rs.makeAvailable(ctx, rental); err != nil {
+		return nil, err
+	}
+
+	if err = rs.endRental(ctx, rental); err != nil {
+		return nil, err
+	}
+
+	return rental, nil
+}
+
+// GetActiveRentals returns the rides of the user in progress, the latest first, along with the tracked positions of
+// their scooters and the fares so far.
+func (rs *rentalService) GetActiveRentals(ctx context.Context, userUUID uuid.UUID) ([]*model.ActiveRental, error) {
+	rentals, err := rs.rentalRepository.GetUserActiveRentals(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user's active rentals: %w", err)
+	}
+
+	now := rs.now().UTC()
+
+	activeRentals := make([]*model.ActiveRental, len(rentals))
+
+	for i, rental := range rentals {
+		scooter, err := rs.scooterRepository.GetScooter(ctx, rental.ScooterUUID)
+		if err != nil {
+			return nil, fmt.Errorf("getting position of rented scooter: %w", err)
+		}
+
+		elapsed := now.Sub(rental.StartedAt)
+
+		activeRentals[i] = &model.ActiveRental{
+			Rental:    rental,
+			Longitude: scooter.Longitude,
+			Latitude:  scooter.Latitude,
+			Elapsed:   elapsed,
+			Fare:      rs.tariff.Fare(elapsed),
+			Currency:  rs.tariff.Currency,
+		}
+	}
+
+	return activeRentals, nil
+}
+
+// makeAvailable ends the rental and marks its scooter as available, recording the end in the outbox. The end is
+// cleared again when the scooter fails to be made available.
+func (rs *rentalService) makeAvailable(ctx context.Context, rental *model.Rental) error {
+	endedAt := rs.now().UTC()
+	rental.EndedAt = &endedAt
+
+	err := rs.scooterRepository.UpdateScooterAvailability(
+		ctx,
+		rental.ScooterUUID,
+		true,
+		rs.newEvent(eventmodel.TypeRentalEnded, rental),
+	)
+	if err != nil {
+		rental.EndedAt = nil
+
 		return fmt.Errorf("updating scooter availability: %w", err)
 	}
 
 	return nil
 }
 
-func (rs *rentalService) Free(ctx context.Context, scooterUUID uuid.UUID) error {
-	if err := rs.scooterRepository.UpdateScooterAvailability(ctx, scooterUUID, true); err != nil {
-		return fmt.Errorf("updating scooter availability: %w", err)
+func (rs *rentalService) endRental(ctx context.Context, rental *model.Rental) error {
+	if err := rs.rentalRepository.EndRental(ctx, rental); err != nil {
+		return fmt.Errorf("ending rental: %w", err)
 	}
 
+	logging.FromContext(ctx).Debug(
+		"Ended rental.",
+		slog.String("rental_id", rental.UUID.String()),
+		slog.String("scooter_id", rental.ScooterUUID.String()),
+	)
+
 	return nil
 }
+
+// newEvent returns the event of the start or the end of the rental, the start carrying the position it started at.
+func (rs *rentalService) newEvent(eventType eventmodel.Type, rental *model.Rental) *eventmodel.Event {
+	event := &eventmodel.Event{
+		UUID:        rs.newUUID(),
+		Type:        eventType,
+		OccurredAt:  rental.StartedAt,
+		RentalUUID:  rental.UUID,
+		UserUUID:    rental.UserUUID,
+		ScooterUUID: rental.ScooterUUID,
+		City:        rental.City,
+	}
+
+	switch {
+	case eventType == eventmodel.TypeRentalStarted:
+		event.Longitude, event.Latitude = rental.StartLongitude, rental.StartLatitude
+	case rental.EndedAt != nil:
+		event.OccurredAt = *rental.EndedAt
+	default:
+		// the rental that failed to be recorded ends right away
+		event.OccurredAt = rs.now().UTC()
+	}
+
+	return event
+}
@@ -4,85 +4,135 @@ package rental
 
 import (
 	"context"
-	"log"
-	"os"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	repositorymock "github.com/PatrykPasterny/scooter-rental/internal/service/mock"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 )
 
 const (
-	testCity = "Montreal"
+	testCity      = "Montreal"
+	testLongitude = 70.0
+	testLatitude  = 60.0
+
+	testUnlockFee = 100
+	testPerMinute = 35
 )
 
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
 func TestRent(t *testing.T) {
 	ctx := context.Background()
 
 	firstScooterUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	userUUID := uuid.New()
+	rentalUUID := uuid.New()
+
 	rentInfo := model.NewRentInfo(
 		firstScooterUUID.String(),
 		testCity,
+		testLongitude,
+		testLatitude,
 	)
 
 	wrongUUIDScooter := model.NewRentInfo(
 		"dd-dd-dd",
 		testCity,
+		testLongitude,
+		testLatitude,
 	)
 
+	expectedRental := model.NewRental(rentalUUID, userUUID, firstScooterUUID, rentInfo, testNow)
+
+	// the rental and its events get the same UUID from the stub
+	startedEvent := &eventmodel.Event{
+		UUID:        rentalUUID,
+		Type:        eventmodel.TypeRentalStarted,
+		OccurredAt:  testNow,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: firstScooterUUID,
+		City:        testCity,
+		Longitude:   testLongitude,
+		Latitude:    testLatitude,
+	}
+	revertedEvent := &eventmodel.Event{
+		UUID:        rentalUUID,
+		Type:        eventmodel.TypeRentalEnded,
+		OccurredAt:  testNow,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: firstScooterUUID,
+		City:        testCity,
+	}
+
 	tests := map[string]struct {
-		rentInfo                *model.RentInfo
-		mockRedisServiceHandler func(mock *repositorymock.MockScooterRepository)
-		wantErr                 bool
+		rentInfo    *model.RentInfo
+		mockHandler func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository)
+		want        *model.Rental
+		wantErr     bool
 	}{
 		"successfully rent scooter": {
 			rentInfo: rentInfo,
-			mockRedisServiceHandler: func(mock *repositorymock.MockScooterRepository) {
-				scooterUUID, innerErr := uuid.Parse(rentInfo.ScooterUUID)
-				require.NoError(t, innerErr)
-
-				mock.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, false).Return(nil).Times(1)
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, false, startedEvent).
+					Return(nil).Times(1)
+				rentals.EXPECT().CreateRental(ctx, expectedRental).Return(nil).Times(1)
 			},
+			want:    expectedRental,
 			wantErr: false,
 		},
 		"rent scooter failing because chosen scooter has incorrect ScooterUUID": {
-			rentInfo:                wrongUUIDScooter,
-			mockRedisServiceHandler: nil,
-			wantErr:                 true,
+			rentInfo:    wrongUUIDScooter,
+			mockHandler: nil,
+			wantErr:     true,
 		},
 		"rent scooter failing because redis service threw an error": {
 			rentInfo: rentInfo,
-			mockRedisServiceHandler: func(mock *repositorymock.MockScooterRepository) {
-				scooterUUID, innerErr := uuid.Parse(rentInfo.ScooterUUID)
-				require.NoError(t, innerErr)
-
-				mock.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, false).Return(redis.ErrClosed)
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, _ *repositorymock.MockRentalRepository) {
+				scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, false, startedEvent).
+					Return(redis.ErrClosed)
+			},
+			wantErr: true,
+		},
+		"rent scooter failing and making it available again with the end recorded because rental wasn't recorded": {
+			rentInfo: rentInfo,
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				gomock.InOrder(
+					scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, false, startedEvent).Return(nil),
+					rentals.EXPECT().CreateRental(ctx, expectedRental).Return(redis.ErrClosed),
+					scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, true, revertedEvent).Return(nil),
+				)
 			},
 			wantErr: true,
 		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			controller := gomock.NewController(t)
-			defer controller.Finish()
-
-			mockRedisService := repositorymock.NewMockScooterRepository(controller)
+			rs, mockScooterRepository, mockRentalRepository := newTestRentalService(t)
+			rs.newUUID = func() uuid.UUID { return rentalUUID }
 
-			if tt.mockRedisServiceHandler != nil {
-				tt.mockRedisServiceHandler(mockRedisService)
+			if tt.mockHandler != nil {
+				tt.mockHandler(mockScooterRepository, mockRentalRepository)
 			}
 
-			rs := NewRentalService(mockRedisService)
-			if err = rs.Rent(ctx, tt.rentInfo); (err != nil) != tt.wantErr {
+			got, err := rs.Rent(ctx, userUUID, tt.rentInfo)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("Rent() error = %v, wantErr %v", err, tt.wantErr)
 			}
+
+			require.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -90,48 +140,272 @@ func TestRent(t *testing.T) {
 func TestFree(t *testing.T) {
 	ctx := context.Background()
 
-	logger := log.New(os.Stdout, "TEST ", log.LstdFlags)
-
 	firstScooterUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	startedAt := testNow.Add(-time.Hour)
+	endedAt := testNow
+
+	activeRental := func() *model.Rental {
+		return &model.Rental{UUID: uuid.New(), ScooterUUID: firstScooterUUID, City: testCity, StartedAt: startedAt}
+	}
+
 	tests := map[string]struct {
-		logger                  *log.Logger
-		mockRedisServiceHandler func(mock *repositorymock.MockScooterRepository)
-		wantErr                 bool
+		mockHandler func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository)
+		wantEnded   bool
+		wantErr     bool
 	}{
-		"successfully freed scooter": {
-			logger: logger,
-			mockRedisServiceHandler: func(mock *repositorymock.MockScooterRepository) {
-				mock.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, true).
+		"successfully freed scooter with the end of its rental recorded": {
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				gomock.InOrder(
+					rentals.EXPECT().GetActiveRental(ctx, firstScooterUUID).Return(activeRental(), nil),
+					scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, true, gomock.Any()).Return(nil),
+					rentals.EXPECT().EndRental(ctx, gomock.Any()).Return(nil),
+				)
+			},
+			wantEnded: true,
+			wantErr:   false,
+		},
+		"successfully freed scooter rented without recorded rental": {
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetActiveRental(ctx, firstScooterUUID).Return(nil, service.ErrRentalNotFound).Times(1)
+				scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, true).
 					Return(nil).Times(1)
 			},
 			wantErr: false,
 		},
 		"freeing scooter failed because redis service threw an error when updating availability": {
-			logger: logger,
-			mockRedisServiceHandler: func(mock *repositorymock.MockScooterRepository) {
-				mock.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, true).
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetActiveRental(ctx, firstScooterUUID).Return(activeRental(), nil).Times(1)
+				scooters.EXPECT().UpdateScooterAvailability(ctx, firstScooterUUID, true, gomock.Any()).
 					Return(redis.ErrClosed).Times(1)
 			},
 			wantErr: true,
 		},
+		"freeing scooter failed because redis service threw an error when getting its rental": {
+			mockHandler: func(_ *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetActiveRental(ctx, firstScooterUUID).Return(nil, redis.ErrClosed).Times(1)
+			},
+			wantErr: true,
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			controller := gomock.NewController(t)
-			defer controller.Finish()
+			rs, mockScooterRepository, mockRentalRepository := newTestRentalService(t)
 
-			mockRedisService := repositorymock.NewMockScooterRepository(controller)
+			tt.mockHandler(mockScooterRepository, mockRentalRepository)
 
-			if tt.mockRedisServiceHandler != nil {
-				tt.mockRedisServiceHandler(mockRedisService)
+			got, err := rs.Free(ctx, firstScooterUUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Free() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			rs := NewRentalService(mockRedisService)
-			if err = rs.Free(ctx, firstScooterUUID); (err != nil) != tt.wantErr {
-				t.Errorf("Free() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantEnded {
+				require.Equal(t, &endedAt, got.EndedAt)
+			} else {
+				require.Nil(t, got)
 			}
 		})
 	}
 }
+
+func TestEndRental(t *testing.T) {
+	ctx := context.Background()
+
+	userUUID := uuid.New()
+	rentalUUID := uuid.New()
+	scooterUUID := uuid.New()
+
+	endedAt := testNow
+	eventUUID := uuid.New()
+
+	endedEvent := &eventmodel.Event{
+		UUID:        eventUUID,
+		Type:        eventmodel.TypeRentalEnded,
+		OccurredAt:  endedAt,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: scooterUUID,
+		City:        testCity,
+	}
+
+	rental := func(owner uuid.UUID, ended bool) *model.Rental {
+		r := &model.Rental{
+			UUID:        rentalUUID,
+			UserUUID:    owner,
+			ScooterUUID: scooterUUID,
+			City:        testCity,
+			StartedAt:   testNow.Add(-time.Hour),
+		}
+
+		if ended {
+			r.EndedAt = &endedAt
+		}
+
+		return r
+	}
+
+	tests := map[string]struct {
+		mockHandler func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository)
+		wantErr     error
+	}{
+		"successfully ended rental": {
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetRental(ctx, rentalUUID).Return(rental(userUUID, false), nil).Times(1)
+				scooters.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, true, endedEvent).Return(nil).Times(1)
+				rentals.EXPECT().EndRental(ctx, rental(userUUID, true)).Return(nil).Times(1)
+			},
+		},
+		"failed ending rental because it belongs to another user": {
+			mockHandler: func(_ *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetRental(ctx, rentalUUID).Return(rental(uuid.New(), false), nil).Times(1)
+			},
+			wantErr: service.ErrRentalNotFound,
+		},
+		"failed ending rental because it has already ended": {
+			mockHandler: func(_ *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetRental(ctx, rentalUUID).Return(rental(userUUID, true), nil).Times(1)
+			},
+			wantErr: ErrRentalEnded,
+		},
+		"failed ending rental because redis service threw an error when updating availability": {
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetRental(ctx, rentalUUID).Return(rental(userUUID, false), nil).Times(1)
+				scooters.EXPECT().UpdateScooterAvailability(ctx, scooterUUID, true, endedEvent).
+					Return(redis.ErrClosed).Times(1)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rs, mockScooterRepository, mockRentalRepository := newTestRentalService(t)
+
+			rs.newUUID = func() uuid.UUID { return eventUUID }
+
+			tt.mockHandler(mockScooterRepository, mockRentalRepository)
+
+			got, err := rs.EndRental(ctx, userUUID, rentalUUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("EndRental() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil {
+				require.Equal(t, rental(userUUID, true), got)
+			}
+		})
+	}
+}
+
+func TestGetActiveRentals(t *testing.T) {
+	ctx := context.Background()
+
+	userUUID := uuid.New()
+	scooterUUID := uuid.New()
+
+	rental := func(startedAt time.Time) *model.Rental {
+		return &model.Rental{
+			UUID:        uuid.New(),
+			UserUUID:    userUUID,
+			ScooterUUID: scooterUUID,
+			City:        testCity,
+			StartedAt:   startedAt,
+		}
+	}
+
+	justStarted := rental(testNow)
+	startedMinuteAgo := rental(testNow.Add(-time.Minute))
+	startedMinuteAndHalfAgo := rental(testNow.Add(-90 * time.Second))
+
+	trackedScooter := model.NewScooter(scooterUUID.String(), testCity, testLongitude+1, testLatitude+1, false)
+
+	tests := map[string]struct {
+		mockHandler func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository)
+		want        []*model.ActiveRental
+		wantErr     bool
+	}{
+		"successfully got active rentals priced by started minutes": {
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetUserActiveRentals(ctx, userUUID).
+					Return([]*model.Rental{justStarted, startedMinuteAgo, startedMinuteAndHalfAgo}, nil).Times(1)
+				scooters.EXPECT().GetScooter(ctx, scooterUUID).Return(trackedScooter, nil).Times(3)
+			},
+			want: []*model.ActiveRental{
+				{
+					Rental:    justStarted,
+					Longitude: testLongitude + 1,
+					Latitude:  testLatitude + 1,
+					Elapsed:   0,
+					Fare:      testUnlockFee,
+					Currency:  "CAD",
+				},
+				{
+					Rental:    startedMinuteAgo,
+					Longitude: testLongitude + 1,
+					Latitude:  testLatitude + 1,
+					Elapsed:   time.Minute,
+					Fare:      testUnlockFee + testPerMinute,
+					Currency:  "CAD",
+				},
+				{
+					Rental:    startedMinuteAndHalfAgo,
+					Longitude: testLongitude + 1,
+					Latitude:  testLatitude + 1,
+					Elapsed:   90 * time.Second,
+					Fare:      testUnlockFee + 2*testPerMinute,
+					Currency:  "CAD",
+				},
+			},
+			wantErr: false,
+		},
+		"successfully got no active rentals": {
+			mockHandler: func(_ *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetUserActiveRentals(ctx, userUUID).Return(nil, nil).Times(1)
+			},
+			want:    []*model.ActiveRental{},
+			wantErr: false,
+		},
+		"failed getting active rentals because redis service threw an error when getting scooter": {
+			mockHandler: func(scooters *repositorymock.MockScooterRepository, rentals *repositorymock.MockRentalRepository) {
+				rentals.EXPECT().GetUserActiveRentals(ctx, userUUID).Return([]*model.Rental{justStarted}, nil).Times(1)
+				scooters.EXPECT().GetScooter(ctx, scooterUUID).Return(nil, redis.ErrClosed).Times(1)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rs, mockScooterRepository, mockRentalRepository := newTestRentalService(t)
+
+			tt.mockHandler(mockScooterRepository, mockRentalRepository)
+
+			got, err := rs.GetActiveRentals(ctx, userUUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetActiveRentals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func newTestRentalService(t *testing.T) (
+	*rentalService,
+	*repositorymock.MockScooterRepository,
+	*repositorymock.MockRentalRepository,
+) {
+	controller := gomock.NewController(t)
+
+	mockScooterRepository := repositorymock.NewMockScooterRepository(controller)
+	mockRentalRepository := repositorymock.NewMockRentalRepository(controller)
+
+	rs := NewRentalService(
+		mockScooterRepository,
+		mockRentalRepository,
+		model.NewTariff(testUnlockFee, testPerMinute, "CAD"),
+	)
+	rs.now = func() time.Time { return testNow }
+
+	return rs, mockScooterRepository, mockRentalRepository
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+var ErrRentalNotFound = errors.New("rental with given UUID was not found")
+
+//go:generate mockgen -source=rental_repository.go -destination=mock/rental_repository_mock.go -package=mock
+type RentalRepository interface {
+	// CreateRental stores the rental and marks it as the active rental of its scooter and of its user.
+	CreateRental(ctx context.Context, rental *rentalmodel.Rental) error
+	// GetRental returns ErrRentalNotFound when there is no rental with the UUID.
+	GetRental(ctx context.Context, rentalUUID uuid.UUID) (*rentalmodel.Rental, error)
+	// GetActiveRental returns the active rental of the scooter or ErrRentalNotFound when the scooter isn't rented.
+	GetActiveRental(ctx context.Context, scooterUUID uuid.UUID) (*rentalmodel.Rental, error)
+	// GetUserRentals returns the rentals of the user, the latest first.
+	GetUserRentals(ctx context.Context, userUUID uuid.UUID) ([]*rentalmodel.Rental, error)
+	// GetUserActiveRentals returns the active rentals of the user, the latest first.
+	GetUserActiveRentals(ctx context.Context, userUUID uuid.UUID) ([]*rentalmodel.Rental, error)
+	// EndRental atomically stores the ended rental and clears it from its user and, unless the scooter was already rented
+	// again, from its scooter. It returns ErrRentalNotFound when there is no rental with the UUID.
+	EndRental(ctx context.Context, rental *rentalmodel.Rental) error
+}
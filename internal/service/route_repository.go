@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+//go:generate mockgen -source=route_repository.go -destination=mock/route_repository_mock.go -package=mock
+type RouteRepository interface {
+	// AppendRoutePoint records the point as the latest one of the route of the rental.
+	AppendRoutePoint(ctx context.Context, rentalUUID uuid.UUID, point *trackermodel.RoutePoint) error
+	// GetRoute returns the points of the route of the rental in the order they were recorded, none when the ride
+	// hasn't moved yet.
+	GetRoute(ctx context.Context, rentalUUID uuid.UUID) ([]*trackermodel.RoutePoint, error)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 )
@@ -12,6 +13,15 @@ import (
 //go:generate mockgen -source=scooter_repository.go -destination=mock/scooter_repository_mock.go -package=mock
 type ScooterRepository interface {
 	GetScooters(ctx context.Context, geoRectangle *rentalmodel.GeoRectangle) ([]*rentalmodel.Scooter, error)
+	// GetScooter finds the scooter with the UUID in whichever city it is.
+	GetScooter(ctx context.Context, scooterUUID uuid.UUID) (*rentalmodel.Scooter, error)
 	UpdateScooterLocation(ctx context.Context, scooter *trackermodel.Scooter) error
-	UpdateScooterAvailability(ctx context.Context, scooterUUID uuid.UUID, availability bool) error
+	// UpdateScooterAvailability records the events in the outbox in the same transaction as the change of the
+	// availability, so they are published if and only if the change is made.
+	UpdateScooterAvailability(
+		ctx context.Context,
+		scooterUUID uuid.UUID,
+		availability bool,
+		events ...*eventmodel.Event,
+	) error
 }
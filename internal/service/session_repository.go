@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+//go:generate mockgen -source=session_repository.go -destination=mock/session_repository_mock.go -package=mock
+type TrackingSessionRepository interface {
+	// SaveSession stores the session of the scooter, replacing the one stored before.
+	SaveSession(ctx context.Context, session *trackermodel.Session) error
+	// ClaimSession makes the owner of the sessions the one tracking the rental. It returns false when the rental is
+	// tracked by another owner, or its tracking by this one has already ended.
+	ClaimSession(ctx context.Context, rentalUUID uuid.UUID) (bool, error)
+	// DeleteSession removes the session of the scooter, provided it tracks the rental, so the session of the next
+	// rental of the scooter is kept. The claim of the rental is released after a while, so its start delivered again
+	// in the meantime is not tracked.
+	DeleteSession(ctx context.Context, scooterUUID, rentalUUID uuid.UUID) error
+	// GetSessions returns the stored sessions, none when no scooter is tracked.
+	GetSessions(ctx context.Context) ([]*trackermodel.Session, error)
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+var ErrTelemetryNotFound = errors.New("telemetry of the scooter with given UUID was not found")
+
+//go:generate mockgen -source=telemetry_repository.go -destination=mock/telemetry_repository_mock.go -package=mock
+type TelemetryRepository interface {
+	// GetTelemetry returns the latest report of the scooter or ErrTelemetryNotFound when it has not reported yet.
+	GetTelemetry(ctx context.Context, scooterUUID uuid.UUID) (*trackermodel.Telemetry, error)
+	// SaveTelemetry stores the report as the latest one of the scooter, unless a later one is already stored.
+	SaveTelemetry(ctx context.Context, scooterUUID uuid.UUID, telemetry *trackermodel.Telemetry) error
+}
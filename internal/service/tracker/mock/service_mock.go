@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	model "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
@@ -35,30 +36,47 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
-// StopTracking mocks base method.
-func (m *MockService) StopTracking(userUUID, scooterUUID uuid.UUID) error {
+// GetRoute mocks base method.
+func (m *MockService) GetRoute(ctx context.Context, rentalUUID uuid.UUID) ([]*model.RoutePoint, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StopTracking", userUUID, scooterUUID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetRoute", ctx, rentalUUID)
+	ret0, _ := ret[0].([]*model.RoutePoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// StopTracking indicates an expected call of StopTracking.
-func (mr *MockServiceMockRecorder) StopTracking(userUUID, scooterUUID interface{}) *gomock.Call {
+// GetRoute indicates an expected call of GetRoute.
+func (mr *MockServiceMockRecorder) GetRoute(ctx, rentalUUID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopTracking", reflect.TypeOf((*MockService)(nil).StopTracking), userUUID, scooterUUID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoute", reflect.TypeOf((*MockService)(nil).GetRoute), ctx, rentalUUID)
 }
 
-// Track mocks base method.
-func (m *MockService) Track(userUUID uuid.UUID, scooter *model.Scooter) error {
+// IngestTelemetry mocks base method.
+func (m *MockService) IngestTelemetry(ctx context.Context, scooterUUID uuid.UUID, readings []*model.Telemetry) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Track", userUUID, scooter)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "IngestTelemetry", ctx, scooterUUID, readings)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Track indicates an expected call of Track.
-func (mr *MockServiceMockRecorder) Track(userUUID, scooter interface{}) *gomock.Call {
+// IngestTelemetry indicates an expected call of IngestTelemetry.
+func (mr *MockServiceMockRecorder) IngestTelemetry(ctx, scooterUUID, readings interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Track", reflect.TypeOf((*MockService)(nil).Track), userUUID, scooter)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestTelemetry", reflect.TypeOf((*MockService)(nil).IngestTelemetry), ctx, scooterUUID, readings)
+}
+
+// Subscribe mocks base method.
+func (m *MockService) Subscribe(scooterUUID uuid.UUID) (<-chan model.Event, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", scooterUUID)
+	ret0, _ := ret[0].(<-chan model.Event)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockServiceMockRecorder) Subscribe(scooterUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockService)(nil).Subscribe), scooterUUID)
 }
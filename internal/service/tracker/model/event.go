@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	// EventPosition reports the new position of the tracked scooter.
+	EventPosition EventType = "position"
+	// EventEnded is the last event of the tracking, sent when the scooter is freed.
+	EventEnded EventType = "ended"
+)
+
+// Event is the update of the tracked scooter sent to its subscribers. The distance is the one travelled since
+// the tracking started, in meters.
+type Event struct {
+	Type                EventType
+	ScooterUUID         uuid.UUID
+	Longitude, Latitude float64
+	Distance            float64
+	At                  time.Time
+}
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// RoutePoint is a position of the rented scooter recorded along its ride.
+type RoutePoint struct {
+	Longitude, Latitude float64
+	At                  time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is the tracking of the ride stored to be resumed after the restart of the tracker, with the last position
+// the scooter was tracked at.
+type Session struct {
+	ScooterUUID         uuid.UUID
+	UserUUID            uuid.UUID
+	RentalUUID          uuid.UUID
+	City                string
+	Longitude, Latitude float64
+	StartedAt           time.Time
+}
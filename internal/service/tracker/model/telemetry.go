@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Telemetry is a single report of the scooter: where it was at the time, how fast it went in kilometers per hour,
+// the charge left in its battery in percent and whether it was locked.
+type Telemetry struct {
+	Longitude, Latitude float64
+	At                  time.Time
+	Speed               float64
+	Battery             int
+	Locked              bool
+}
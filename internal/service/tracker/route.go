@@ -0,0 +1,75 @@
+package tracker
+
+import (
+	"math"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+// simplifyRoute drops the points of the route lying closer than the tolerance in meters to the line between the
+// points kept around them (the Douglas–Peucker algorithm). The first and the last point are always kept, and the
+// route is returned as it is when the tolerance is not positive.
+func simplifyRoute(route []*model.RoutePoint, tolerance float64) []*model.RoutePoint {
+	if tolerance <= 0 || len(route) < 3 {
+		return route
+	}
+
+	keep := make([]bool, len(route))
+	keep[0], keep[len(route)-1] = true, true
+
+	// the sections are split on a stack instead of recursively, so a long ride doesn't grow the call stack
+	sections := [][2]int{{0, len(route) - 1}}
+
+	for len(sections) > 0 {
+		first, last := sections[len(sections)-1][0], sections[len(sections)-1][1]
+		sections = sections[:len(sections)-1]
+
+		farthest, farthestDistance := 0, 0.0
+
+		for i := first + 1; i < last; i++ {
+			if d := distanceToSegment(route[i], route[first], route[last]); d > farthestDistance {
+				farthest, farthestDistance = i, d
+			}
+		}
+
+		if farthestDistance <= tolerance {
+			continue
+		}
+
+		keep[farthest] = true
+
+		sections = append(sections, [2]int{first, farthest}, [2]int{farthest, last})
+	}
+
+	simplified := make([]*model.RoutePoint, 0, len(route))
+
+	for i, point := range route {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+
+	return simplified
+}
+
+// distanceToSegment returns the distance in meters between the point and the segment, on the plane the earth is
+// projected to around the start of the segment, which is precise enough for the lengths of the rides.
+func distanceToSegment(point, start, end *model.RoutePoint) float64 {
+	metersPerDegree := earthRadiusInMeters * math.Pi / 180
+	metersPerLongitudeDegree := metersPerDegree * math.Cos(start.Latitude*math.Pi/180)
+
+	px := (point.Longitude - start.Longitude) * metersPerLongitudeDegree
+	py := (point.Latitude - start.Latitude) * metersPerDegree
+	ex := (end.Longitude - start.Longitude) * metersPerLongitudeDegree
+	ey := (end.Latitude - start.Latitude) * metersPerDegree
+
+	segmentLength := ex*ex + ey*ey
+	if segmentLength == 0 {
+		return math.Hypot(px, py)
+	}
+
+	// the projection of the point is clamped to the segment, so the rides going back and forth are not flattened
+	along := math.Max(0, math.Min(1, (px*ex+py*ey)/segmentLength))
+
+	return math.Hypot(px-along*ex, py-along*ey)
+}
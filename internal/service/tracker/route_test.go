@@ -0,0 +1,88 @@
+//go:build unit
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestSimplifyRoute(t *testing.T) {
+	startedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	point := func(longitude, latitude float64, second int) *model.RoutePoint {
+		return &model.RoutePoint{
+			Longitude: longitude,
+			Latitude:  latitude,
+			At:        startedAt.Add(time.Duration(second) * time.Second),
+		}
+	}
+
+	// a ride going north for about 220 meters, then east for about 110 meters
+	north := []*model.RoutePoint{
+		point(70.0, 60.0, 0),
+		point(70.0, 60.001, 3),
+		point(70.0, 60.002, 6),
+	}
+	corner := append(north, point(70.001, 60.002, 9), point(70.002, 60.002, 12))
+
+	// a ride going north and coming back the same way, whose turn is on the line between its ends
+	backAndForth := []*model.RoutePoint{
+		point(70.0, 60.0, 0),
+		point(70.0, 60.001, 3),
+		point(70.0, 60.002, 6),
+		point(70.0, 60.001, 9),
+		point(70.0, 60.0, 12),
+	}
+
+	tests := map[string]struct {
+		route     []*model.RoutePoint
+		tolerance float64
+		want      []*model.RoutePoint
+	}{
+		"dropped points on the straight line": {
+			route:     north,
+			tolerance: 5,
+			want:      []*model.RoutePoint{north[0], north[2]},
+		},
+		"kept the turn of the ride": {
+			route:     corner,
+			tolerance: 5,
+			want:      []*model.RoutePoint{corner[0], corner[2], corner[4]},
+		},
+		"kept the point the ride turned back at": {
+			route:     backAndForth,
+			tolerance: 5,
+			want:      []*model.RoutePoint{backAndForth[0], backAndForth[2], backAndForth[4]},
+		},
+		"dropped the turn within the tolerance": {
+			route:     corner,
+			tolerance: 500,
+			want:      []*model.RoutePoint{corner[0], corner[4]},
+		},
+		"kept the route without the tolerance": {
+			route:     corner,
+			tolerance: 0,
+			want:      corner,
+		},
+		"kept the route of two points": {
+			route:     north[:2],
+			tolerance: 5,
+			want:      north[:2],
+		},
+		"kept the empty route": {
+			route:     []*model.RoutePoint{},
+			tolerance: 5,
+			want:      []*model.RoutePoint{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, simplifyRoute(tt.route, tt.tolerance))
+		})
+	}
+}
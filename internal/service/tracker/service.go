@@ -2,13 +2,20 @@ package tracker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"runtime/debug"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
 	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 )
 
@@ -21,130 +28,603 @@ const (
 	MovingTimeInSeconds = 3
 
 	oneSecondDecimal float64 = 0.000278
+
+	earthRadiusInMeters = 6371000
+
+	maxConsecutiveUpdateFailures = 5
+
+	// reportsBufferSize is how many reported positions wait for the tracking go routine before the newer are dropped.
+	reportsBufferSize = 16
+)
+
+// Mode tells where the positions of the rented scooters come from.
+type Mode string
+
+const (
+	// ModeSimulated moves the rented scooters by itself, the telemetry of the scooters is not accepted.
+	ModeSimulated Mode = "simulated"
+	// ModeDevice takes the positions from the telemetry the scooters report.
+	ModeDevice Mode = "device"
+)
+
+var (
+	ErrTrackerDegraded        = errors.New("tracker keeps failing to update scooters' locations")
+	ErrDeviceTrackingDisabled = errors.New("telemetry is not accepted while the rides are simulated")
+	ErrTelemetryOutOfOrder    = errors.New("telemetry readings are not in chronological order")
+	ErrTelemetryInFuture      = errors.New("telemetry reading is timestamped in the future")
+	ErrUnknownScooter         = errors.New("scooter is not tracked")
+	ErrAlreadyTracked         = errors.New("ride of the scooter is already tracked")
+	ErrTrackingPanicked       = errors.New("tracking go routine panicked")
 )
 
+// Service is the tracker seen by the transport layer. The tracking itself is started and stopped by the events of
+// the rentals handled by HandleEvent.
+//
 //go:generate mockgen -source=service.go -destination=mock/service_mock.go -package=mock
 type Service interface {
-	Track(userUUID uuid.UUID, scooter *model.Scooter) error
-	StopTracking(userUUID, scooterUUID uuid.UUID) error
+	// Subscribe returns the channel of the events of the scooter, closed after the ride ends, and the function that
+	// cancels the subscription. The oldest events are dropped when the subscriber falls behind.
+	Subscribe(scooterUUID uuid.UUID) (<-chan model.Event, func())
+	// IngestTelemetry writes the positions reported by the scooter, the oldest reading first, and returns the number
+	// of the readings accepted. The readings already ingested are dropped as duplicates.
+	IngestTelemetry(ctx context.Context, scooterUUID uuid.UUID, readings []*model.Telemetry) (int, error)
+	// GetRoute returns the points the scooter went through during the rental, the oldest first, simplified to keep
+	// the long rides compact.
+	GetRoute(ctx context.Context, rentalUUID uuid.UUID) ([]*model.RoutePoint, error)
 }
 
 type trackingService struct {
-	logger         *slog.Logger
 	service        service.ScooterRepository
-	rentedScooters map[uuid.UUID]chan uuid.UUID
-	errorsChan     map[uuid.UUID]chan error
+	rentals        service.RentalRepository
+	telemetry      service.TelemetryRepository
+	routes         service.RouteRepository
+	sessionStore   service.TrackingSessionRepository
+	events         service.EventPublisher
+	mode           Mode
+	maxClockSkew   time.Duration
+	routeTolerance float64
+	now            func() time.Time
+	sessions       *sessions
+	subscriptions  *subscriptions
+
+	consecutiveUpdateFailures atomic.Int64
 }
 
-func NewTrackingService(logger *slog.Logger, service service.ScooterRepository) *trackingService {
-	return &trackingService{
-		logger:         logger,
+// NewTrackingService creates the tracker working in the mode and resumes tracking of the rides stored in the session
+// store, which were tracked before the restart. The telemetry timestamped further ahead of the clock than the max
+// clock skew is rejected. The routes are simplified by dropping the points closer than the route tolerance in meters
+// to the line of the others.
+func NewTrackingService(
+	ctx context.Context,
+	service service.ScooterRepository,
+	rentals service.RentalRepository,
+	telemetry service.TelemetryRepository,
+	routes service.RouteRepository,
+	sessionStore service.TrackingSessionRepository,
+	events service.EventPublisher,
+	mode Mode,
+	maxClockSkew time.Duration,
+	routeTolerance float64,
+) (*trackingService, error) {
+	ts := &trackingService{
 		service:        service,
-		rentedScooters: make(map[uuid.UUID]chan uuid.UUID),
-		errorsChan:     make(map[uuid.UUID]chan error),
+		rentals:        rentals,
+		telemetry:      telemetry,
+		routes:         routes,
+		sessionStore:   sessionStore,
+		events:         events,
+		mode:           mode,
+		maxClockSkew:   maxClockSkew,
+		routeTolerance: routeTolerance,
+		now:            time.Now,
+		sessions:       newSessions(),
+		subscriptions:  newSubscriptions(),
 	}
+
+	if err := ts.resume(ctx); err != nil {
+		return nil, fmt.Errorf("resuming tracking sessions: %w", err)
+	}
+
+	return ts, nil
 }
 
 // Track simulates the startup of a tracker go routine running on a scooter that periodically updates its localisation
-// and also simulates its movement until the time the tracker go routine is stopped.
-func (ts *trackingService) Track(userUUID uuid.UUID, scooter *model.Scooter) error {
+// and also simulates its movement until the time the tracker go routine is stopped. In the device mode the go routine
+// does not move the scooter, but follows the positions the scooter reports instead. Every move is recorded in the route
+// of the rental, while its subscribers are told about it by its scooter.moved event. The go routine keeps logging with
+// the logger of the given context, but it is not stopped when the context is done. Tracking the rental already tracked
+// returns ErrAlreadyTracked, while the tracking of the previous rental of the scooter, whose end was missed, is
+// stopped. The session is stored, so the tracking is resumed after the restart of the tracker.
+func (ts *trackingService) Track(
+	ctx context.Context,
+	userUUID, rentalUUID uuid.UUID,
+	scooter *model.Scooter,
+) error {
 	scooterUUID, err := uuid.Parse(scooter.Name)
 	if err != nil {
 		return fmt.Errorf("parsing scooter's uuid: %w", err)
 	}
 
-	trackerLogger := ts.logger.With(
+	return ts.track(ctx, model.Session{
+		ScooterUUID: scooterUUID,
+		UserUUID:    userUUID,
+		RentalUUID:  rentalUUID,
+		City:        scooter.City,
+		Longitude:   scooter.Longitude,
+		Latitude:    scooter.Latitude,
+		StartedAt:   ts.now().UTC(),
+	})
+}
+
+// track starts the go routine of the session, which keeps its own copy of the session to store the positions of the
+// scooter in.
+func (ts *trackingService) track(ctx context.Context, stored model.Session) error {
+	scooterUUID, rentalUUID := stored.ScooterUUID, stored.RentalUUID
+
+	trackerLogger := logging.FromContext(ctx).With(
 		slog.String("scooter_id", scooterUUID.String()),
-		slog.String("user_id", userUUID.String()),
+		slog.String("user_id", stored.UserUUID.String()),
+		slog.String("rental_id", rentalUUID.String()),
 	)
 
-	currentScooterChan := make(chan uuid.UUID)
-	currentErrorChan := make(chan error)
+	// the reports are not received in the simulated mode, as they are never sent
+	var reports chan *model.RoutePoint
+	if ts.mode == ModeDevice {
+		reports = make(chan *model.RoutePoint, reportsBufferSize)
+	}
+
+	sessionCtx, cancel := context.WithCancel(logging.WithLogger(context.Background(), trackerLogger))
+
+	tracked := newSession(rentalUUID, cancel, reports)
+
+	replaced, err := ts.sessions.add(scooterUUID, tracked)
+	if err != nil {
+		cancel()
+
+		return fmt.Errorf("tracking scooter %s: %w", scooterUUID, err)
+	}
+
+	// the go routine of the previous ride is stopped first, so it doesn't move the scooter of the new one
+	if replaced != nil {
+		trackerLogger.Warn(
+			"Stopped tracking the previous rental of the scooter, its end was missed.",
+			slog.String("previous_rental_id", replaced.rentalUUID.String()),
+		)
 
-	if v, ok := ts.rentedScooters[scooterUUID]; ok && v != nil {
-		close(v)
+		if stopErr := replaced.stop(); stopErr != nil {
+			trackerLogger.Warn("Tracking of the previous rental met errors.", slog.Any("err", stopErr))
+		}
 	}
 
-	ts.rentedScooters[scooterUUID] = currentScooterChan
-	ts.errorsChan[scooterUUID] = currentErrorChan
+	// the session of the previous rental is replaced in the store only after its go routine stops storing it
+	ts.saveSession(sessionCtx, &stored)
 
 	trackerLogger.Info("Started tracking scooter")
 
-	go func(tLogger *slog.Logger) {
-		defer close(currentScooterChan)
+	go ts.run(sessionCtx, tracked, stored)
 
-		trackerContext, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	return nil
+}
 
-		rentalErrors := make(map[string]int)
-		for {
-			select {
-			case <-time.After(MovingTimeInSeconds * time.Second):
-				simulateScooterMove(scooter, MovingTimeInSeconds, north)
+// run is the go routine of the session, moving the scooter or following its reports until the session is cancelled.
+// A panic is recovered and kept as the failure of the session, so it is reported when the tracking is stopped.
+func (ts *trackingService) run(ctx context.Context, tracked *session, stored model.Session) {
+	tLogger := logging.FromContext(ctx)
 
-				tLogger.Info(
-					"Tracked scooter continues his journey.",
-					slog.Float64("longitude", scooter.Longitude),
-					slog.Float64("latitude", scooter.Latitude),
-				)
+	scooterUUID := stored.ScooterUUID
+	scooter := model.NewScooter(scooterUUID.String(), stored.City, stored.Longitude, stored.Latitude)
 
-				err = ts.service.UpdateScooterLocation(trackerContext, scooter)
-				if err != nil {
-					if _, ok := rentalErrors[err.Error()]; ok {
-						rentalErrors[err.Error()] += 1
-					}
+	rentalErrors := make(map[string]int)
 
-					rentalErrors[err.Error()] = 1
-				}
-			case <-currentScooterChan: // Signal to stop tracking
-				if len(rentalErrors) == 0 {
-					currentErrorChan <- nil
+	defer close(tracked.done)
 
-					return
-				}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tLogger.Error(
+				"Tracking go routine panicked.",
+				slog.Any("panic", recovered),
+				slog.String("stack", string(debug.Stack())),
+			)
 
-				routineErrors := fmt.Errorf(
-					"go routine assigned to userUUID - %s met several errors",
-					scooterUUID,
-				)
+			tracked.err = fmt.Errorf("%w: %v", ErrTrackingPanicked, recovered)
+
+			return
+		}
+
+		tracked.err = joinRentalErrors(scooterUUID, rentalErrors)
+	}()
+
+	// the route starts where the scooter was rented
+	ts.recordRoutePoint(ctx, tracked.rentalUUID, &model.RoutePoint{
+		Longitude: scooter.Longitude,
+		Latitude:  scooter.Latitude,
+		At:        ts.now().UTC(),
+	})
+
+	for {
+		var move <-chan time.Time
+		if ts.mode == ModeSimulated {
+			move = time.After(MovingTimeInSeconds * time.Second)
+		}
 
-				for key, value := range rentalErrors {
-					routineErrors = fmt.Errorf("%w: %s, %d times", routineErrors, key, value)
+		select {
+		case <-move:
+			simulateScooterMove(scooter, MovingTimeInSeconds, north)
+
+			tLogger.Info(
+				"Tracked scooter continues his journey.",
+				slog.Float64("longitude", scooter.Longitude),
+				slog.Float64("latitude", scooter.Latitude),
+			)
+
+			ts.recordRoutePoint(ctx, tracked.rentalUUID, &model.RoutePoint{
+				Longitude: scooter.Longitude,
+				Latitude:  scooter.Latitude,
+				At:        ts.now().UTC(),
+			})
+
+			stored.Longitude, stored.Latitude = scooter.Longitude, scooter.Latitude
+			ts.saveSession(ctx, &stored)
+
+			updateErr := ts.service.UpdateScooterLocation(ctx, scooter)
+			if updateErr != nil {
+				ts.consecutiveUpdateFailures.Add(1)
+
+				tLogger.Warn("Failed to update tracked scooter's location.", slog.Any("err", updateErr))
+
+				// an open circuit reports a different retry time on every call, so count it under one key
+				if errors.Is(updateErr, resilience.ErrCircuitOpen) {
+					updateErr = resilience.ErrCircuitOpen
 				}
 
-				currentErrorChan <- routineErrors
+				rentalErrors[updateErr.Error()]++
 
-				return
+				continue
 			}
+
+			ts.consecutiveUpdateFailures.Store(0)
+
+			movedEvent := newMovedEvent(scooterUUID, scooter, ts.now().UTC())
+			if publishErr := ts.events.Publish(ctx, movedEvent); publishErr != nil {
+				tLogger.Warn("Failed to publish tracked scooter's move.", slog.Any("err", publishErr))
+			}
+		case reported := <-tracked.reports:
+			scooter.Longitude, scooter.Latitude = reported.Longitude, reported.Latitude
+
+			ts.recordRoutePoint(ctx, tracked.rentalUUID, reported)
+
+			stored.Longitude, stored.Latitude = reported.Longitude, reported.Latitude
+			ts.saveSession(ctx, &stored)
+		case <-ctx.Done(): // Signal to stop tracking
+			return
+		}
+	}
+}
+
+// HandleEvent starts tracking the rented scooters and stops tracking the freed ones. Every instance handles every
+// event, so the rental is tracked only by the instance that claims it first, and its end stops the tracking there,
+// while the subscribers of the ride on every instance are told about its moves and its end. The events delivered
+// again are recognised by the rental being already tracked or not tracked at all, and are skipped, as are the starts
+// of the rentals ended in the meantime.
+func (ts *trackingService) HandleEvent(ctx context.Context, event *eventmodel.Event) error {
+	ctxLogger := logging.FromContext(ctx).With(slog.String("scooter_id", event.ScooterUUID.String()))
+
+	switch event.Type {
+	case eventmodel.TypeRentalStarted:
+		if !ts.rentalActive(ctx, event) {
+			ctxLogger.Debug("Rental has already ended, the event is skipped.")
+
+			return nil
+		}
+
+		ts.subscriptions.start(event.ScooterUUID, event.RentalUUID, event.Longitude, event.Latitude)
+
+		claimed, err := ts.sessionStore.ClaimSession(ctx, event.RentalUUID)
+		if err != nil {
+			return fmt.Errorf("claiming tracking of rented scooter: %w", err)
+		}
+
+		if !claimed {
+			ctxLogger.Debug("Rental is tracked by another instance, the event is skipped.")
+
+			return nil
 		}
-	}(trackerLogger)
+
+		scooter := model.NewScooter(event.ScooterUUID.String(), event.City, event.Longitude, event.Latitude)
+
+		err = ts.Track(ctx, event.UserUUID, event.RentalUUID, scooter)
+		if errors.Is(err, ErrAlreadyTracked) {
+			ctxLogger.Debug("Scooter is already tracked, the event is skipped.")
+
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("tracking rented scooter: %w", err)
+		}
+	case eventmodel.TypeRentalEnded:
+		ts.subscriptions.finish(event.ScooterUUID, event.RentalUUID, event.OccurredAt)
+
+		// the tracking is stopped even if it met errors on the way, so they are only reported
+		err := ts.stop(ctx, event.UserUUID, event.ScooterUUID, event.RentalUUID)
+		if errors.Is(err, ErrUnknownScooter) {
+			ctxLogger.Debug("Scooter is not tracked, the event is skipped.")
+
+			return nil
+		}
+
+		if err != nil {
+			ctxLogger.Warn("Tracking of the freed scooter met errors.", slog.Any("err", err))
+		}
+	case eventmodel.TypeScooterMoved:
+		ts.subscriptions.move(event.ScooterUUID, event.Longitude, event.Latitude, event.OccurredAt)
+	default:
+	}
 
 	return nil
 }
 
-// StopTracking stops the tracking go routine for a given scooterUUID (simulates the stopping process on the scooter
-// itself).
-func (ts *trackingService) StopTracking(userUUID, scooterUUID uuid.UUID) error {
-	defer close(ts.errorsChan[scooterUUID])
+// rentalActive tells whether the rental started by the event still goes on. The rental not recorded yet, as the
+// scooter is marked as rented first, is told by the availability of its scooter. The rental that can't be checked is
+// taken as active, so it is tracked and stopped by its end, if it is still to be delivered.
+func (ts *trackingService) rentalActive(ctx context.Context, event *eventmodel.Event) bool {
+	ctxLogger := logging.FromContext(ctx).With(slog.String("rental_id", event.RentalUUID.String()))
+
+	rental, err := ts.rentals.GetRental(ctx, event.RentalUUID)
+	if err == nil {
+		return rental.Active()
+	}
+
+	if !errors.Is(err, service.ErrRentalNotFound) {
+		ctxLogger.Warn("Failed to check the started rental.", slog.Any("err", err))
+
+		return true
+	}
+
+	scooter, err := ts.service.GetScooter(ctx, event.ScooterUUID)
+	if err != nil {
+		ctxLogger.Warn("Failed to check the scooter of the started rental.", slog.Any("err", err))
+
+		return true
+	}
+
+	return !scooter.Availability
+}
+
+func (ts *trackingService) Subscribe(scooterUUID uuid.UUID) (<-chan model.Event, func()) {
+	return ts.subscriptions.subscribe(scooterUUID)
+}
+
+// IngestTelemetry rejects the whole batch when its readings are not in chronological order or any of them is
+// timestamped ahead of the clock by more than the allowed skew. The readings timestamped at or before the latest one
+// ingested are dropped, so the batch sent again after a failure is not applied twice. The latest reading is stored
+// only after all the positions are written, so the batch failing half way is accepted again when sent again.
+func (ts *trackingService) IngestTelemetry(
+	ctx context.Context,
+	scooterUUID uuid.UUID,
+	readings []*model.Telemetry,
+) (int, error) {
+	if ts.mode != ModeDevice {
+		return 0, ErrDeviceTrackingDisabled
+	}
+
+	if err := validateTelemetry(readings, ts.now().Add(ts.maxClockSkew)); err != nil {
+		return 0, err
+	}
+
+	rentalScooter, err := ts.service.GetScooter(ctx, scooterUUID)
+	if err != nil {
+		return 0, fmt.Errorf("getting reporting scooter: %w", err)
+	}
+
+	latest, err := ts.telemetry.GetTelemetry(ctx, scooterUUID)
+	if err != nil && !errors.Is(err, service.ErrTelemetryNotFound) {
+		return 0, fmt.Errorf("getting scooter's latest telemetry: %w", err)
+	}
+
+	accepted := dropIngested(readings, latest)
+	if len(accepted) == 0 {
+		return 0, nil
+	}
+
+	for _, reading := range accepted {
+		scooter := model.NewScooter(scooterUUID.String(), rentalScooter.City, reading.Longitude, reading.Latitude)
+
+		if err = ts.service.UpdateScooterLocation(ctx, scooter); err != nil {
+			ts.consecutiveUpdateFailures.Add(1)
+
+			return 0, fmt.Errorf("updating reported scooter's location: %w", err)
+		}
+
+		ts.consecutiveUpdateFailures.Store(0)
+
+		ts.report(ctx, scooterUUID, &model.RoutePoint{
+			Longitude: reading.Longitude,
+			Latitude:  reading.Latitude,
+			At:        reading.At,
+		})
+
+		if publishErr := ts.events.Publish(ctx, newMovedEvent(scooterUUID, scooter, reading.At)); publishErr != nil {
+			logging.FromContext(ctx).Warn("Failed to publish reported scooter's move.", slog.Any("err", publishErr))
+		}
+	}
+
+	if err = ts.telemetry.SaveTelemetry(ctx, scooterUUID, accepted[len(accepted)-1]); err != nil {
+		return 0, fmt.Errorf("saving scooter's latest telemetry: %w", err)
+	}
+
+	return len(accepted), nil
+}
+
+// GetRoute simplifies the recorded route with the tolerance of the tracker.
+func (ts *trackingService) GetRoute(ctx context.Context, rentalUUID uuid.UUID) ([]*model.RoutePoint, error) {
+	route, err := ts.routes.GetRoute(ctx, rentalUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting rental's route: %w", err)
+	}
+
+	return simplifyRoute(route, ts.routeTolerance), nil
+}
+
+// stop unregisters the session of the scooter tracking the rental, waits for its go routine to finish and removes the
+// session from the store.
+func (ts *trackingService) stop(ctx context.Context, userUUID, scooterUUID, rentalUUID uuid.UUID) error {
+	stopped, ok := ts.sessions.remove(scooterUUID, rentalUUID)
+	if !ok {
+		return fmt.Errorf("stopping tracking of scooter %s: %w", scooterUUID, ErrUnknownScooter)
+	}
 
-	ts.logger.Info(
-		"Stopped tracking scooter.",
+	ctxLogger := logging.FromContext(ctx).With(
 		slog.String("scooter_id", scooterUUID.String()),
 		slog.String("user_id", userUUID.String()),
+		slog.String("rental_id", stopped.rentalUUID.String()),
 	)
 
-	scooterToFree := ts.rentedScooters[scooterUUID]
-	scooterToFree <- scooterUUID
-	ts.rentedScooters[scooterUUID] = nil
+	ctxLogger.Info("Stopped tracking scooter.")
+
+	err := stopped.stop()
+
+	// the session left in the store is dropped when resumed, as its scooter is free by then
+	if deleteErr := ts.sessionStore.DeleteSession(ctx, scooterUUID, stopped.rentalUUID); deleteErr != nil {
+		ctxLogger.Warn("Failed to delete the stored tracking session.", slog.Any("err", deleteErr))
+	}
+
+	if err != nil {
+		return fmt.Errorf("freeing scooter: %w", err)
+	}
+
+	return nil
+}
+
+// resume tracks again the rides stored before the restart of the tracker. The sessions of the scooters freed in the
+// meantime are dropped, while the ones whose scooter can't be checked are resumed and stopped by the end of the
+// rental, if it is still to be delivered.
+func (ts *trackingService) resume(ctx context.Context) error {
+	ctxLogger := logging.FromContext(ctx)
+
+	stored, err := ts.sessionStore.GetSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("getting stored tracking sessions: %w", err)
+	}
+
+	for _, resumed := range stored {
+		sessionLogger := ctxLogger.With(
+			slog.String("scooter_id", resumed.ScooterUUID.String()),
+			slog.String("rental_id", resumed.RentalUUID.String()),
+		)
+
+		scooter, getErr := ts.service.GetScooter(ctx, resumed.ScooterUUID)
+		if getErr != nil {
+			sessionLogger.Warn("Failed to check the scooter of the stored tracking session.", slog.Any("err", getErr))
+		}
+
+		if getErr == nil && scooter.Availability {
+			sessionLogger.Info("Dropped the stored tracking session, the scooter was freed in the meantime.")
+
+			if err = ts.sessionStore.DeleteSession(ctx, resumed.ScooterUUID, resumed.RentalUUID); err != nil {
+				return fmt.Errorf("deleting tracking session of freed scooter: %w", err)
+			}
+
+			continue
+		}
+
+		if err = ts.track(ctx, *resumed); err != nil {
+			return fmt.Errorf("resuming tracking of scooter %s: %w", resumed.ScooterUUID, err)
+		}
 
-	potentialErrors := <-ts.errorsChan[scooterUUID]
-	if potentialErrors != nil {
-		return fmt.Errorf("freeing scooter: %w", potentialErrors)
+		sessionLogger.Info("Resumed tracking scooter.", slog.Time("started_at", resumed.StartedAt))
 	}
 
 	return nil
 }
 
+// HealthCheck reports the tracker as degraded when the recent location updates of all tracked scooters failed.
+func (ts *trackingService) HealthCheck(_ context.Context) error {
+	if failures := ts.consecutiveUpdateFailures.Load(); failures >= maxConsecutiveUpdateFailures {
+		return fmt.Errorf("%d location updates failed in a row: %w", failures, ErrTrackerDegraded)
+	}
+
+	return nil
+}
+
+// report passes the reported position to the tracking go routine of the scooter, if it is tracked by this instance.
+// The position is dropped when the go routine falls behind, as the location is already written.
+func (ts *trackingService) report(ctx context.Context, scooterUUID uuid.UUID, point *model.RoutePoint) {
+	if !ts.sessions.report(scooterUUID, point) {
+		logging.FromContext(ctx).Debug("Reported position is not passed to the tracking, the scooter isn't tracked " +
+			"here or its tracking falls behind.")
+	}
+}
+
+// saveSession stores the session with the latest position of the scooter. The failure is only logged, as it shouldn't
+// stop the tracking, so the tracking resumed after a restart may start from an earlier position.
+func (ts *trackingService) saveSession(ctx context.Context, stored *model.Session) {
+	if err := ts.sessionStore.SaveSession(ctx, stored); err != nil {
+		logging.FromContext(ctx).Warn("Failed to store the tracking session.", slog.Any("err", err))
+	}
+}
+
+// recordRoutePoint appends the point to the route of the rental. The failure is only logged, as it shouldn't stop the
+// tracking, so the route of the ride misses the point.
+func (ts *trackingService) recordRoutePoint(ctx context.Context, rentalUUID uuid.UUID, point *model.RoutePoint) {
+	if err := ts.routes.AppendRoutePoint(ctx, rentalUUID, point); err != nil {
+		logging.FromContext(ctx).Warn("Failed to record the point of the route.", slog.Any("err", err))
+	}
+}
+
+// validateTelemetry checks the readings are in chronological order and none of them is timestamped after the latest
+// time allowed.
+func validateTelemetry(readings []*model.Telemetry, latestAllowed time.Time) error {
+	for i, reading := range readings {
+		if i > 0 && reading.At.Before(readings[i-1].At) {
+			return fmt.Errorf("reading %d is earlier than the one before: %w", i, ErrTelemetryOutOfOrder)
+		}
+
+		if reading.At.After(latestAllowed) {
+			return fmt.Errorf("reading %d at %s: %w", i, reading.At.Format(time.RFC3339), ErrTelemetryInFuture)
+		}
+	}
+
+	return nil
+}
+
+// dropIngested keeps the readings timestamped after the latest one ingested and after each other, dropping the
+// duplicates sent within the batch.
+func dropIngested(readings []*model.Telemetry, latest *model.Telemetry) []*model.Telemetry {
+	var ingestedUntil time.Time
+	if latest != nil {
+		ingestedUntil = latest.At
+	}
+
+	accepted := make([]*model.Telemetry, 0, len(readings))
+
+	for _, reading := range readings {
+		if !reading.At.After(ingestedUntil) {
+			continue
+		}
+
+		accepted = append(accepted, reading)
+		ingestedUntil = reading.At
+	}
+
+	return accepted
+}
+
+// joinRentalErrors sums the failures the tracking met up, nil when there were none.
+func joinRentalErrors(scooterUUID uuid.UUID, rentalErrors map[string]int) error {
+	if len(rentalErrors) == 0 {
+		return nil
+	}
+
+	routineErrors := fmt.Errorf("tracking of scooter %s met several errors", scooterUUID)
+
+	for key, value := range rentalErrors {
+		routineErrors = fmt.Errorf("%w: %s, %d times", routineErrors, key, value)
+	}
+
+	return routineErrors
+}
+
 // simulateScooterMove is simulating the move of the scooter, I assume that each scooter goes on average 36 km/h
 // which is around one second degree per second(approximately for both latitude and longitude). I pick
 // one of four sides(north, west, east, south) and move the scooter three second degrees in that direction.
@@ -159,3 +639,26 @@ func simulateScooterMove(scooter *model.Scooter, timeInSeconds int, direction in
 		scooter.Longitude -= float64(timeInSeconds) * oneSecondDecimal
 	}
 }
+
+func newMovedEvent(scooterUUID uuid.UUID, scooter *model.Scooter, occurredAt time.Time) *eventmodel.Event {
+	return &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeScooterMoved,
+		OccurredAt:  occurredAt,
+		ScooterUUID: scooterUUID,
+		City:        scooter.City,
+		Longitude:   scooter.Longitude,
+		Latitude:    scooter.Latitude,
+	}
+}
+
+// distance returns the great-circle distance between the two points in meters (the haversine formula).
+func distance(fromLongitude, fromLatitude, toLongitude, toLatitude float64) float64 {
+	fromLat, toLat := fromLatitude*math.Pi/180, toLatitude*math.Pi/180
+	deltaLat, deltaLong := toLat-fromLat, (toLongitude-fromLongitude)*math.Pi/180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(fromLat)*math.Cos(toLat)*math.Sin(deltaLong/2)*math.Sin(deltaLong/2)
+
+	return 2 * earthRadiusInMeters * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
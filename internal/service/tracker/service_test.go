@@ -3,29 +3,49 @@
 package tracker
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	redisservice "github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 )
 
 const (
+	// trackingErrorsLog is logged by the handler of the end of the rental whose tracking met errors.
+	trackingErrorsLog = "Tracking of the freed scooter met errors."
+
 	amountOfScooterTrackingEvents = 2
 	firstTestCity                 = "Montreal"
 	secondTestCity                = "Ottawa"
+
+	testMaxClockSkew   = time.Minute
+	testRouteTolerance = 5.0
 )
 
 func TestTrackScooter(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
+	ctx := logging.WithLogger(context.Background(), logger)
+
 	userUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
@@ -51,29 +71,26 @@ func TestTrackScooter(t *testing.T) {
 	}
 
 	tests := map[string]struct {
-		logger                  *slog.Logger
 		mockRedisServiceHandler func(mock *mock.MockScooterRepository)
 		wantErr                 bool
 	}{
 		"successfully tracking multiple scooters": {
-			logger: logger,
 			mockRedisServiceHandler: func(mock *mock.MockScooterRepository) {
 				for i := range scooters {
-					mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooters[i]).
+					mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooterNamed(scooters[i].Name)).
 						Return(nil).Times(amountOfScooterTrackingEvents)
 				}
 			},
 			wantErr: false,
 		},
 		"failed tracking multiple scooters, because of redis service threw error when updating scooter location ": {
-			logger: logger,
 			mockRedisServiceHandler: func(mock *mock.MockScooterRepository) {
-				mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooters[0]).
+				mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooterNamed(scooters[0].Name)).
 					Return(nil).Times(amountOfScooterTrackingEvents - 1)
-				mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooters[0]).
+				mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooterNamed(scooters[0].Name)).
 					Return(redis.ErrClosed).Times(1)
 				for i := 1; i < len(scooters); i++ {
-					mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooters[i]).
+					mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooterNamed(scooters[i].Name)).
 						Return(nil).Times(amountOfScooterTrackingEvents)
 				}
 			},
@@ -89,44 +106,55 @@ func TestTrackScooter(t *testing.T) {
 
 			tt.mockRedisServiceHandler(mockRedisService)
 
-			ts := NewTrackingService(tt.logger, mockRedisService)
+			ts, err := NewTrackingService(
+				ctx,
+				mockRedisService,
+				newTestRentalRepository(controller),
+				mock.NewMockTelemetryRepository(controller),
+				newTestRouteRepository(controller),
+				newTestSessionRepository(controller),
+				newTestEventPublisher(controller),
+				ModeSimulated,
+				testMaxClockSkew,
+				testRouteTolerance,
+			)
+			require.NoError(t, err)
+
+			rentalUUIDs := make([]uuid.UUID, len(scooters))
 
 			for i := range scooters {
-				innerErr := ts.Track(userUUID, scooters[i])
+				rentalUUIDs[i] = uuid.New()
+
+				innerErr := ts.Track(ctx, userUUID, rentalUUIDs[i], scooters[i])
 				require.NoError(t, innerErr)
 			}
 
-			if len(ts.rentedScooters) != len(scooters) {
-				t.Errorf(
-					"Track() should rent all given scooters = %v, want %v",
-					len(ts.rentedScooters),
-					len(scooters),
-				)
+			for i := range scooters {
+				scooterUUID, innerErr := uuid.Parse(scooters[i].Name)
+				require.NoError(t, innerErr)
+
+				if !ts.sessions.tracked(scooterUUID) {
+					t.Errorf("Track() should rent all given scooters, %s is not tracked", scooterUUID)
+				}
 			}
 
 			for i := 0; i < amountOfScooterTrackingEvents; i++ {
 				time.Sleep((MovingTimeInSeconds + 1) * time.Second)
 			}
 
+			var errorFound bool
+
 			for i := range scooters {
 				scooterUUID, innerErr := uuid.Parse(scooters[i].Name)
 				require.NoError(t, innerErr)
 
-				ts.rentedScooters[scooterUUID] <- scooterUUID
-			}
-
-			var errorFound bool
-
-			for i := range ts.errorsChan {
-				if err = <-ts.errorsChan[i]; err != nil {
+				if strings.Contains(endRental(t, ts, rentalUUIDs[i], scooterUUID), trackingErrorsLog) {
 					errorFound = true
 				}
-
-				close(ts.errorsChan[i])
 			}
 
 			if errorFound != tt.wantErr {
-				t.Errorf("Track() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("Track() errors logged = %v, wantErr %v", errorFound, tt.wantErr)
 
 				return
 			}
@@ -137,12 +165,17 @@ func TestTrackScooter(t *testing.T) {
 func TestFreeScooter(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
+	ctx := logging.WithLogger(context.Background(), logger)
+
 	userUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
 	firstScooterUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
 	scooter := &model.Scooter{
 		Name:      firstScooterUUID.String(),
 		Longitude: 70.01,
@@ -151,26 +184,23 @@ func TestFreeScooter(t *testing.T) {
 	}
 
 	tests := map[string]struct {
-		logger                  *slog.Logger
 		mockRedisServiceHandler func(mock *mock.MockScooterRepository)
 		rentScooterHandler      func(tracker *trackingService) error
 		wantErr                 bool
 	}{
 		"successfully freeing scooter": {
-			logger:                  logger,
 			mockRedisServiceHandler: nil,
 			rentScooterHandler: func(ts *trackingService) error {
-				return ts.Track(firstScooterUUID, scooter)
+				return ts.Track(ctx, userUUID, rentalUUID, scooter)
 			},
 			wantErr: false,
 		},
 		"freeing scooter failed, because scooter's rental process threw error": {
-			logger: logger,
 			mockRedisServiceHandler: func(mock *mock.MockScooterRepository) {
-				mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooter).Return(redis.ErrClosed)
+				mock.EXPECT().UpdateScooterLocation(gomock.Any(), scooterNamed(scooter.Name)).Return(redis.ErrClosed)
 			},
 			rentScooterHandler: func(ts *trackingService) error {
-				innerErr := ts.Track(firstScooterUUID, scooter)
+				innerErr := ts.Track(ctx, userUUID, rentalUUID, scooter)
 				require.NoError(t, innerErr)
 
 				time.Sleep((MovingTimeInSeconds + 1) * time.Second)
@@ -190,14 +220,982 @@ func TestFreeScooter(t *testing.T) {
 				tt.mockRedisServiceHandler(mockRedisService)
 			}
 
-			ts := NewTrackingService(tt.logger, mockRedisService)
+			ts, err := NewTrackingService(
+				ctx,
+				mockRedisService,
+				newTestRentalRepository(controller),
+				mock.NewMockTelemetryRepository(controller),
+				newTestRouteRepository(controller),
+				newTestSessionRepository(controller),
+				newTestEventPublisher(controller),
+				ModeSimulated,
+				testMaxClockSkew,
+				testRouteTolerance,
+			)
+			require.NoError(t, err)
 
 			err = tt.rentScooterHandler(ts)
 			require.NoError(t, err)
 
-			if err = ts.StopTracking(userUUID, firstScooterUUID); (err != nil) != tt.wantErr {
-				t.Errorf("StopTracking() error = %v, wantErr %v", err, tt.wantErr)
+			logs := endRental(t, ts, rentalUUID, firstScooterUUID)
+			if logged := strings.Contains(logs, trackingErrorsLog); logged != tt.wantErr {
+				t.Errorf("HandleEvent() errors logged = %v, wantErr %v", logged, tt.wantErr)
+			}
+
+			require.False(t, ts.sessions.tracked(firstScooterUUID))
+		})
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	ctx := context.Background()
+
+	userUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	nextRentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	started := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalStarted,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: scooterUUID,
+		City:        firstTestCity,
+		Longitude:   70.01,
+		Latitude:    60.01,
+	}
+	ended := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalEnded,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: scooterUUID,
+	}
+	nextStarted := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalStarted,
+		RentalUUID:  nextRentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: scooterUUID,
+		City:        firstTestCity,
+		Longitude:   70.01,
+		Latitude:    60.01,
+	}
+
+	endedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		events              []*eventmodel.Event
+		mockRentalsHandler  func(mock *mock.MockRentalRepository)
+		mockScootersHandler func(mock *mock.MockScooterRepository)
+		wantTracking        bool
+	}{
+		"successfully started tracking rented scooter": {
+			events:       []*eventmodel.Event{started},
+			wantTracking: true,
+		},
+		"successfully started tracking once, when the start was delivered again": {
+			events:       []*eventmodel.Event{started, started},
+			wantTracking: true,
+		},
+		"successfully stopped tracking freed scooter": {
+			events:       []*eventmodel.Event{started, ended},
+			wantTracking: false,
+		},
+		"successfully skipped the end delivered again": {
+			events:       []*eventmodel.Event{started, ended, ended},
+			wantTracking: false,
+		},
+		"successfully skipped the end of untracked scooter": {
+			events:       []*eventmodel.Event{ended},
+			wantTracking: false,
+		},
+		"successfully replaced the tracking of the rental whose end was missed": {
+			events:       []*eventmodel.Event{started, nextStarted},
+			wantTracking: true,
+		},
+		"successfully skipped the end of the rental replaced by the next one": {
+			events:       []*eventmodel.Event{started, nextStarted, ended},
+			wantTracking: true,
+		},
+		"successfully skipped the start of the rental ended in the meantime": {
+			events: []*eventmodel.Event{started},
+			mockRentalsHandler: func(mock *mock.MockRentalRepository) {
+				mock.EXPECT().GetRental(gomock.Any(), rentalUUID).
+					Return(&rentalmodel.Rental{UUID: rentalUUID, EndedAt: &endedAt}, nil)
+			},
+			wantTracking: false,
+		},
+		"successfully skipped the start of the rental never recorded, as its scooter is free": {
+			events: []*eventmodel.Event{started},
+			mockRentalsHandler: func(mock *mock.MockRentalRepository) {
+				mock.EXPECT().GetRental(gomock.Any(), rentalUUID).Return(nil, service.ErrRentalNotFound)
+			},
+			mockScootersHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+					Return(rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.01, 60.01, true), nil)
+			},
+			wantTracking: false,
+		},
+		"successfully started tracking the rental not recorded yet, as its scooter is rented": {
+			events: []*eventmodel.Event{started},
+			mockRentalsHandler: func(mock *mock.MockRentalRepository) {
+				mock.EXPECT().GetRental(gomock.Any(), rentalUUID).Return(nil, service.ErrRentalNotFound)
+			},
+			mockScootersHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+					Return(rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.01, 60.01, false), nil)
+			},
+			wantTracking: true,
+		},
+		"successfully started tracking the rental that couldn't be checked": {
+			events: []*eventmodel.Event{started},
+			mockRentalsHandler: func(mock *mock.MockRentalRepository) {
+				mock.EXPECT().GetRental(gomock.Any(), rentalUUID).Return(nil, redis.ErrClosed)
+			},
+			wantTracking: true,
+		},
+		"successfully skipped the move of scooter": {
+			events:       []*eventmodel.Event{{UUID: uuid.New(), Type: eventmodel.TypeScooterMoved}},
+			wantTracking: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockScooters := mock.NewMockScooterRepository(controller)
+			if tt.mockScootersHandler != nil {
+				tt.mockScootersHandler(mockScooters)
+			}
+
+			mockRentals := newTestRentalRepository(controller)
+			if tt.mockRentalsHandler != nil {
+				mockRentals = mock.NewMockRentalRepository(controller)
+				tt.mockRentalsHandler(mockRentals)
+			}
+
+			ts, err := NewTrackingService(
+				ctx,
+				mockScooters,
+				mockRentals,
+				mock.NewMockTelemetryRepository(controller),
+				newTestRouteRepository(controller),
+				newTestSessionRepository(controller),
+				newTestEventPublisher(controller),
+				ModeSimulated,
+				testMaxClockSkew,
+				testRouteTolerance,
+			)
+			require.NoError(t, err)
+
+			for _, event := range tt.events {
+				require.NoError(t, ts.HandleEvent(ctx, event))
+			}
+
+			if tracking := ts.sessions.tracked(scooterUUID); tracking != tt.wantTracking {
+				t.Errorf("HandleEvent() left tracking = %v, want %v", tracking, tt.wantTracking)
+			}
+
+			// the ride left tracked belongs to either of the rentals, the end of the other one is skipped
+			if tt.wantTracking {
+				endRental(t, ts, rentalUUID, scooterUUID)
+				endRental(t, ts, nextRentalUUID, scooterUUID)
+
+				require.False(t, ts.sessions.tracked(scooterUUID))
+			}
+		})
+	}
+}
+
+// TestHandleEventOnTwoInstances delivers the events of the rental to two instances sharing one bus, as every instance
+// reads them in its own consumer group, and sharing the sessions stored in Redis.
+func TestHandleEventOnTwoInstances(t *testing.T) {
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockScooters := mock.NewMockScooterRepository(controller)
+	mockScooters.EXPECT().GetScooter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, scooterUUID uuid.UUID) (*rentalmodel.Scooter, error) {
+			return rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.01, 60.01, false), nil
+		}).AnyTimes()
+	mockScooters.EXPECT().UpdateScooterLocation(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	mockTelemetry := mock.NewMockTelemetryRepository(controller)
+	mockTelemetry.EXPECT().GetTelemetry(gomock.Any(), gomock.Any()).Return(nil, service.ErrTelemetryNotFound).AnyTimes()
+	mockTelemetry.EXPECT().SaveTelemetry(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	bus := &testBus{}
+
+	newInstance := func(owner string) *trackingService {
+		ts, err := NewTrackingService(
+			ctx,
+			mockScooters,
+			newTestRentalRepository(controller),
+			mockTelemetry,
+			newTestRouteRepository(controller),
+			redisservice.NewSessionRepository(redisClient, owner),
+			bus,
+			ModeDevice,
+			testMaxClockSkew,
+			testRouteTolerance,
+		)
+		require.NoError(t, err)
+
+		bus.subscribe(ts)
+
+		return ts
+	}
+
+	first, second := newInstance("tracker-1"), newInstance("tracker-2")
+
+	started := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalStarted,
+		RentalUUID:  uuid.New(),
+		UserUUID:    uuid.New(),
+		ScooterUUID: uuid.New(),
+		City:        firstTestCity,
+		Longitude:   70.01,
+		Latitude:    60.01,
+	}
+	ended := &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalEnded,
+		RentalUUID:  started.RentalUUID,
+		UserUUID:    started.UserUUID,
+		ScooterUUID: started.ScooterUUID,
+	}
+
+	require.NoError(t, bus.Publish(ctx, started))
+
+	require.True(t, first.sessions.tracked(started.ScooterUUID))
+	require.False(t, second.sessions.tracked(started.ScooterUUID))
+
+	// the ride is streamed by the instance not tracking it as well
+	events, unsubscribe := second.Subscribe(started.ScooterUUID)
+	defer unsubscribe()
+
+	_, err := first.IngestTelemetry(ctx, started.ScooterUUID, []*model.Telemetry{
+		{Longitude: 70.01, Latitude: 60.011, At: time.Now().UTC()},
+	})
+	require.NoError(t, err)
+
+	select {
+	case position := <-events:
+		require.Equal(t, model.EventPosition, position.Type)
+		require.Equal(t, 60.011, position.Latitude)
+		require.InDelta(t, 111, position.Distance, 1)
+	case <-time.After(time.Second):
+		t.Fatalf("HandleEvent() did not stream the position to the instance not tracking the ride")
+	}
+
+	// the end reaches the instance tracking the ride, whichever handles it first
+	require.NoError(t, second.HandleEvent(ctx, ended))
+	require.NoError(t, first.HandleEvent(ctx, ended))
+
+	require.False(t, first.sessions.tracked(started.ScooterUUID))
+
+	end, open := <-events
+	require.True(t, open)
+	require.Equal(t, model.EventEnded, end.Type)
+	require.InDelta(t, 111, end.Distance, 1)
+
+	_, open = <-events
+	require.False(t, open)
+
+	// the start read again by the group of an instance started later is not tracked once more
+	third := newInstance("tracker-3")
+	require.NoError(t, third.HandleEvent(ctx, started))
+	require.False(t, third.sessions.tracked(started.ScooterUUID))
+
+	// the instance tracking the ride resumes nothing after its restart
+	restarted := newInstance("tracker-1")
+	require.False(t, restarted.sessions.tracked(started.ScooterUUID))
+}
+
+func TestIngestTelemetry(t *testing.T) {
+	ctx := context.Background()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	first := &model.Telemetry{Longitude: 70.01, Latitude: 60.01, At: now.Add(-20 * time.Second), Battery: 80}
+	second := &model.Telemetry{Longitude: 70.02, Latitude: 60.01, At: now.Add(-10 * time.Second), Battery: 79}
+	secondAgain := &model.Telemetry{Longitude: 70.02, Latitude: 60.01, At: second.At, Battery: 79}
+
+	tests := map[string]struct {
+		mode                 Mode
+		readings             []*model.Telemetry
+		mockScootersHandler  func(mock *mock.MockScooterRepository)
+		mockTelemetryHandler func(mock *mock.MockTelemetryRepository)
+		want                 int
+		wantErr              error
+	}{
+		"successfully ingested readings, dropping the duplicate": {
+			mode:     ModeDevice,
+			readings: []*model.Telemetry{first, second, secondAgain},
+			mockScootersHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+					Return(rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.0, 60.0, false), nil)
+				mock.EXPECT().UpdateScooterLocation(gomock.Any(), model.NewScooter(
+					scooterUUID.String(), firstTestCity, first.Longitude, first.Latitude,
+				)).Return(nil)
+				mock.EXPECT().UpdateScooterLocation(gomock.Any(), model.NewScooter(
+					scooterUUID.String(), firstTestCity, second.Longitude, second.Latitude,
+				)).Return(nil)
+			},
+			mockTelemetryHandler: func(mock *mock.MockTelemetryRepository) {
+				mock.EXPECT().GetTelemetry(gomock.Any(), scooterUUID).Return(nil, service.ErrTelemetryNotFound)
+				mock.EXPECT().SaveTelemetry(gomock.Any(), scooterUUID, second).Return(nil)
+			},
+			want: 2,
+		},
+		"successfully dropped readings ingested before": {
+			mode:     ModeDevice,
+			readings: []*model.Telemetry{first, secondAgain},
+			mockScootersHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+					Return(rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.0, 60.0, false), nil)
+			},
+			mockTelemetryHandler: func(mock *mock.MockTelemetryRepository) {
+				mock.EXPECT().GetTelemetry(gomock.Any(), scooterUUID).Return(second, nil)
+			},
+			want: 0,
+		},
+		"failed ingesting readings, because the rides are simulated": {
+			mode:     ModeSimulated,
+			readings: []*model.Telemetry{first},
+			wantErr:  ErrDeviceTrackingDisabled,
+		},
+		"failed ingesting readings, because they are out of order": {
+			mode:     ModeDevice,
+			readings: []*model.Telemetry{second, first},
+			wantErr:  ErrTelemetryOutOfOrder,
+		},
+		"failed ingesting readings, because one is timestamped in the future": {
+			mode:     ModeDevice,
+			readings: []*model.Telemetry{first, {At: now.Add(2 * testMaxClockSkew)}},
+			wantErr:  ErrTelemetryInFuture,
+		},
+		"failed ingesting readings, because the location was not updated": {
+			mode:     ModeDevice,
+			readings: []*model.Telemetry{first, second},
+			mockScootersHandler: func(mock *mock.MockScooterRepository) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+					Return(rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.0, 60.0, false), nil)
+				mock.EXPECT().UpdateScooterLocation(gomock.Any(), gomock.Any()).Return(redis.ErrClosed)
+			},
+			mockTelemetryHandler: func(mock *mock.MockTelemetryRepository) {
+				mock.EXPECT().GetTelemetry(gomock.Any(), scooterUUID).Return(nil, service.ErrTelemetryNotFound)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockScooters := mock.NewMockScooterRepository(controller)
+			mockTelemetry := mock.NewMockTelemetryRepository(controller)
+
+			if tt.mockScootersHandler != nil {
+				tt.mockScootersHandler(mockScooters)
+			}
+
+			if tt.mockTelemetryHandler != nil {
+				tt.mockTelemetryHandler(mockTelemetry)
+			}
+
+			ts, err := NewTrackingService(
+				ctx,
+				mockScooters,
+				newTestRentalRepository(controller),
+				mockTelemetry,
+				newTestRouteRepository(controller),
+				newTestSessionRepository(controller),
+				newTestEventPublisher(controller),
+				tt.mode,
+				testMaxClockSkew,
+				testRouteTolerance,
+			)
+			require.NoError(t, err)
+			ts.now = func() time.Time { return now }
+
+			got, err := ts.IngestTelemetry(ctx, scooterUUID, tt.readings)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("IngestTelemetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTrackReportedPositions(t *testing.T) {
+	ctx := context.Background()
+
+	userUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	reading := &model.Telemetry{Longitude: 70.0, Latitude: 60.001, At: time.Now().UTC()}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockScooters := mock.NewMockScooterRepository(controller)
+	mockScooters.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+		Return(rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.0, 60.0, false), nil)
+	mockScooters.EXPECT().UpdateScooterLocation(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockTelemetry := mock.NewMockTelemetryRepository(controller)
+	mockTelemetry.EXPECT().GetTelemetry(gomock.Any(), scooterUUID).Return(nil, service.ErrTelemetryNotFound)
+	mockTelemetry.EXPECT().SaveTelemetry(gomock.Any(), scooterUUID, gomock.Any()).Return(nil)
+
+	recorded := make(chan *model.RoutePoint, 1)
+
+	mockRoutes := mock.NewMockRouteRepository(controller)
+	mockRoutes.EXPECT().AppendRoutePoint(gomock.Any(), rentalUUID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, point *model.RoutePoint) error {
+			recorded <- point
+
+			return nil
+		}).Times(2)
+
+	// the subscribers are told about the move by its event, handled by the tracker like the others on the bus
+	bus := &testBus{}
+
+	ts, err := NewTrackingService(
+		ctx,
+		mockScooters,
+		newTestRentalRepository(controller),
+		mockTelemetry,
+		mockRoutes,
+		newTestSessionRepository(controller),
+		bus,
+		ModeDevice,
+		testMaxClockSkew,
+		testRouteTolerance,
+	)
+	require.NoError(t, err)
+
+	bus.subscribe(ts)
+
+	require.NoError(t, bus.Publish(ctx, &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalStarted,
+		RentalUUID:  rentalUUID,
+		UserUUID:    userUUID,
+		ScooterUUID: scooterUUID,
+		City:        firstTestCity,
+		Longitude:   70.0,
+		Latitude:    60.0,
+	}))
+
+	// the route starts at the position of the rent
+	start := <-recorded
+	require.Equal(t, 60.0, start.Latitude)
+
+	events, unsubscribe := ts.Subscribe(scooterUUID)
+	defer unsubscribe()
+
+	accepted, err := ts.IngestTelemetry(ctx, scooterUUID, []*model.Telemetry{reading})
+	require.NoError(t, err)
+	require.Equal(t, 1, accepted)
+
+	select {
+	case event := <-events:
+		require.Equal(t, model.EventPosition, event.Type)
+		require.Equal(t, 60.001, event.Latitude)
+		require.InDelta(t, 111, event.Distance, 1)
+	case <-time.After(time.Second):
+		t.Errorf("IngestTelemetry() did not publish the reported position")
+	}
+
+	select {
+	case point := <-recorded:
+		require.Equal(t, reading.At, point.At)
+		require.Equal(t, reading.Latitude, point.Latitude)
+	case <-time.After(time.Second):
+		t.Errorf("Track() did not record the reported position")
+	}
+
+	endRental(t, ts, rentalUUID, scooterUUID)
+}
+
+func TestGetRoute(t *testing.T) {
+	ctx := context.Background()
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	startedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	// the middle point is about a meter off the straight ride, below the tolerance
+	route := []*model.RoutePoint{
+		{Longitude: 70.0, Latitude: 60.0, At: startedAt},
+		{Longitude: 70.00001, Latitude: 60.0005, At: startedAt.Add(3 * time.Second)},
+		{Longitude: 70.0, Latitude: 60.001, At: startedAt.Add(6 * time.Second)},
+	}
+
+	tests := map[string]struct {
+		mockRoutesHandler func(mock *mock.MockRouteRepository)
+		want              []*model.RoutePoint
+		wantErr           bool
+	}{
+		"successfully got simplified route": {
+			mockRoutesHandler: func(mock *mock.MockRouteRepository) {
+				mock.EXPECT().GetRoute(gomock.Any(), rentalUUID).Return(route, nil)
+			},
+			want:    []*model.RoutePoint{route[0], route[2]},
+			wantErr: false,
+		},
+		"failed getting route, because redis service threw error": {
+			mockRoutesHandler: func(mock *mock.MockRouteRepository) {
+				mock.EXPECT().GetRoute(gomock.Any(), rentalUUID).Return(nil, redis.ErrClosed)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockRoutes := mock.NewMockRouteRepository(controller)
+
+			tt.mockRoutesHandler(mockRoutes)
+
+			ts, err := NewTrackingService(
+				ctx,
+				mock.NewMockScooterRepository(controller),
+				newTestRentalRepository(controller),
+				mock.NewMockTelemetryRepository(controller),
+				mockRoutes,
+				newTestSessionRepository(controller),
+				newTestEventPublisher(controller),
+				ModeSimulated,
+				testMaxClockSkew,
+				testRouteTolerance,
+			)
+			require.NoError(t, err)
+
+			got, err := ts.GetRoute(ctx, rentalUUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRoute() error = %v, wantErr %v", err, tt.wantErr)
 			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResumeSessions(t *testing.T) {
+	ctx := context.Background()
+
+	startedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	rented := &model.Session{
+		ScooterUUID: uuid.New(),
+		UserUUID:    uuid.New(),
+		RentalUUID:  uuid.New(),
+		City:        firstTestCity,
+		Longitude:   70.01,
+		Latitude:    60.01,
+		StartedAt:   startedAt,
+	}
+	freed := &model.Session{
+		ScooterUUID: uuid.New(),
+		UserUUID:    uuid.New(),
+		RentalUUID:  uuid.New(),
+		City:        secondTestCity,
+		Longitude:   69.99,
+		Latitude:    59.99,
+		StartedAt:   startedAt,
+	}
+
+	tests := map[string]struct {
+		mockHandler func(scooters *mock.MockScooterRepository, sessionStore *mock.MockTrackingSessionRepository)
+		wantTracked []uuid.UUID
+		wantErr     bool
+	}{
+		"resumed session of rented scooter and dropped the one of freed scooter": {
+			mockHandler: func(scooters *mock.MockScooterRepository, sessionStore *mock.MockTrackingSessionRepository) {
+				sessionStore.EXPECT().GetSessions(gomock.Any()).Return([]*model.Session{rented, freed}, nil)
+				scooters.EXPECT().GetScooter(gomock.Any(), rented.ScooterUUID).
+					Return(rentalmodel.NewScooter(rented.ScooterUUID.String(), firstTestCity, 70.01, 60.01, false), nil)
+				scooters.EXPECT().GetScooter(gomock.Any(), freed.ScooterUUID).
+					Return(rentalmodel.NewScooter(freed.ScooterUUID.String(), secondTestCity, 69.99, 59.99, true), nil)
+				sessionStore.EXPECT().SaveSession(gomock.Any(), rented).Return(nil)
+				sessionStore.EXPECT().DeleteSession(gomock.Any(), freed.ScooterUUID, freed.RentalUUID).Return(nil)
+				sessionStore.EXPECT().DeleteSession(gomock.Any(), rented.ScooterUUID, rented.RentalUUID).Return(nil)
+			},
+			wantTracked: []uuid.UUID{rented.ScooterUUID},
+			wantErr:     false,
+		},
+		"resumed session, although its scooter couldn't be checked": {
+			mockHandler: func(scooters *mock.MockScooterRepository, sessionStore *mock.MockTrackingSessionRepository) {
+				sessionStore.EXPECT().GetSessions(gomock.Any()).Return([]*model.Session{rented}, nil)
+				scooters.EXPECT().GetScooter(gomock.Any(), rented.ScooterUUID).Return(nil, redis.ErrClosed)
+				sessionStore.EXPECT().SaveSession(gomock.Any(), rented).Return(nil)
+				sessionStore.EXPECT().DeleteSession(gomock.Any(), rented.ScooterUUID, rented.RentalUUID).Return(nil)
+			},
+			wantTracked: []uuid.UUID{rented.ScooterUUID},
+			wantErr:     false,
+		},
+		"resumed nothing, as no session is stored": {
+			mockHandler: func(_ *mock.MockScooterRepository, sessionStore *mock.MockTrackingSessionRepository) {
+				sessionStore.EXPECT().GetSessions(gomock.Any()).Return([]*model.Session{}, nil)
+			},
+			wantTracked: nil,
+			wantErr:     false,
+		},
+		"failed resuming sessions, because redis service threw error when getting them": {
+			mockHandler: func(_ *mock.MockScooterRepository, sessionStore *mock.MockTrackingSessionRepository) {
+				sessionStore.EXPECT().GetSessions(gomock.Any()).Return(nil, redis.ErrClosed)
+			},
+			wantTracked: nil,
+			wantErr:     true,
+		},
+		"failed resuming sessions, because redis service threw error when dropping the freed one": {
+			mockHandler: func(scooters *mock.MockScooterRepository, sessionStore *mock.MockTrackingSessionRepository) {
+				sessionStore.EXPECT().GetSessions(gomock.Any()).Return([]*model.Session{freed}, nil)
+				scooters.EXPECT().GetScooter(gomock.Any(), freed.ScooterUUID).
+					Return(rentalmodel.NewScooter(freed.ScooterUUID.String(), secondTestCity, 69.99, 59.99, true), nil)
+				sessionStore.EXPECT().DeleteSession(gomock.Any(), freed.ScooterUUID, freed.RentalUUID).
+					Return(redis.ErrClosed)
+			},
+			wantTracked: nil,
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockScooters := mock.NewMockScooterRepository(controller)
+			mockSessionStore := mock.NewMockTrackingSessionRepository(controller)
+
+			tt.mockHandler(mockScooters, mockSessionStore)
+
+			// the device mode doesn't move the resumed scooters, so only the stored sessions are touched
+			ts, err := NewTrackingService(
+				ctx,
+				mockScooters,
+				newTestRentalRepository(controller),
+				mock.NewMockTelemetryRepository(controller),
+				newTestRouteRepository(controller),
+				mockSessionStore,
+				newTestEventPublisher(controller),
+				ModeDevice,
+				testMaxClockSkew,
+				testRouteTolerance,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTrackingService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			for _, scooterUUID := range []uuid.UUID{rented.ScooterUUID, freed.ScooterUUID} {
+				require.Equal(t, slices.Contains(tt.wantTracked, scooterUUID), ts.sessions.tracked(scooterUUID))
+			}
+
+			if slices.Contains(tt.wantTracked, rented.ScooterUUID) {
+				endRental(t, ts, rented.RentalUUID, rented.ScooterUUID)
+			}
+		})
+	}
+}
+
+func TestTrackRecoversPanic(t *testing.T) {
+	ctx := context.Background()
+
+	userUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockRoutes := mock.NewMockRouteRepository(controller)
+	mockRoutes.EXPECT().AppendRoutePoint(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, uuid.UUID, *model.RoutePoint) error {
+			panic("route repository is broken")
 		})
+
+	ts, err := NewTrackingService(
+		ctx,
+		mock.NewMockScooterRepository(controller),
+		newTestRentalRepository(controller),
+		mock.NewMockTelemetryRepository(controller),
+		mockRoutes,
+		newTestSessionRepository(controller),
+		newTestEventPublisher(controller),
+		ModeDevice,
+		testMaxClockSkew,
+		testRouteTolerance,
+	)
+	require.NoError(t, err)
+
+	events, unsubscribe := ts.Subscribe(scooterUUID)
+	defer unsubscribe()
+
+	require.NoError(t, ts.Track(ctx, userUUID, rentalUUID, model.NewScooter(scooterUUID.String(), firstTestCity, 70, 60)))
+
+	// the panic is reported when the rental ends
+	if logs := endRental(t, ts, rentalUUID, scooterUUID); !strings.Contains(logs, ErrTrackingPanicked.Error()) {
+		t.Errorf("HandleEvent() logged %q, want it to hold %v", logs, ErrTrackingPanicked)
+	}
+
+	// the subscribers are told the ride ended, although its tracking didn't make it to the end
+	select {
+	case event := <-events:
+		require.Equal(t, model.EventEnded, event.Type)
+	case <-time.After(time.Second):
+		t.Errorf("HandleEvent() did not end the ride of the panicked go routine")
+	}
+}
+
+// TestTrackConcurrently drives the rides of several scooters from concurrent handlers, meant to be run with the race
+// detector.
+func TestTrackConcurrently(t *testing.T) {
+	const (
+		scooterCount  = 8
+		deliveryCount = 3
+	)
+
+	ctx := context.Background()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockScooters := mock.NewMockScooterRepository(controller)
+	mockScooters.EXPECT().GetScooter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, scooterUUID uuid.UUID) (*rentalmodel.Scooter, error) {
+			return rentalmodel.NewScooter(scooterUUID.String(), firstTestCity, 70.0, 60.0, false), nil
+		}).AnyTimes()
+	mockScooters.EXPECT().UpdateScooterLocation(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	mockTelemetry := mock.NewMockTelemetryRepository(controller)
+	mockTelemetry.EXPECT().GetTelemetry(gomock.Any(), gomock.Any()).Return(nil, service.ErrTelemetryNotFound).AnyTimes()
+	mockTelemetry.EXPECT().SaveTelemetry(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	ts, err := NewTrackingService(
+		ctx,
+		mockScooters,
+		newTestRentalRepository(controller),
+		mockTelemetry,
+		newTestRouteRepository(controller),
+		newTestSessionRepository(controller),
+		newTestEventPublisher(controller),
+		ModeDevice,
+		testMaxClockSkew,
+		testRouteTolerance,
+	)
+	require.NoError(t, err)
+
+	scooterUUIDs := make([]uuid.UUID, scooterCount)
+	for i := range scooterUUIDs {
+		scooterUUIDs[i] = uuid.New()
 	}
+
+	// every event is delivered several times at once, as by the consumers of the group claiming it from each other
+	deliver := func(event *eventmodel.Event) {
+		var deliveries sync.WaitGroup
+
+		for i := 0; i < deliveryCount; i++ {
+			deliveries.Add(1)
+
+			go func() {
+				defer deliveries.Done()
+
+				require.NoError(t, ts.HandleEvent(ctx, event))
+			}()
+		}
+
+		deliveries.Wait()
+	}
+
+	var rides sync.WaitGroup
+
+	for _, scooterUUID := range scooterUUIDs {
+		rides.Add(1)
+
+		go func(scooterUUID uuid.UUID) {
+			defer rides.Done()
+
+			started := &eventmodel.Event{
+				UUID:        uuid.New(),
+				Type:        eventmodel.TypeRentalStarted,
+				RentalUUID:  uuid.New(),
+				ScooterUUID: scooterUUID,
+				City:        firstTestCity,
+				Longitude:   70.0,
+				Latitude:    60.0,
+			}
+
+			deliver(started)
+
+			events, unsubscribe := ts.Subscribe(scooterUUID)
+			defer unsubscribe()
+
+			_, err := ts.IngestTelemetry(ctx, scooterUUID, []*model.Telemetry{
+				{Longitude: 70.0, Latitude: 60.001, At: time.Now().UTC()},
+			})
+			require.NoError(t, err)
+
+			deliver(&eventmodel.Event{
+				UUID:        uuid.New(),
+				Type:        eventmodel.TypeRentalEnded,
+				RentalUUID:  started.RentalUUID,
+				ScooterUUID: scooterUUID,
+			})
+
+			// the ride ends for the subscriber once, however many times its end was delivered
+			for event := range events {
+				if event.Type == model.EventEnded {
+					return
+				}
+			}
+
+			t.Errorf("HandleEvent() did not end the ride of scooter %s", scooterUUID)
+		}(scooterUUID)
+	}
+
+	rides.Wait()
+
+	for _, scooterUUID := range scooterUUIDs {
+		if ts.sessions.tracked(scooterUUID) {
+			t.Errorf("HandleEvent() left scooter %s tracked", scooterUUID)
+		}
+	}
+}
+
+// endRental delivers the end of the rental to the tracker and returns what the handler logged, the failures of the
+// tracking included, as the handler doesn't return them.
+func endRental(t *testing.T, ts *trackingService, rentalUUID, scooterUUID uuid.UUID) string {
+	t.Helper()
+
+	var logs bytes.Buffer
+
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+
+	require.NoError(t, ts.HandleEvent(ctx, &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalEnded,
+		RentalUUID:  rentalUUID,
+		ScooterUUID: scooterUUID,
+	}))
+
+	return logs.String()
+}
+
+// testBus hands every published event to all the trackers right away, as the event bus does to the tracker group of
+// every instance.
+type testBus struct {
+	mu       sync.Mutex
+	trackers []*trackingService
+}
+
+func (b *testBus) subscribe(ts *trackingService) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trackers = append(b.trackers, ts)
+}
+
+func (b *testBus) Publish(ctx context.Context, event *eventmodel.Event) error {
+	b.mu.Lock()
+	trackers := slices.Clone(b.trackers)
+	b.mu.Unlock()
+
+	for _, ts := range trackers {
+		if err := ts.HandleEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newTestEventPublisher(controller *gomock.Controller) *mock.MockEventPublisher {
+	publisher := mock.NewMockEventPublisher(controller)
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return publisher
+}
+
+// newTestRentalRepository returns the repository holding every rental as active.
+func newTestRentalRepository(controller *gomock.Controller) *mock.MockRentalRepository {
+	rentals := mock.NewMockRentalRepository(controller)
+	rentals.EXPECT().GetRental(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rentalUUID uuid.UUID) (*rentalmodel.Rental, error) {
+			return &rentalmodel.Rental{UUID: rentalUUID}, nil
+		},
+	).AnyTimes()
+
+	return rentals
+}
+
+func newTestSessionRepository(controller *gomock.Controller) *mock.MockTrackingSessionRepository {
+	sessionStore := mock.NewMockTrackingSessionRepository(controller)
+	sessionStore.EXPECT().GetSessions(gomock.Any()).Return(nil, nil).AnyTimes()
+	sessionStore.EXPECT().ClaimSession(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	sessionStore.EXPECT().SaveSession(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	sessionStore.EXPECT().DeleteSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return sessionStore
+}
+
+func newTestRouteRepository(controller *gomock.Controller) *mock.MockRouteRepository {
+	routes := mock.NewMockRouteRepository(controller)
+	routes.EXPECT().AppendRoutePoint(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return routes
+}
+
+// scooterNamed matches the scooter by its name, as the tracking go routine moves its own copy of the tracked one.
+type scooterNamed string
+
+func (n scooterNamed) Matches(x any) bool {
+	scooter, ok := x.(*model.Scooter)
+
+	return ok && scooter.Name == string(n)
+}
+
+func (n scooterNamed) String() string {
+	return "is scooter named " + string(n)
 }
@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+// session is the tracking of a single ride, run by its go routine from the start of the rental until the session is
+// stopped.
+type session struct {
+	rentalUUID uuid.UUID
+	cancel     context.CancelFunc
+	// reports carries the positions reported by the scooter to the go routine in the device mode, it is nil in the
+	// simulated one.
+	reports chan *model.RoutePoint
+	// done is closed when the go routine finishes, err holding the failures it met on the way by then.
+	done chan struct{}
+	err  error
+}
+
+func newSession(rentalUUID uuid.UUID, cancel context.CancelFunc, reports chan *model.RoutePoint) *session {
+	return &session{
+		rentalUUID: rentalUUID,
+		cancel:     cancel,
+		reports:    reports,
+		done:       make(chan struct{}),
+	}
+}
+
+// stop cancels the go routine of the session and waits for it to finish, returning the failures it met.
+func (s *session) stop() error {
+	s.cancel()
+
+	<-s.done
+
+	return s.err
+}
+
+// sessions is the registry of the tracked scooters, shared by the handlers of the events and the requests.
+type sessions struct {
+	mu        sync.Mutex
+	byScooter map[uuid.UUID]*session
+}
+
+func newSessions() *sessions {
+	return &sessions{
+		byScooter: make(map[uuid.UUID]*session),
+	}
+}
+
+// add registers the session of the scooter. The session of the same rental already registered is kept and
+// ErrAlreadyTracked is returned, while the session of another rental, whose end was missed, is replaced and
+// returned to be stopped.
+func (s *sessions) add(scooterUUID uuid.UUID, added *session) (*session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replaced, ok := s.byScooter[scooterUUID]
+	if ok && replaced.rentalUUID == added.rentalUUID {
+		return nil, ErrAlreadyTracked
+	}
+
+	s.byScooter[scooterUUID] = added
+
+	return replaced, nil
+}
+
+// remove unregisters the session of the scooter, provided it tracks the rental.
+func (s *sessions) remove(scooterUUID, rentalUUID uuid.UUID) (*session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed, ok := s.byScooter[scooterUUID]
+	if !ok || removed.rentalUUID != rentalUUID {
+		return nil, false
+	}
+
+	delete(s.byScooter, scooterUUID)
+
+	return removed, true
+}
+
+// report passes the reported position to the session of the scooter without blocking. It returns false when the
+// scooter is not tracked or its go routine falls behind.
+func (s *sessions) report(scooterUUID uuid.UUID, point *model.RoutePoint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reported, ok := s.byScooter[scooterUUID]
+	if !ok || reported.reports == nil {
+		return false
+	}
+
+	select {
+	case reported.reports <- point:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *sessions) tracked(scooterUUID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.byScooter[scooterUUID]
+
+	return ok
+}
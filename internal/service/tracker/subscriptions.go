@@ -0,0 +1,162 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+// subscriptionBuffer is the number of events a subscriber can fall behind before its oldest ones are dropped.
+const subscriptionBuffer = 16
+
+// ride is the progress of the ride of the scooter told to its subscribers.
+type ride struct {
+	// rentalUUID is nil for the ride whose start was published before the instance started to read the events.
+	rentalUUID          uuid.UUID
+	longitude, latitude float64
+	travelled           float64
+}
+
+// subscriptions fans the events of the rides out to their subscribers. Publishing never blocks the tracker: a
+// subscriber too slow to keep up loses its oldest events, which are superseded by the newer positions anyway.
+//
+// The rides are followed from the events of the bus, which every instance reads, rather than from the tracking,
+// which runs on one instance only, so the subscribers connected to any instance are told about the ride.
+type subscriptions struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan model.Event]struct{}
+	rides       map[uuid.UUID]*ride
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		subscribers: make(map[uuid.UUID]map[chan model.Event]struct{}),
+		rides:       make(map[uuid.UUID]*ride),
+	}
+}
+
+// subscribe returns the channel of the events of the scooter, closed after the ended event, and the function that
+// cancels the subscription.
+func (s *subscriptions) subscribe(scooterUUID uuid.UUID) (<-chan model.Event, func()) {
+	events := make(chan model.Event, subscriptionBuffer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscribers[scooterUUID] == nil {
+		s.subscribers[scooterUUID] = make(map[chan model.Event]struct{})
+	}
+
+	s.subscribers[scooterUUID][events] = struct{}{}
+
+	return events, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if _, ok := s.subscribers[scooterUUID][events]; !ok {
+			return
+		}
+
+		delete(s.subscribers[scooterUUID], events)
+
+		if len(s.subscribers[scooterUUID]) == 0 {
+			delete(s.subscribers, scooterUUID)
+		}
+
+		close(events)
+	}
+}
+
+// start begins the ride of the rental at the position the scooter was rented at.
+func (s *subscriptions) start(scooterUUID, rentalUUID uuid.UUID, longitude, latitude float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rides[scooterUUID] = &ride{rentalUUID: rentalUUID, longitude: longitude, latitude: latitude}
+}
+
+// move publishes the new position of the scooter along with the distance travelled since the start of its ride. The
+// distance of the ride whose start was missed is counted from its first move.
+func (s *subscriptions) move(scooterUUID uuid.UUID, longitude, latitude float64, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	moved, ok := s.rides[scooterUUID]
+	if !ok {
+		moved = &ride{longitude: longitude, latitude: latitude}
+		s.rides[scooterUUID] = moved
+	}
+
+	moved.travelled += distance(moved.longitude, moved.latitude, longitude, latitude)
+	moved.longitude, moved.latitude = longitude, latitude
+
+	s.publish(newRideEvent(model.EventPosition, scooterUUID, moved, at))
+}
+
+// finish ends the ride of the rental, sending the ended event to the subscribers of its scooter. The end of another
+// rental than the one the ride started with is skipped, as it belongs to an earlier ride.
+func (s *subscriptions) finish(scooterUUID, rentalUUID uuid.UUID, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished, ok := s.rides[scooterUUID]
+	if !ok {
+		finished = &ride{}
+	}
+
+	if finished.rentalUUID != uuid.Nil && finished.rentalUUID != rentalUUID {
+		return
+	}
+
+	delete(s.rides, scooterUUID)
+
+	s.end(newRideEvent(model.EventEnded, scooterUUID, finished, at))
+}
+
+// publish sends the event to the subscribers of its scooter, the lock held by the caller.
+func (s *subscriptions) publish(event model.Event) {
+	for events := range s.subscribers[event.ScooterUUID] {
+		send(events, event)
+	}
+}
+
+// end sends the ended event to the subscribers of its scooter and closes their channels, the lock held by the caller.
+func (s *subscriptions) end(event model.Event) {
+	for events := range s.subscribers[event.ScooterUUID] {
+		send(events, event)
+		close(events)
+	}
+
+	delete(s.subscribers, event.ScooterUUID)
+}
+
+func newRideEvent(eventType model.EventType, scooterUUID uuid.UUID, progress *ride, at time.Time) model.Event {
+	return model.Event{
+		Type:        eventType,
+		ScooterUUID: scooterUUID,
+		Longitude:   progress.longitude,
+		Latitude:    progress.latitude,
+		Distance:    progress.travelled,
+		At:          at,
+	}
+}
+
+// send delivers the event without blocking, making room for it by dropping the oldest pending event. Only the
+// publisher holding the lock sends, so the freed slot can't be taken by anyone else.
+func send(events chan model.Event, event model.Event) {
+	for {
+		select {
+		case events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-events:
+		default:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+//go:build unit
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestSubscriptions(t *testing.T) {
+	scooterUUID := uuid.New()
+
+	position := func(distance float64) model.Event {
+		return model.Event{Type: model.EventPosition, ScooterUUID: scooterUUID, Distance: distance}
+	}
+
+	tests := map[string]struct {
+		run func(t *testing.T, s *subscriptions)
+	}{
+		"successfully published events to all subscribers of the scooter": {
+			run: func(t *testing.T, s *subscriptions) {
+				first, _ := s.subscribe(scooterUUID)
+				second, _ := s.subscribe(scooterUUID)
+				other, _ := s.subscribe(uuid.New())
+
+				s.publish(position(1))
+
+				require.Equal(t, position(1), <-first)
+				require.Equal(t, position(1), <-second)
+				require.Empty(t, other)
+			},
+		},
+		"successfully dropped the oldest events of a slow subscriber": {
+			run: func(t *testing.T, s *subscriptions) {
+				events, _ := s.subscribe(scooterUUID)
+
+				for i := 0; i < subscriptionBuffer+2; i++ {
+					s.publish(position(float64(i)))
+				}
+
+				require.Len(t, events, subscriptionBuffer)
+				require.Equal(t, position(2), <-events)
+			},
+		},
+		"successfully ended subscriptions with the ended event": {
+			run: func(t *testing.T, s *subscriptions) {
+				events, unsubscribe := s.subscribe(scooterUUID)
+
+				ended := model.Event{Type: model.EventEnded, ScooterUUID: scooterUUID}
+
+				s.end(ended)
+
+				require.Equal(t, ended, <-events)
+
+				_, open := <-events
+				require.False(t, open)
+
+				// unsubscribing after the end must not close the channel again
+				unsubscribe()
+				require.Empty(t, s.subscribers)
+			},
+		},
+		"successfully followed the ride from its start to its end": {
+			run: func(t *testing.T, s *subscriptions) {
+				rentalUUID := uuid.New()
+				at := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+				events, _ := s.subscribe(scooterUUID)
+
+				s.start(scooterUUID, rentalUUID, 70.0, 60.0)
+				s.move(scooterUUID, 70.0, 60.001, at)
+				s.move(scooterUUID, 70.0, 60.002, at.Add(time.Second))
+
+				first, second := <-events, <-events
+				require.Equal(t, model.EventPosition, first.Type)
+				require.InDelta(t, 111, first.Distance, 1)
+				require.Equal(t, 60.002, second.Latitude)
+				require.InDelta(t, 222, second.Distance, 1)
+				require.Equal(t, at.Add(time.Second), second.At)
+
+				// the end of an earlier rental of the scooter doesn't end the ride
+				s.finish(scooterUUID, uuid.New(), at)
+				require.Empty(t, events)
+
+				s.finish(scooterUUID, rentalUUID, at.Add(2*time.Second))
+
+				ended := <-events
+				require.Equal(t, model.EventEnded, ended.Type)
+				require.Equal(t, 60.002, ended.Latitude)
+				require.InDelta(t, 222, ended.Distance, 1)
+
+				_, open := <-events
+				require.False(t, open)
+				require.Empty(t, s.rides)
+			},
+		},
+		"successfully followed the ride whose start was missed from its first move": {
+			run: func(t *testing.T, s *subscriptions) {
+				events, _ := s.subscribe(scooterUUID)
+
+				s.move(scooterUUID, 70.0, 60.001, time.Now())
+				s.finish(scooterUUID, uuid.New(), time.Now())
+
+				moved, ended := <-events, <-events
+				require.Zero(t, moved.Distance)
+				require.Equal(t, model.EventEnded, ended.Type)
+				require.Equal(t, 60.001, ended.Latitude)
+			},
+		},
+		"successfully unsubscribed": {
+			run: func(t *testing.T, s *subscriptions) {
+				events, unsubscribe := s.subscribe(scooterUUID)
+
+				unsubscribe()
+				unsubscribe()
+
+				s.publish(position(1))
+
+				_, open := <-events
+				require.False(t, open)
+				require.Empty(t, s.subscribers)
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.run(t, newSubscriptions())
+		})
+	}
+}
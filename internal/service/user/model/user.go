@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+)
+
+type Profile struct {
+	Name          string
+	Email         string
+	Phone         string
+	PreferredCity string
+}
+
+type User struct {
+	UUID      uuid.UUID
+	Profile   Profile
+	Status    Status
+	CreatedAt time.Time
+}
+
+func NewUser(userUUID uuid.UUID, profile Profile, createdAt time.Time) *User {
+	return &User{
+		UUID:      userUUID,
+		Profile:   profile,
+		Status:    StatusActive,
+		CreatedAt: createdAt,
+	}
+}
+
+// Active tells whether the user is allowed to use the service.
+func (u *User) Active() bool {
+	return u.Status == StatusActive
+}
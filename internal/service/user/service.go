@@ -0,0 +1,121 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+//go:generate mockgen -source=service.go -destination=mock/service_mock.go -package=mock
+type Service interface {
+	SignUp(ctx context.Context, userUUID uuid.UUID, profile model.Profile) (*model.User, error)
+	GetUser(ctx context.Context, userUUID uuid.UUID) (*model.User, error)
+	UpdateProfile(ctx context.Context, userUUID uuid.UUID, profile model.Profile) (*model.User, error)
+	SetStatus(ctx context.Context, userUUID uuid.UUID, status model.Status) (*model.User, error)
+}
+
+type userService struct {
+	userRepository service.UserRepository
+	now            func() time.Time
+}
+
+func NewUserService(repo service.UserRepository) *userService {
+	return &userService{
+		userRepository: repo,
+		now:            time.Now,
+	}
+}
+
+func (us *userService) SignUp(ctx context.Context, userUUID uuid.UUID, profile model.Profile) (*model.User, error) {
+	user := model.NewUser(userUUID, profile, us.now().UTC())
+
+	if err := us.userRepository.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("creating user: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("Signed up user.", slog.String("user_id", userUUID.String()))
+
+	return user, nil
+}
+
+func (us *userService) GetUser(ctx context.Context, userUUID uuid.UUID) (*model.User, error) {
+	user, err := us.userRepository.GetUser(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (us *userService) UpdateProfile(
+	ctx context.Context,
+	userUUID uuid.UUID,
+	profile model.Profile,
+) (*model.User, error) {
+	return us.updateUser(ctx, userUUID, func(user *model.User) {
+		user.Profile = profile
+	})
+}
+
+func (us *userService) SetStatus(ctx context.Context, userUUID uuid.UUID, status model.Status) (*model.User, error) {
+	return us.updateUser(ctx, userUUID, func(user *model.User) {
+		user.Status = status
+	})
+}
+
+// SeedUsers registers the users listed in the config that are not registered yet. They start active with an empty
+// profile, the already registered ones are left as they are.
+func (us *userService) SeedUsers(ctx context.Context, userUUIDs []uuid.UUID) error {
+	for _, userUUID := range userUUIDs {
+		err := us.userRepository.CreateUser(ctx, model.NewUser(userUUID, model.Profile{}, us.now().UTC()))
+		if err != nil && !errors.Is(err, service.ErrUserAlreadyExists) {
+			return fmt.Errorf("seeding user %s: %w", userUUID, err)
+		}
+	}
+
+	return nil
+}
+
+// SyncSeedUsers applies the changes of the users listed in the config: the added ones are registered or activated,
+// the removed ones are suspended.
+func (us *userService) SyncSeedUsers(ctx context.Context, added, removed []uuid.UUID) error {
+	if err := us.SeedUsers(ctx, added); err != nil {
+		return err
+	}
+
+	for _, userUUID := range added {
+		if _, err := us.SetStatus(ctx, userUUID, model.StatusActive); err != nil {
+			return fmt.Errorf("activating user %s: %w", userUUID, err)
+		}
+	}
+
+	for _, userUUID := range removed {
+		if _, err := us.SetStatus(ctx, userUUID, model.StatusSuspended); err != nil &&
+			!errors.Is(err, service.ErrUserNotFound) {
+			return fmt.Errorf("suspending user %s: %w", userUUID, err)
+		}
+	}
+
+	return nil
+}
+
+func (us *userService) updateUser(
+	ctx context.Context,
+	userUUID uuid.UUID,
+	update func(user *model.User),
+) (*model.User, error) {
+	user, err := us.userRepository.UpdateUser(ctx, userUUID, update)
+	if err != nil {
+		return nil, fmt.Errorf("updating user: %w", err)
+	}
+
+	return user, nil
+}
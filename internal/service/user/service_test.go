@@ -0,0 +1,170 @@
+//go:build unit
+
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	repositorymock "github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+var testNow = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSignUp(t *testing.T) {
+	ctx := context.Background()
+
+	userUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	profile := model.Profile{Name: "Jane Doe", Email: "jane@example.com"}
+	user := model.NewUser(userUUID, profile, testNow)
+
+	tests := map[string]struct {
+		mockRepositoryHandler func(mock *repositorymock.MockUserRepository)
+		want                  *model.User
+		wantErr               error
+	}{
+		"successfully signed up user": {
+			mockRepositoryHandler: func(mock *repositorymock.MockUserRepository) {
+				mock.EXPECT().CreateUser(ctx, user).Return(nil).Times(1)
+			},
+			want:    user,
+			wantErr: nil,
+		},
+		"failed signing up user, because it is already registered": {
+			mockRepositoryHandler: func(mock *repositorymock.MockUserRepository) {
+				mock.EXPECT().CreateUser(ctx, user).Return(service.ErrUserAlreadyExists).Times(1)
+			},
+			want:    nil,
+			wantErr: service.ErrUserAlreadyExists,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockRepository := repositorymock.NewMockUserRepository(controller)
+
+			tt.mockRepositoryHandler(mockRepository)
+
+			us := newTestUserService(mockRepository)
+
+			got, err := us.SignUp(ctx, userUUID, profile)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SignUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSyncSeedUsers(t *testing.T) {
+	ctx := context.Background()
+
+	addedUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	removedUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	suspendedUser := model.NewUser(addedUUID, model.Profile{}, testNow)
+	suspendedUser.Status = model.StatusSuspended
+
+	activeUser := model.NewUser(removedUUID, model.Profile{}, testNow)
+
+	tests := map[string]struct {
+		mockRepositoryHandler func(mock *repositorymock.MockUserRepository)
+		wantErr               error
+	}{
+		"activating the added user and suspending the removed one": {
+			mockRepositoryHandler: func(mock *repositorymock.MockUserRepository) {
+				mock.EXPECT().CreateUser(ctx, gomock.Any()).Return(service.ErrUserAlreadyExists).Times(1)
+				mock.EXPECT().UpdateUser(ctx, addedUUID, gomock.Any()).
+					DoAndReturn(updatedUser(t, suspendedUser, &model.User{
+						UUID:      addedUUID,
+						Status:    model.StatusActive,
+						CreatedAt: testNow,
+					})).Times(1)
+				mock.EXPECT().UpdateUser(ctx, removedUUID, gomock.Any()).
+					DoAndReturn(updatedUser(t, activeUser, &model.User{
+						UUID:      removedUUID,
+						Status:    model.StatusSuspended,
+						CreatedAt: testNow,
+					})).Times(1)
+			},
+			wantErr: nil,
+		},
+		"failed suspending the removed user, because it was changed concurrently": {
+			mockRepositoryHandler: func(mock *repositorymock.MockUserRepository) {
+				mock.EXPECT().CreateUser(ctx, gomock.Any()).Return(service.ErrUserAlreadyExists).Times(1)
+				mock.EXPECT().UpdateUser(ctx, addedUUID, gomock.Any()).
+					DoAndReturn(updatedUser(t, suspendedUser, &model.User{
+						UUID:      addedUUID,
+						Status:    model.StatusActive,
+						CreatedAt: testNow,
+					})).Times(1)
+				mock.EXPECT().UpdateUser(ctx, removedUUID, gomock.Any()).
+					Return(nil, service.ErrUserConflict).Times(1)
+			},
+			wantErr: service.ErrUserConflict,
+		},
+		"failed seeding the added user, because the repository threw an error": {
+			mockRepositoryHandler: func(mock *repositorymock.MockUserRepository) {
+				mock.EXPECT().CreateUser(ctx, gomock.Any()).Return(redis.ErrClosed).Times(1)
+			},
+			wantErr: redis.ErrClosed,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockRepository := repositorymock.NewMockUserRepository(controller)
+
+			tt.mockRepositoryHandler(mockRepository)
+
+			us := newTestUserService(mockRepository)
+
+			err = us.SyncSeedUsers(ctx, []uuid.UUID{addedUUID}, []uuid.UUID{removedUUID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SyncSeedUsers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// updatedUser applies the update to a copy of the stored user, as the repository does, and checks the result.
+func updatedUser(
+	t *testing.T,
+	stored, want *model.User,
+) func(ctx context.Context, userUUID uuid.UUID, update func(user *model.User)) (*model.User, error) {
+	return func(_ context.Context, _ uuid.UUID, update func(user *model.User)) (*model.User, error) {
+		user := *stored
+
+		update(&user)
+
+		require.Equal(t, want, &user)
+
+		return &user, nil
+	}
+}
+
+func newTestUserService(repository *repositorymock.MockUserRepository) *userService {
+	us := NewUserService(repository)
+	us.now = func() time.Time { return testNow }
+
+	return us
+}
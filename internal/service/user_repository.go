@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user with given UUID was not found")
+	ErrUserAlreadyExists = errors.New("user with given UUID already exists")
+	ErrUserConflict      = errors.New("user was changed concurrently")
+)
+
+//go:generate mockgen -source=user_repository.go -destination=mock/user_repository_mock.go -package=mock
+type UserRepository interface {
+	// CreateUser stores the user unless one with the same UUID exists, in which case it returns ErrUserAlreadyExists.
+	CreateUser(ctx context.Context, user *usermodel.User) error
+	// GetUser returns ErrUserNotFound when there is no user with the UUID.
+	GetUser(ctx context.Context, userUUID uuid.UUID) (*usermodel.User, error)
+	// UpdateUser applies the update to the stored user and stores the result in one transaction, so the concurrent
+	// updates of the other fields are not overwritten. It returns ErrUserNotFound when there is no user with the UUID
+	// and ErrUserConflict when the user is changed by another update meanwhile.
+	UpdateUser(
+		ctx context.Context,
+		userUUID uuid.UUID,
+		update func(user *usermodel.User),
+	) (*usermodel.User, error)
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+// errRentalNotSettled is returned for the event of the rental whose change isn't stored yet, as the event is
+// recorded before the rental itself. The event is handled again by the retries of the bus.
+var errRentalNotSettled = errors.New("rental is not stored as of the event yet")
+
+// NewEventHandler returns the handler of the events of the bus, which delivers the starts and the ends of the rentals
+// to the webhooks. The deliveries of an event keep its UUID, so the receivers can recognise the ones delivered again.
+func NewEventHandler(webhooks Service, rentals service.RentalRepository) service.EventHandler {
+	return func(ctx context.Context, event *eventmodel.Event) error {
+		var eventType model.EventType
+
+		switch event.Type {
+		case eventmodel.TypeRentalStarted:
+			eventType = model.EventRentalStarted
+		case eventmodel.TypeRentalEnded:
+			eventType = model.EventRentalEnded
+		default:
+			return nil
+		}
+
+		rental, err := rentals.GetRental(ctx, event.RentalUUID)
+		if errors.Is(err, service.ErrRentalNotFound) {
+			return fmt.Errorf("%w: %w", errRentalNotSettled, err)
+		}
+
+		if err != nil {
+			return fmt.Errorf("getting rental of the event: %w", err)
+		}
+
+		if eventType == model.EventRentalEnded && rental.Active() {
+			return errRentalNotSettled
+		}
+
+		if err = webhooks.Publish(ctx, &model.Event{
+			UUID:       event.UUID,
+			Type:       eventType,
+			OccurredAt: event.OccurredAt,
+			Rental:     rental,
+		}); err != nil {
+			return fmt.Errorf("publishing rental event to webhooks: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+//go:build unit
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	repositorymock "github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook/mock"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+func TestEventHandler(t *testing.T) {
+	ctx := context.Background()
+
+	activeRental := newTestRental(t)
+
+	endedRental := newTestRental(t)
+	endedAt := testNow.Add(time.Hour)
+	endedRental.EndedAt = &endedAt
+
+	newEvent := func(eventType eventmodel.Type, rentalUUID uuid.UUID) *eventmodel.Event {
+		return &eventmodel.Event{
+			UUID:       uuid.New(),
+			Type:       eventType,
+			OccurredAt: testNow,
+			RentalUUID: rentalUUID,
+		}
+	}
+
+	startedEvent := newEvent(eventmodel.TypeRentalStarted, activeRental.UUID)
+	endedEvent := newEvent(eventmodel.TypeRentalEnded, endedRental.UUID)
+	endedTooEarlyEvent := newEvent(eventmodel.TypeRentalEnded, activeRental.UUID)
+	movedEvent := newEvent(eventmodel.TypeScooterMoved, uuid.Nil)
+
+	tests := map[string]struct {
+		event       *eventmodel.Event
+		mockHandler func(rentals *repositorymock.MockRentalRepository, webhooks *mock.MockService)
+		wantErr     error
+	}{
+		"published the start of the rental with the UUID of the event": {
+			event: startedEvent,
+			mockHandler: func(rentals *repositorymock.MockRentalRepository, webhooks *mock.MockService) {
+				rentals.EXPECT().GetRental(ctx, activeRental.UUID).Return(activeRental, nil).Times(1)
+				webhooks.EXPECT().Publish(ctx, &model.Event{
+					UUID:       startedEvent.UUID,
+					Type:       model.EventRentalStarted,
+					OccurredAt: testNow,
+					Rental:     activeRental,
+				}).Return(nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		"published the end of the rental": {
+			event: endedEvent,
+			mockHandler: func(rentals *repositorymock.MockRentalRepository, webhooks *mock.MockService) {
+				rentals.EXPECT().GetRental(ctx, endedRental.UUID).Return(endedRental, nil).Times(1)
+				webhooks.EXPECT().Publish(ctx, &model.Event{
+					UUID:       endedEvent.UUID,
+					Type:       model.EventRentalEnded,
+					OccurredAt: testNow,
+					Rental:     endedRental,
+				}).Return(nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		"ignored the move of the scooter": {
+			event:       movedEvent,
+			mockHandler: func(*repositorymock.MockRentalRepository, *mock.MockService) {},
+			wantErr:     nil,
+		},
+		"published nothing, because the rental isn't stored yet": {
+			event: startedEvent,
+			mockHandler: func(rentals *repositorymock.MockRentalRepository, _ *mock.MockService) {
+				rentals.EXPECT().GetRental(ctx, activeRental.UUID).Return(nil, service.ErrRentalNotFound).Times(1)
+			},
+			wantErr: errRentalNotSettled,
+		},
+		"published nothing, because the end of the rental isn't stored yet": {
+			event: endedTooEarlyEvent,
+			mockHandler: func(rentals *repositorymock.MockRentalRepository, _ *mock.MockService) {
+				rentals.EXPECT().GetRental(ctx, activeRental.UUID).Return(activeRental, nil).Times(1)
+			},
+			wantErr: errRentalNotSettled,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockRentals := repositorymock.NewMockRentalRepository(controller)
+			mockWebhooks := mock.NewMockService(controller)
+
+			tt.mockHandler(mockRentals, mockWebhooks)
+
+			handler := NewEventHandler(mockWebhooks, mockRentals)
+
+			if err := handler(ctx, tt.event); !errors.Is(err, tt.wantErr) {
+				t.Errorf("handler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+type EventType string
+
+const (
+	EventRentalStarted EventType = "rental.started"
+	EventRentalEnded   EventType = "rental.ended"
+)
+
+// Subscription tells where to deliver the events of the types and the secret signing them.
+type Subscription struct {
+	UUID       uuid.UUID
+	URL        string
+	EventTypes []EventType
+	Secret     string
+	CreatedAt  time.Time
+}
+
+func NewSubscription(
+	subscriptionUUID uuid.UUID,
+	url string,
+	eventTypes []EventType,
+	secret string,
+	createdAt time.Time,
+) *Subscription {
+	return &Subscription{
+		UUID:       subscriptionUUID,
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  createdAt,
+	}
+}
+
+// Wants tells whether the subscription is for the events of the type.
+func (s *Subscription) Wants(eventType EventType) bool {
+	return slices.Contains(s.EventTypes, eventType)
+}
+
+// Event is the change of the rental the subscribers are told about. Its UUID is the same in all the deliveries, so
+// the subscribers can recognise the ones they have already handled.
+type Event struct {
+	UUID       uuid.UUID
+	Type       EventType
+	OccurredAt time.Time
+	Rental     *rentalmodel.Rental
+}
+
+// Delivery is the event sent to the subscription. The deliveries that kept failing are kept as dead letters, with
+// the number of attempts made and the last error, until they are replayed.
+type Delivery struct {
+	UUID             uuid.UUID
+	SubscriptionUUID uuid.UUID
+	Event            *Event
+	Attempts         int
+	LastError        string
+	FailedAt         time.Time
+}
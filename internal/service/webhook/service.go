@@ -0,0 +1,458 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+const (
+	contentTypeJSON = "application/json"
+
+	// queueSize is how many deliveries wait for the worker of the subscription before the newer are written to the
+	// dead letters.
+	queueSize = 64
+)
+
+var (
+	ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+	errQueueFull = errors.New("deliveries of the webhook are backed up")
+	errClosed    = errors.New("webhook service is closed")
+)
+
+//go:generate mockgen -source=service.go -destination=mock/service_mock.go -package=mock
+type Service interface {
+	Subscribe(ctx context.Context, url string, eventTypes []model.EventType, secret string) (*model.Subscription, error)
+	GetSubscriptions(ctx context.Context) ([]*model.Subscription, error)
+	Unsubscribe(ctx context.Context, subscriptionUUID uuid.UUID) error
+	// Publish queues the delivery of the event to the subscriptions wanting it, returning without waiting for the
+	// deliveries. The deliveries that fail or don't fit in the queue are written to the dead letters.
+	Publish(ctx context.Context, event *model.Event) error
+	GetDeadLetters(ctx context.Context) ([]*model.Delivery, error)
+	// Replay makes a single attempt to deliver the dead letter again, removing it when it succeeds.
+	Replay(ctx context.Context, deliveryUUID uuid.UUID) error
+}
+
+type webhookService struct {
+	repository service.WebhookRepository
+	client     *http.Client
+	retrier    *resilience.Retrier
+	now        func() time.Time
+	newUUID    func() uuid.UUID
+	// stopped is cancelled by Close to cut the retries of the deliveries in flight short.
+	stopped    context.Context
+	stop       context.CancelFunc
+	deliveries sync.WaitGroup
+
+	mu sync.Mutex
+	// queues holds the deliveries waiting for the worker of each subscription, while the worker runs.
+	queues map[uuid.UUID]chan *queued
+	closed bool
+}
+
+// queued is the delivery waiting for the worker of its subscription.
+type queued struct {
+	ctx          context.Context
+	subscription *model.Subscription
+	event        *model.Event
+}
+
+func NewWebhookService(
+	repository service.WebhookRepository,
+	client *http.Client,
+	retrier *resilience.Retrier,
+) *webhookService {
+	stopped, stop := context.WithCancel(context.Background())
+
+	return &webhookService{
+		repository: repository,
+		client:     client,
+		retrier:    retrier,
+		now:        time.Now,
+		newUUID:    uuid.New,
+		stopped:    stopped,
+		stop:       stop,
+		queues:     make(map[uuid.UUID]chan *queued),
+	}
+}
+
+// payload is the body of the delivery.
+type payload struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       model.EventType `json:"type"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       rentalData      `json:"data"`
+}
+
+type rentalData struct {
+	RentalUUID     uuid.UUID  `json:"rentalUUID"`
+	UserUUID       uuid.UUID  `json:"userUUID"`
+	ScooterUUID    uuid.UUID  `json:"scooterUUID"`
+	City           string     `json:"city"`
+	StartLongitude float64    `json:"startLongitude"`
+	StartLatitude  float64    `json:"startLatitude"`
+	StartedAt      time.Time  `json:"startedAt"`
+	EndedAt        *time.Time `json:"endedAt,omitempty"`
+}
+
+// statusError is returned for the deliveries the receiver answered with a status other than 2xx.
+type statusError struct {
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("receiver answered with %d %s", e.statusCode, http.StatusText(e.statusCode))
+}
+
+func (ws *webhookService) Subscribe(
+	ctx context.Context,
+	url string,
+	eventTypes []model.EventType,
+	secret string,
+) (*model.Subscription, error) {
+	subscription := model.NewSubscription(ws.newUUID(), url, eventTypes, secret, ws.now().UTC())
+
+	if err := ws.repository.CreateSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("creating webhook subscription: %w", err)
+	}
+
+	logging.FromContext(ctx).Info(
+		"Created webhook subscription.",
+		slog.String("webhook_id", subscription.UUID.String()),
+		slog.String("url", subscription.URL),
+	)
+
+	return subscription, nil
+}
+
+func (ws *webhookService) GetSubscriptions(ctx context.Context) ([]*model.Subscription, error) {
+	subscriptions, err := ws.repository.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (ws *webhookService) Unsubscribe(ctx context.Context, subscriptionUUID uuid.UUID) error {
+	if err := ws.repository.DeleteSubscription(ctx, subscriptionUUID); err != nil {
+		return fmt.Errorf("deleting webhook subscription: %w", err)
+	}
+
+	logging.FromContext(ctx).Info(
+		"Deleted webhook subscription.",
+		slog.String("webhook_id", subscriptionUUID.String()),
+	)
+
+	return nil
+}
+
+// Publish queues the delivery of the event to every subscription, so the subscription slow to answer holds up
+// neither the others nor the consumer of the event. The deliveries of a subscription are made one at a time, in the
+// order of the events. The delivery that doesn't fit in the queue of the subscription is written to the dead letters
+// right away. The deliveries keep the logger of the given context, but are not cancelled with it.
+func (ws *webhookService) Publish(ctx context.Context, event *model.Event) error {
+	subscriptions, err := ws.repository.GetSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("getting webhook subscriptions: %w", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event.Type) {
+			continue
+		}
+
+		if err = ws.enqueue(&queued{ctx: ctx, subscription: subscription, event: event}); err != nil {
+			ws.saveDeadLetter(ctx, subscription, event, 0, err)
+		}
+	}
+
+	return nil
+}
+
+func (ws *webhookService) GetDeadLetters(ctx context.Context) ([]*model.Delivery, error) {
+	deliveries, err := ws.repository.GetDeadLetters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting dead letters: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Replay sends the dead letter to the subscription once more with the same event UUID. A failed replay updates the
+// dead letter with the error and the count of the attempts.
+func (ws *webhookService) Replay(ctx context.Context, deliveryUUID uuid.UUID) error {
+	delivery, err := ws.repository.GetDeadLetter(ctx, deliveryUUID)
+	if err != nil {
+		return fmt.Errorf("getting dead letter: %w", err)
+	}
+
+	subscription, err := ws.repository.GetSubscription(ctx, delivery.SubscriptionUUID)
+	if err != nil {
+		return fmt.Errorf("getting webhook subscription of the dead letter: %w", err)
+	}
+
+	delivery.Attempts++
+
+	if deliverErr := ws.deliver(ctx, subscription, delivery.Event); deliverErr != nil {
+		delivery.LastError = deliverErr.Error()
+		delivery.FailedAt = ws.now().UTC()
+
+		if err = ws.repository.SaveDeadLetter(ctx, delivery); err != nil {
+			return fmt.Errorf("updating dead letter: %w", err)
+		}
+
+		return fmt.Errorf("%w: %v", ErrDeliveryFailed, deliverErr)
+	}
+
+	if err = ws.repository.DeleteDeadLetter(ctx, deliveryUUID); err != nil {
+		return fmt.Errorf("deleting replayed dead letter: %w", err)
+	}
+
+	logging.FromContext(ctx).Info(
+		"Replayed webhook delivery.",
+		slog.String("delivery_id", deliveryUUID.String()),
+		slog.String("webhook_id", subscription.UUID.String()),
+	)
+
+	return nil
+}
+
+// Close cuts the retries of the deliveries in flight short, so the failing ones are written to the dead letters
+// right away, as are the deliveries still queued and the ones published afterwards, and waits for them to finish.
+func (ws *webhookService) Close() {
+	ws.mu.Lock()
+	ws.closed = true
+	ws.mu.Unlock()
+
+	ws.stop()
+	ws.deliveries.Wait()
+}
+
+// enqueue adds the delivery to the queue of its subscription, starting the worker of the subscription unless it
+// runs. It returns errQueueFull when the queue is full.
+func (ws *webhookService) enqueue(delivery *queued) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.closed {
+		return errClosed
+	}
+
+	queue, ok := ws.queues[delivery.subscription.UUID]
+	if !ok {
+		queue = make(chan *queued, queueSize)
+		ws.queues[delivery.subscription.UUID] = queue
+
+		ws.deliveries.Add(1)
+
+		go ws.work(delivery.subscription.UUID, queue)
+	}
+
+	select {
+	case queue <- delivery:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// work makes the queued deliveries of the subscription until its queue is empty. The deliveries queued after Close
+// are written to the dead letters without being attempted.
+func (ws *webhookService) work(subscriptionUUID uuid.UUID, queue chan *queued) {
+	defer ws.deliveries.Done()
+
+	for {
+		delivery, ok := ws.next(subscriptionUUID, queue)
+		if !ok {
+			return
+		}
+
+		if ws.stopped.Err() != nil {
+			ws.saveDeadLetter(delivery.ctx, delivery.subscription, delivery.event, 0, errClosed)
+
+			continue
+		}
+
+		ws.deliverWithRetries(delivery.ctx, delivery.subscription, delivery.event)
+	}
+}
+
+// next takes the next delivery from the queue of the subscription. The empty queue is removed, so the worker stops
+// and the next delivery of the subscription starts a new one.
+func (ws *webhookService) next(subscriptionUUID uuid.UUID, queue chan *queued) (*queued, bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case delivery := <-queue:
+		return delivery, true
+	default:
+		delete(ws.queues, subscriptionUUID)
+
+		return nil, false
+	}
+}
+
+// deliverWithRetries delivers the event to the subscription, retrying the failures the receiver may recover from.
+// The delivery that keeps failing is written to the dead letters.
+func (ws *webhookService) deliverWithRetries(
+	ctx context.Context,
+	subscription *model.Subscription,
+	event *model.Event,
+) {
+	logger := logging.FromContext(ctx).With(
+		slog.String("event_id", event.UUID.String()),
+		slog.String("webhook_id", subscription.UUID.String()),
+	)
+
+	// Close cancels only the waits between the attempts, the attempt in flight is bounded by the client timeout.
+	retryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stopCancelling := context.AfterFunc(ws.stopped, cancel)
+	defer stopCancelling()
+
+	var (
+		attempts int
+		lastErr  error
+	)
+
+	err := ws.retrier.Do(retryCtx, func(context.Context) error {
+		attempts++
+		lastErr = ws.deliver(ctx, subscription, event)
+
+		return lastErr
+	}, isRetryable)
+	if err == nil {
+		logger.Debug("Delivered webhook.", slog.Int("attempts", attempts))
+
+		return
+	}
+
+	ws.saveDeadLetter(ctx, subscription, event, attempts, lastErr)
+}
+
+// saveDeadLetter writes the delivery that failed after the attempts to the dead letters.
+func (ws *webhookService) saveDeadLetter(
+	ctx context.Context,
+	subscription *model.Subscription,
+	event *model.Event,
+	attempts int,
+	lastErr error,
+) {
+	logger := logging.FromContext(ctx).With(
+		slog.String("event_id", event.UUID.String()),
+		slog.String("webhook_id", subscription.UUID.String()),
+	)
+
+	delivery := &model.Delivery{
+		UUID:             ws.newUUID(),
+		SubscriptionUUID: subscription.UUID,
+		Event:            event,
+		Attempts:         attempts,
+		LastError:        lastErr.Error(),
+		FailedAt:         ws.now().UTC(),
+	}
+
+	if saveErr := ws.repository.SaveDeadLetter(ctx, delivery); saveErr != nil {
+		logger.Error(
+			"Failed to save the dead letter of the webhook delivery, the event is dropped.",
+			slog.Any("err", saveErr),
+		)
+
+		return
+	}
+
+	logger.Warn(
+		"Webhook delivery failed, written to the dead letters.",
+		slog.String("delivery_id", delivery.UUID.String()),
+		slog.Int("attempts", attempts),
+		slog.Any("err", lastErr),
+	)
+}
+
+// deliver makes a single attempt to post the signed event to the subscription.
+func (ws *webhookService) deliver(ctx context.Context, subscription *model.Subscription, event *model.Event) error {
+	body, err := json.Marshal(newPayload(event))
+	if err != nil {
+		return fmt.Errorf("marshaling webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+
+	timestamp := ws.now().Unix()
+
+	req.Header.Set("Content-Type", contentTypeJSON)
+	req.Header.Set(HeaderID, event.UUID.String())
+	req.Header.Set(HeaderEvent, string(event.Type))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// The body is drained, so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &statusError{statusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// isRetryable tells whether the receiver may accept the delivery later. The network failures, the 5xx statuses and
+// the 429 status are retried, while the other statuses mean the request itself is not accepted.
+func isRetryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= http.StatusInternalServerError ||
+			statusErr.statusCode == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+func newPayload(event *model.Event) *payload {
+	return &payload{
+		ID:         event.UUID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data: rentalData{
+			RentalUUID:     event.Rental.UUID,
+			UserUUID:       event.Rental.UserUUID,
+			ScooterUUID:    event.Rental.ScooterUUID,
+			City:           event.Rental.City,
+			StartLongitude: event.Rental.StartLongitude,
+			StartLatitude:  event.Rental.StartLatitude,
+			StartedAt:      event.Rental.StartedAt,
+			EndedAt:        event.Rental.EndedAt,
+		},
+	}
+}
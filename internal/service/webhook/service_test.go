@@ -0,0 +1,470 @@
+//go:build unit
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	repositorymock "github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+const (
+	testSecret   = "0123456789abcdef"
+	testAttempts = 3
+)
+
+var testNow = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+// receiver is the endpoint of the partner answering the deliveries with the given statuses in turn. It rejects
+// the deliveries that are not signed with the test secret.
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	payloads []payload
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rc.t, err)
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(rc.t, err)
+
+	if r.Header.Get(HeaderSignature) != Sign(testSecret, timestamp, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	var received payload
+
+	require.NoError(rc.t, json.Unmarshal(body, &received))
+	require.Equal(rc.t, received.ID.String(), r.Header.Get(HeaderID))
+	require.Equal(rc.t, string(received.Type), r.Header.Get(HeaderEvent))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	status := http.StatusOK
+	if len(rc.payloads) < len(rc.statuses) {
+		status = rc.statuses[len(rc.payloads)]
+	}
+
+	rc.payloads = append(rc.payloads, received)
+
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) received() []payload {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return rc.payloads
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+
+	rental := newTestRental(t)
+
+	event := &model.Event{
+		UUID:       uuid.New(),
+		Type:       model.EventRentalStarted,
+		OccurredAt: testNow,
+		Rental:     rental,
+	}
+
+	tests := map[string]struct {
+		eventTypes       []model.EventType
+		secret           string
+		subscriptionsErr error
+		statuses         []int
+		wantDeliveries   int
+		wantDeadLetter   bool
+		wantErr          bool
+	}{
+		"successfully delivered event at the first attempt": {
+			eventTypes:     []model.EventType{model.EventRentalStarted},
+			secret:         testSecret,
+			statuses:       []int{http.StatusNoContent},
+			wantDeliveries: 1,
+			wantDeadLetter: false,
+		},
+		"successfully delivered event after the receiver recovered": {
+			eventTypes:     []model.EventType{model.EventRentalStarted, model.EventRentalEnded},
+			secret:         testSecret,
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			wantDeliveries: 3,
+			wantDeadLetter: false,
+		},
+		"skipped subscription not wanting the event": {
+			eventTypes:     []model.EventType{model.EventRentalEnded},
+			secret:         testSecret,
+			statuses:       nil,
+			wantDeliveries: 0,
+			wantDeadLetter: false,
+		},
+		"failed delivering event, because the attempts were used up": {
+			eventTypes:     []model.EventType{model.EventRentalStarted},
+			secret:         testSecret,
+			statuses:       []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusInternalServerError},
+			wantDeliveries: testAttempts,
+			wantDeadLetter: true,
+		},
+		"failed delivering event without retrying, because the receiver rejected the signature": {
+			eventTypes:     []model.EventType{model.EventRentalStarted},
+			secret:         "fedcba9876543210",
+			statuses:       nil,
+			wantDeliveries: 0,
+			wantDeadLetter: true,
+		},
+		"failed publishing event, because the subscriptions couldn't be read": {
+			eventTypes:       []model.EventType{model.EventRentalStarted},
+			secret:           testSecret,
+			subscriptionsErr: errors.New("redis down"),
+			statuses:         nil,
+			wantDeliveries:   0,
+			wantDeadLetter:   false,
+			wantErr:          true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			rc := &receiver{t: t, statuses: tt.statuses}
+
+			server := httptest.NewServer(rc)
+			defer server.Close()
+
+			subscription := model.NewSubscription(uuid.New(), server.URL, tt.eventTypes, tt.secret, testNow)
+
+			mockRepository := repositorymock.NewMockWebhookRepository(controller)
+			mockRepository.EXPECT().GetSubscriptions(gomock.Any()).
+				Return([]*model.Subscription{subscription}, tt.subscriptionsErr).Times(1)
+
+			var deadLetter *model.Delivery
+
+			if tt.wantDeadLetter {
+				mockRepository.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, delivery *model.Delivery) error {
+						deadLetter = delivery
+
+						return nil
+					}).Times(1)
+			}
+
+			ws := newTestWebhookService(mockRepository)
+
+			if err := ws.Publish(ctx, event); (err != nil) != tt.wantErr {
+				t.Errorf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// the workers stop once the queues are empty
+			ws.deliveries.Wait()
+
+			received := rc.received()
+			require.Len(t, received, tt.wantDeliveries)
+
+			for _, got := range received {
+				require.Equal(t, event.UUID, got.ID)
+				require.Equal(t, model.EventRentalStarted, got.Type)
+				require.Equal(t, rental.UUID, got.Data.RentalUUID)
+				require.Equal(t, rental.ScooterUUID, got.Data.ScooterUUID)
+			}
+
+			if tt.wantDeadLetter {
+				require.Equal(t, subscription.UUID, deadLetter.SubscriptionUUID)
+				require.Equal(t, rental, deadLetter.Event.Rental)
+				require.Equal(t, max(tt.wantDeliveries, 1), deadLetter.Attempts)
+				require.NotEmpty(t, deadLetter.LastError)
+			}
+		})
+	}
+}
+
+// TestPublishToStalledReceiver publishes the events to the receiver that doesn't answer until it is released, next to
+// the one answering right away.
+func TestPublishToStalledReceiver(t *testing.T) {
+	ctx := context.Background()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	releaseStalled := sync.OnceFunc(func() { close(release) })
+
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+
+		<-release
+	}))
+	defer stalled.Close()
+	defer releaseStalled()
+
+	rc := &receiver{t: t}
+
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	stalledSubscription := model.NewSubscription(
+		uuid.New(),
+		stalled.URL,
+		[]model.EventType{model.EventRentalStarted, model.EventRentalEnded},
+		testSecret,
+		testNow,
+	)
+	subscription := model.NewSubscription(
+		uuid.New(),
+		server.URL,
+		[]model.EventType{model.EventRentalStarted},
+		testSecret,
+		testNow,
+	)
+
+	mockRepository := repositorymock.NewMockWebhookRepository(controller)
+	mockRepository.EXPECT().GetSubscriptions(gomock.Any()).
+		Return([]*model.Subscription{stalledSubscription, subscription}, nil).Times(queueSize + 2)
+
+	var deadLetters []*model.Delivery
+
+	mockRepository.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, delivery *model.Delivery) error {
+			deadLetters = append(deadLetters, delivery)
+
+			return nil
+		}).Times(1)
+
+	ws := newTestWebhookService(mockRepository)
+
+	started := newTestEvent(t)
+
+	require.NoError(t, ws.Publish(ctx, started))
+
+	// the first delivery is taken from the queue by the worker of the stalled subscription
+	select {
+	case <-arrived:
+	case <-time.After(time.Second):
+		t.Fatal("Publish() did not deliver to the stalled receiver")
+	}
+
+	// the other subscription is not held up by the stalled one
+	require.Eventually(t, func() bool {
+		return len(rc.received()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	for i := 0; i < queueSize; i++ {
+		ended := newTestEvent(t)
+		ended.Type = model.EventRentalEnded
+
+		require.NoError(t, ws.Publish(ctx, ended))
+	}
+
+	overflow := newTestEvent(t)
+	overflow.Type = model.EventRentalEnded
+
+	require.NoError(t, ws.Publish(ctx, overflow))
+
+	// the delivery that didn't fit in the queue of the stalled subscription is given up on without waiting
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, stalledSubscription.UUID, deadLetters[0].SubscriptionUUID)
+	require.Equal(t, overflow.UUID, deadLetters[0].Event.UUID)
+	require.Equal(t, 0, deadLetters[0].Attempts)
+	require.Equal(t, errQueueFull.Error(), deadLetters[0].LastError)
+
+	releaseStalled()
+
+	ws.deliveries.Wait()
+
+	require.Equal(t, started.UUID, rc.received()[0].ID)
+}
+
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	rc := &receiver{t: t}
+
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	subscription := model.NewSubscription(
+		uuid.New(),
+		server.URL,
+		[]model.EventType{model.EventRentalStarted},
+		testSecret,
+		testNow,
+	)
+
+	mockRepository := repositorymock.NewMockWebhookRepository(controller)
+	mockRepository.EXPECT().GetSubscriptions(gomock.Any()).Return([]*model.Subscription{subscription}, nil).Times(1)
+	mockRepository.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, delivery *model.Delivery) error {
+			require.Equal(t, 0, delivery.Attempts)
+			require.Equal(t, errClosed.Error(), delivery.LastError)
+
+			return nil
+		}).Times(1)
+
+	ws := newTestWebhookService(mockRepository)
+	ws.Close()
+
+	// the event published after the close is written to the dead letters, to be replayed after the restart
+	require.NoError(t, ws.Publish(ctx, newTestEvent(t)))
+	require.Empty(t, rc.received())
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		status             int
+		deadLetterErr      error
+		wantDeletedOrSaved bool
+		wantErr            error
+	}{
+		"successfully replayed dead letter": {
+			status:             http.StatusOK,
+			deadLetterErr:      nil,
+			wantDeletedOrSaved: true,
+			wantErr:            nil,
+		},
+		"failed replaying dead letter, because the receiver is still failing": {
+			status:             http.StatusServiceUnavailable,
+			deadLetterErr:      nil,
+			wantDeletedOrSaved: true,
+			wantErr:            ErrDeliveryFailed,
+		},
+		"failed replaying dead letter, because it does not exist": {
+			status:             http.StatusOK,
+			deadLetterErr:      service.ErrDeadLetterNotFound,
+			wantDeletedOrSaved: false,
+			wantErr:            service.ErrDeadLetterNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			rc := &receiver{t: t, statuses: []int{tt.status}}
+
+			server := httptest.NewServer(rc)
+			defer server.Close()
+
+			subscription := model.NewSubscription(
+				uuid.New(),
+				server.URL,
+				[]model.EventType{model.EventRentalEnded},
+				testSecret,
+				testNow,
+			)
+			delivery := &model.Delivery{
+				UUID:             uuid.New(),
+				SubscriptionUUID: subscription.UUID,
+				Event: &model.Event{
+					UUID:       uuid.New(),
+					Type:       model.EventRentalEnded,
+					OccurredAt: testNow,
+					Rental:     newTestRental(t),
+				},
+				Attempts:  testAttempts,
+				LastError: "receiver answered with 500 Internal Server Error",
+				FailedAt:  testNow,
+			}
+
+			mockRepository := repositorymock.NewMockWebhookRepository(controller)
+
+			if tt.deadLetterErr != nil {
+				mockRepository.EXPECT().GetDeadLetter(ctx, delivery.UUID).Return(nil, tt.deadLetterErr).Times(1)
+			} else {
+				mockRepository.EXPECT().GetDeadLetter(ctx, delivery.UUID).Return(delivery, nil).Times(1)
+				mockRepository.EXPECT().GetSubscription(ctx, subscription.UUID).Return(subscription, nil).Times(1)
+			}
+
+			if tt.wantDeletedOrSaved && tt.wantErr == nil {
+				mockRepository.EXPECT().DeleteDeadLetter(ctx, delivery.UUID).Return(nil).Times(1)
+			}
+
+			if tt.wantDeletedOrSaved && tt.wantErr != nil {
+				mockRepository.EXPECT().SaveDeadLetter(ctx, delivery).Return(nil).Times(1)
+			}
+
+			ws := newTestWebhookService(mockRepository)
+
+			if err := ws.Replay(ctx, delivery.UUID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Replay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil && tt.wantDeletedOrSaved {
+				require.Equal(t, testAttempts+1, delivery.Attempts)
+				require.Contains(t, delivery.LastError, "503")
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+
+	signature := Sign(testSecret, 1714564800, body)
+
+	require.Equal(t, signature, Sign(testSecret, 1714564800, body))
+	require.NotEqual(t, signature, Sign(testSecret, 1714564801, body))
+	require.NotEqual(t, signature, Sign("fedcba9876543210", 1714564800, body))
+	require.Regexp(t, "^v1=[0-9a-f]{64}$", signature)
+}
+
+func newTestWebhookService(repository *repositorymock.MockWebhookRepository) *webhookService {
+	ws := NewWebhookService(repository, http.DefaultClient, resilience.NewRetrier(testAttempts, 0, 0))
+	ws.now = func() time.Time { return testNow }
+
+	return ws
+}
+
+func newTestEvent(t *testing.T) *model.Event {
+	t.Helper()
+
+	return &model.Event{
+		UUID:       uuid.New(),
+		Type:       model.EventRentalStarted,
+		OccurredAt: testNow,
+		Rental:     newTestRental(t),
+	}
+}
+
+func newTestRental(t *testing.T) *rentalmodel.Rental {
+	t.Helper()
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), "Montreal", 70.0, 60.0)
+
+	return rentalmodel.NewRental(uuid.New(), uuid.New(), scooterUUID, rentInfo, testNow)
+}
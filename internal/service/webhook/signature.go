@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers of the deliveries. The receivers verify the signature by computing Sign with their secret over the
+// timestamp and the raw body, and reject the stale timestamps to stop replayed requests.
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+
+	signatureVersion = "v1"
+)
+
+// Sign returns the signature of the body sent at the timestamp (in Unix seconds), the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the secret of the subscription, prefixed with the version of the scheme.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	webhookmodel "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+)
+
+var (
+	ErrWebhookNotFound    = errors.New("webhook subscription with given UUID was not found")
+	ErrDeadLetterNotFound = errors.New("dead letter with given UUID was not found")
+)
+
+//go:generate mockgen -source=webhook_repository.go -destination=mock/webhook_repository_mock.go -package=mock
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *webhookmodel.Subscription) error
+	// GetSubscription returns ErrWebhookNotFound when there is no subscription with the UUID.
+	GetSubscription(ctx context.Context, subscriptionUUID uuid.UUID) (*webhookmodel.Subscription, error)
+	GetSubscriptions(ctx context.Context) ([]*webhookmodel.Subscription, error)
+	// DeleteSubscription returns ErrWebhookNotFound when there is no subscription with the UUID.
+	DeleteSubscription(ctx context.Context, subscriptionUUID uuid.UUID) error
+	// SaveDeadLetter stores the failed delivery, replacing the one with the same UUID.
+	SaveDeadLetter(ctx context.Context, delivery *webhookmodel.Delivery) error
+	// GetDeadLetter returns ErrDeadLetterNotFound when there is no dead letter with the UUID.
+	GetDeadLetter(ctx context.Context, deliveryUUID uuid.UUID) (*webhookmodel.Delivery, error)
+	// GetDeadLetters returns the dead letters, the latest failed first.
+	GetDeadLetters(ctx context.Context) ([]*webhookmodel.Delivery, error)
+	DeleteDeadLetter(ctx context.Context, deliveryUUID uuid.UUID) error
+}
@@ -0,0 +1,247 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/telemetry"
+)
+
+const (
+	// sharePrefix starts the filters of the shared subscriptions, whose messages are handed to one of the clients
+	// of the group only.
+	sharePrefix = "$share"
+	// disconnectQuiesce is how long in milliseconds the client waits for the work in flight when it disconnects.
+	disconnectQuiesce = 250
+)
+
+var ErrNotConnected = errors.New("mqtt client is not connected to the broker")
+
+// Options configure the client, the topics hold the {scooterID} level standing for the ID of the scooter.
+type Options struct {
+	Broker         string
+	ClientID       string
+	Username       string
+	Password       string
+	TelemetryTopic string
+	TelemetryQoS   byte
+	CommandTopic   string
+	CommandQoS     byte
+	// ShareGroup is the group of the shared subscription of the telemetry, empty to have every instance handle all
+	// the reports.
+	ShareGroup string
+	// Timeout bounds connecting to the broker, handling a report and publishing a command.
+	Timeout time.Duration
+}
+
+// Client talks to the scooters through the MQTT broker, run next to the HTTP server. It feeds the telemetry the
+// scooters publish to the tracker and publishes the commands to the scooters.
+type Client struct {
+	logger         *slog.Logger
+	validator      *validator.Validate
+	trackerService tracker.Service
+	client         paho.Client
+	telemetryTopic *topic
+	telemetryQoS   byte
+	commandTopic   *topic
+	commandQoS     byte
+	shareGroup     string
+	timeout        time.Duration
+	ctx            context.Context
+	cancel         context.CancelFunc
+}
+
+func NewClient(
+	logger *slog.Logger,
+	validator *validator.Validate,
+	tracker tracker.Service,
+	opts *Options,
+) *Client {
+	ctx, cancel := context.WithCancel(logging.WithLogger(context.Background(), logger))
+
+	c := &Client{
+		logger:         logger,
+		validator:      validator,
+		trackerService: tracker,
+		telemetryTopic: newTopic(opts.TelemetryTopic),
+		telemetryQoS:   opts.TelemetryQoS,
+		commandTopic:   newTopic(opts.CommandTopic),
+		commandQoS:     opts.CommandQoS,
+		shareGroup:     opts.ShareGroup,
+		timeout:        opts.Timeout,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	// The session is kept by the broker between the connections, so the reports published with QoS above 0 while
+	// the client is away are handed to it after it connects again.
+	c.client = paho.NewClient(paho.NewClientOptions().
+		AddBroker(opts.Broker).
+		SetClientID(opts.ClientID).
+		SetUsername(opts.Username).
+		SetPassword(opts.Password).
+		SetCleanSession(false).
+		SetConnectTimeout(opts.Timeout).
+		SetConnectRetry(true).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(c.onConnect).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			logger.Warn("Lost connection to the mqtt broker.", slog.Any("err", err))
+		}),
+	)
+
+	return c
+}
+
+// Serve connects to the broker, retrying until it succeeds, and handles the telemetry until the client is shut down.
+func (c *Client) Serve() error {
+	c.logger.Info("Connecting to the mqtt broker.")
+
+	token := c.client.Connect()
+
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("connecting to mqtt broker: %w", err)
+		}
+	case <-c.ctx.Done():
+		return nil
+	}
+
+	<-c.ctx.Done()
+
+	return nil
+}
+
+// Shutdown disconnects from the broker, letting the report being handled finish.
+func (c *Client) Shutdown() {
+	c.cancel()
+
+	c.client.Disconnect(disconnectQuiesce)
+
+	c.logger.Info("Disconnected from the mqtt broker.")
+}
+
+// HealthCheck reports the client as unhealthy while it is not connected to the broker.
+func (c *Client) HealthCheck(_ context.Context) error {
+	if !c.client.IsConnectionOpen() {
+		return ErrNotConnected
+	}
+
+	return nil
+}
+
+// PublishCommand publishes the command to the command topic of the scooter, waiting for the broker to take it over
+// as of the QoS.
+func (c *Client) PublishCommand(ctx context.Context, scooterUUID uuid.UUID, command *Command) error {
+	payload, err := json.Marshal(command)
+	if err != nil {
+		return fmt.Errorf("marshaling command: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	token := c.client.Publish(c.commandTopic.forScooter(scooterUUID), c.commandQoS, false, payload)
+
+	select {
+	case <-token.Done():
+		if err = token.Error(); err != nil {
+			return fmt.Errorf("publishing command: %w", err)
+		}
+	case <-ctx.Done():
+		return fmt.Errorf("publishing command: %w", ctx.Err())
+	}
+
+	logging.FromContext(ctx).Debug(
+		"Published command to the scooter.",
+		slog.String("scooter_id", scooterUUID.String()),
+		slog.String("command", string(command.Type)),
+	)
+
+	return nil
+}
+
+// onConnect subscribes to the telemetry again on every connection, as the broker may have lost the session.
+func (c *Client) onConnect(client paho.Client) {
+	filter := c.telemetryTopic.filter()
+	if c.shareGroup != "" {
+		filter = fmt.Sprintf("%s/%s/%s", sharePrefix, c.shareGroup, filter)
+	}
+
+	c.logger.Info("Connected to the mqtt broker.", slog.String("filter", filter))
+
+	token := client.Subscribe(filter, c.telemetryQoS, c.handleTelemetry)
+
+	go func() {
+		<-token.Done()
+
+		if err := token.Error(); err != nil {
+			c.logger.Error("failed to subscribe to telemetry", slog.Any("err", err))
+		}
+	}()
+}
+
+// handleTelemetry ingests the batch of the readings the scooter published, in the shape of the REST one. The
+// reports that can't be read are dropped, as publishing them again wouldn't help. The reports failing to be ingested
+// are dropped too, even when the failure is transient, as the message is acknowledged once the handler returns and
+// the broker would hand the unacknowledged one again only after the client reconnects.
+func (c *Client) handleTelemetry(_ paho.Client, message paho.Message) {
+	ctxLogger := c.logger.With(slog.String("topic", message.Topic()))
+
+	scooterUUID, ok := c.telemetryTopic.scooterUUID(message.Topic())
+	if !ok {
+		ctxLogger.Warn("Dropped telemetry from unknown topic.")
+
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("scooter_id", scooterUUID.String()))
+
+	var batch telemetry.Batch
+
+	if err := json.Unmarshal(message.Payload(), &batch); err != nil {
+		ctxLogger.Warn("Dropped malformed telemetry.", slog.Any("err", err))
+
+		return
+	}
+
+	if err := c.validator.Struct(batch); err != nil {
+		ctxLogger.Warn("Dropped invalid telemetry.", slog.Any("err", err))
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logging.WithLogger(c.ctx, ctxLogger), c.timeout)
+	defer cancel()
+
+	accepted, err := c.trackerService.IngestTelemetry(ctx, scooterUUID, batch.ToTracker())
+	if errors.Is(err, tracker.ErrTelemetryOutOfOrder) || errors.Is(err, tracker.ErrTelemetryInFuture) ||
+		errors.Is(err, tracker.ErrDeviceTrackingDisabled) {
+		ctxLogger.Warn("Dropped rejected telemetry.", slog.Any("err", err))
+
+		return
+	}
+
+	if err != nil {
+		ctxLogger.Error("failed to ingest telemetry", slog.Any("err", err))
+
+		return
+	}
+
+	ctxLogger.Debug(
+		"Ingested telemetry.",
+		slog.Int("accepted", accepted),
+		slog.Int("duplicates", len(batch.Readings)-accepted),
+	)
+}
@@ -0,0 +1,245 @@
+//go:build unit
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/stretchr/testify/require"
+
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	mocktracker "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/mock"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/api"
+)
+
+const (
+	testTelemetryTopic = "scooters/{scooterID}/telemetry"
+	testCommandTopic   = "scooters/{scooterID}/commands"
+	testShareGroup     = "scooter-rental"
+
+	testWait = 5 * time.Second
+)
+
+var testReadAt = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+func TestHandleTelemetry(t *testing.T) {
+	scooterUUID := uuid.New()
+	topic := newTopic(testTelemetryTopic).forScooter(scooterUUID)
+
+	validPayload := `{"readings":[{"longitude":70,"latitude":60,"timestamp":"2024-05-01T12:00:00Z",` +
+		`"speed":12.5,"battery":80,"locked":false}]}`
+	wantReadings := []*trackermodel.Telemetry{{
+		Longitude: 70,
+		Latitude:  60,
+		At:        testReadAt,
+		Speed:     12.5,
+		Battery:   80,
+		Locked:    false,
+	}}
+
+	tests := map[string]struct {
+		// payloads are published before the valid one, which is waited for, so the ones dropped are known to be
+		// handled by then.
+		payloads []string
+	}{
+		"ingested telemetry": {
+			payloads: nil,
+		},
+		"dropped malformed telemetry": {
+			payloads: []string{`{"readings":`},
+		},
+		"dropped invalid telemetry": {
+			payloads: []string{
+				`{"readings":[]}`,
+				`{"readings":[{"longitude":200,"latitude":60,"timestamp":"2024-05-01T12:00:00Z",` +
+					`"speed":12.5,"battery":80,"locked":false}]}`,
+				`{"readings":[{"longitude":70,"latitude":60,"timestamp":"2024-05-01T12:00:00Z"}]}`,
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			ingested := make(chan struct{})
+
+			trackerMock := mocktracker.NewMockService(ctrl)
+			trackerMock.EXPECT().IngestTelemetry(gomock.Any(), scooterUUID, wantReadings).
+				DoAndReturn(func(context.Context, uuid.UUID, []*trackermodel.Telemetry) (int, error) {
+					close(ingested)
+
+					return 1, nil
+				}).Times(1)
+
+			broker := newTestBroker(t)
+			client := newTestClient(t, broker, trackerMock)
+
+			for _, payload := range append(tt.payloads, validPayload) {
+				require.NoError(t, broker.server.Publish(topic, []byte(payload), false, 1))
+			}
+
+			select {
+			case <-ingested:
+			case <-time.After(testWait):
+				t.Fatal("telemetry was not ingested")
+			}
+
+			client.Shutdown()
+		})
+	}
+}
+
+func TestCommandHandler(t *testing.T) {
+	scooterUUID := uuid.New()
+	rentalUUID := uuid.New()
+	eventUUID := uuid.New()
+
+	tests := map[string]struct {
+		eventType eventmodel.Type
+		want      *Command
+	}{
+		"unlocked rented scooter": {
+			eventType: eventmodel.TypeRentalStarted,
+			want: &Command{
+				ID:         eventUUID,
+				Type:       CommandUnlock,
+				RentalUUID: rentalUUID,
+				IssuedAt:   testReadAt,
+			},
+		},
+		"locked freed scooter": {
+			eventType: eventmodel.TypeRentalEnded,
+			want: &Command{
+				ID:         eventUUID,
+				Type:       CommandLock,
+				RentalUUID: rentalUUID,
+				IssuedAt:   testReadAt,
+			},
+		},
+		"ignored scooter's move": {
+			eventType: eventmodel.TypeScooterMoved,
+			want:      nil,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			broker := newTestBroker(t)
+			client := newTestClient(t, broker, mocktracker.NewMockService(ctrl))
+
+			commands := make(chan *Command, 1)
+
+			require.NoError(t, broker.server.Subscribe(
+				newTopic(testCommandTopic).forScooter(scooterUUID),
+				1,
+				func(_ *mochi.Client, _ packets.Subscription, pk packets.Packet) {
+					var command Command
+
+					require.NoError(t, json.Unmarshal(pk.Payload, &command))
+
+					commands <- &command
+				},
+			))
+
+			err := NewCommandHandler(client)(context.Background(), &eventmodel.Event{
+				UUID:        eventUUID,
+				Type:        tt.eventType,
+				OccurredAt:  testReadAt,
+				RentalUUID:  rentalUUID,
+				ScooterUUID: scooterUUID,
+			})
+			require.NoError(t, err)
+
+			if tt.want == nil {
+				require.Empty(t, commands)
+
+				client.Shutdown()
+
+				return
+			}
+
+			select {
+			case got := <-commands:
+				require.Equal(t, tt.want, got)
+			case <-time.After(testWait):
+				t.Fatal("command was not published")
+			}
+
+			client.Shutdown()
+		})
+	}
+}
+
+type testBroker struct {
+	server  *mochi.Server
+	address string
+}
+
+// newTestBroker runs the in-process broker on a random port, closed with the end of the test.
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+
+	listener := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+
+	require.NoError(t, server.AddListener(listener))
+	require.NoError(t, server.Serve())
+
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	return &testBroker{server: server, address: listener.Address()}
+}
+
+// newTestClient connects the client to the broker and waits for it to subscribe to the telemetry.
+func newTestClient(t *testing.T, broker *testBroker, trackerService *mocktracker.MockService) *Client {
+	t.Helper()
+
+	client := NewClient(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		api.NewValidator(),
+		trackerService,
+		&Options{
+			Broker:         "tcp://" + broker.address,
+			ClientID:       "scooter-rental-" + uuid.NewString(),
+			TelemetryTopic: testTelemetryTopic,
+			TelemetryQoS:   1,
+			CommandTopic:   testCommandTopic,
+			CommandQoS:     1,
+			ShareGroup:     testShareGroup,
+			Timeout:        testWait,
+		},
+	)
+
+	go func() {
+		_ = client.Serve()
+	}()
+
+	topic := newTopic(testTelemetryTopic).forScooter(uuid.New())
+
+	require.Eventually(t, func() bool {
+		return len(broker.server.Topics.Subscribers(topic).Shared) > 0
+	}, testWait, 10*time.Millisecond)
+
+	return client
+}
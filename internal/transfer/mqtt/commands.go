@@ -0,0 +1,63 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+)
+
+type CommandType string
+
+const (
+	// CommandUnlock is sent to the scooter when it is rented.
+	CommandUnlock CommandType = "unlock"
+	// CommandLock is sent to the scooter when it is freed.
+	CommandLock CommandType = "lock"
+)
+
+// Command is the message published to the command topic of the scooter. Its ID is the UUID of the event it comes
+// from, so the scooter can recognise the command published again when the event is delivered more than once.
+type Command struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       CommandType `json:"type"`
+	RentalUUID uuid.UUID   `json:"rentalId"`
+	IssuedAt   time.Time   `json:"issuedAt"`
+}
+
+//go:generate mockgen -source=commands.go -destination=mock/commands_mock.go -package=mock
+type CommandPublisher interface {
+	PublishCommand(ctx context.Context, scooterUUID uuid.UUID, command *Command) error
+}
+
+// NewCommandHandler returns the handler of the events of the bus, which unlocks the scooters when they are rented
+// and locks them when they are freed.
+func NewCommandHandler(publisher CommandPublisher) service.EventHandler {
+	return func(ctx context.Context, event *eventmodel.Event) error {
+		var commandType CommandType
+
+		switch event.Type {
+		case eventmodel.TypeRentalStarted:
+			commandType = CommandUnlock
+		case eventmodel.TypeRentalEnded:
+			commandType = CommandLock
+		default:
+			return nil
+		}
+
+		if err := publisher.PublishCommand(ctx, event.ScooterUUID, &Command{
+			ID:         event.UUID,
+			Type:       commandType,
+			RentalUUID: event.RentalUUID,
+			IssuedAt:   event.OccurredAt,
+		}); err != nil {
+			return fmt.Errorf("publishing %s command: %w", commandType, err)
+		}
+
+		return nil
+	}
+}
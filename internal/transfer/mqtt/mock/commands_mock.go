@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: commands.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	mqtt "github.com/PatrykPasterny/scooter-rental/internal/transfer/mqtt"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockCommandPublisher is a mock of CommandPublisher interface.
+type MockCommandPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandPublisherMockRecorder
+}
+
+// MockCommandPublisherMockRecorder is the mock recorder for MockCommandPublisher.
+type MockCommandPublisherMockRecorder struct {
+	mock *MockCommandPublisher
+}
+
+// NewMockCommandPublisher creates a new mock instance.
+func NewMockCommandPublisher(ctrl *gomock.Controller) *MockCommandPublisher {
+	mock := &MockCommandPublisher{ctrl: ctrl}
+	mock.recorder = &MockCommandPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandPublisher) EXPECT() *MockCommandPublisherMockRecorder {
+	return m.recorder
+}
+
+// PublishCommand mocks base method.
+func (m *MockCommandPublisher) PublishCommand(ctx context.Context, scooterUUID uuid.UUID, command *mqtt.Command) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishCommand", ctx, scooterUUID, command)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishCommand indicates an expected call of PublishCommand.
+func (mr *MockCommandPublisherMockRecorder) PublishCommand(ctx, scooterUUID, command interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCommand", reflect.TypeOf((*MockCommandPublisher)(nil).PublishCommand), ctx, scooterUUID, command)
+}
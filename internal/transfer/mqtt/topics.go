@@ -0,0 +1,66 @@
+package mqtt
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// scooterLevel is the level of the topic template standing for the ID of the scooter.
+const scooterLevel = "{scooterID}"
+
+// topic is the layout of the topics of the scooters, one topic per scooter.
+type topic struct {
+	levels []string
+	// scooterIndex is the position of the level of the ID of the scooter.
+	scooterIndex int
+}
+
+// newTopic splits the template, validated by the config to hold a single {scooterID} level and no wildcards.
+func newTopic(template string) *topic {
+	levels := strings.Split(template, "/")
+
+	scooterIndex := 0
+
+	for i, level := range levels {
+		if level == scooterLevel {
+			scooterIndex = i
+		}
+	}
+
+	return &topic{levels: levels, scooterIndex: scooterIndex}
+}
+
+// forScooter returns the topic of the scooter.
+func (t *topic) forScooter(scooterUUID uuid.UUID) string {
+	return t.withScooterLevel(scooterUUID.String())
+}
+
+// filter returns the filter matching the topics of all the scooters.
+func (t *topic) filter() string {
+	return t.withScooterLevel("+")
+}
+
+// scooterUUID returns the ID of the scooter the topic belongs to.
+func (t *topic) scooterUUID(name string) (uuid.UUID, bool) {
+	levels := strings.Split(name, "/")
+	if len(levels) != len(t.levels) {
+		return uuid.Nil, false
+	}
+
+	scooterUUID, err := uuid.Parse(levels[t.scooterIndex])
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return scooterUUID, true
+}
+
+func (t *topic) withScooterLevel(level string) string {
+	levels := make([]string, len(t.levels))
+	copy(levels, t.levels)
+
+	levels[t.scooterIndex] = level
+
+	return strings.Join(levels, "/")
+}
@@ -0,0 +1,69 @@
+//go:build unit
+
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopic(t *testing.T) {
+	scooterUUID := uuid.New()
+
+	tests := map[string]struct {
+		template       string
+		wantForScooter string
+		wantFilter     string
+		name           string
+		wantScooter    uuid.UUID
+		wantScooterOK  bool
+	}{
+		"scooter level in the middle": {
+			template:       "scooters/{scooterID}/telemetry",
+			wantForScooter: "scooters/" + scooterUUID.String() + "/telemetry",
+			wantFilter:     "scooters/+/telemetry",
+			name:           "scooters/" + scooterUUID.String() + "/telemetry",
+			wantScooter:    scooterUUID,
+			wantScooterOK:  true,
+		},
+		"scooter level at the end": {
+			template:       "fleet/montreal/{scooterID}",
+			wantForScooter: "fleet/montreal/" + scooterUUID.String(),
+			wantFilter:     "fleet/montreal/+",
+			name:           "fleet/montreal/" + scooterUUID.String(),
+			wantScooter:    scooterUUID,
+			wantScooterOK:  true,
+		},
+		"topic of other length": {
+			template:       "scooters/{scooterID}/telemetry",
+			wantForScooter: "scooters/" + scooterUUID.String() + "/telemetry",
+			wantFilter:     "scooters/+/telemetry",
+			name:           "scooters/" + scooterUUID.String() + "/telemetry/extra",
+			wantScooter:    uuid.Nil,
+			wantScooterOK:  false,
+		},
+		"scooter level not being uuid": {
+			template:       "scooters/{scooterID}/telemetry",
+			wantForScooter: "scooters/" + scooterUUID.String() + "/telemetry",
+			wantFilter:     "scooters/+/telemetry",
+			name:           "scooters/scooter-1/telemetry",
+			wantScooter:    uuid.Nil,
+			wantScooterOK:  false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			topic := newTopic(tt.template)
+
+			require.Equal(t, tt.wantForScooter, topic.forScooter(scooterUUID))
+			require.Equal(t, tt.wantFilter, topic.filter())
+
+			gotScooter, gotOK := topic.scooterUUID(tt.name)
+
+			require.Equal(t, tt.wantScooterOK, gotOK)
+			require.Equal(t, tt.wantScooter, gotScooter)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+// getLogLevel returns the level the application currently logs at.
+//
+//	@Summary	Gets the current log level.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//
+//	@Success	200	{object}	model.LogLevel
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Router		/v1/admin/log-level [get]
+func (s *Server) getLogLevel(w http.ResponseWriter, _ *http.Request) {
+	JSON(w, http.StatusOK, model.LogLevel{Level: s.logLevel.Level().String()})
+}
+
+// setLogLevel changes the level the application logs at without restarting it.
+//
+//	@Summary	Changes the log level.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//	@Param		Payload	body		model.LogLevel	true	"New log level (debug, info, warn or error)"
+//
+//	@Success	200		{object}	model.LogLevel
+//	@Failure	400		{object}	model.Problem
+//	@Failure	401		{object}	model.Problem
+//	@Failure	403		{object}	model.Problem
+//	@Failure	413		{object}	model.Problem
+//	@Failure	422		{object}	model.Problem
+//	@Router		/v1/admin/log-level [put]
+func (s *Server) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	ctxLogger := logging.FromContext(r.Context())
+
+	var logLevel model.LogLevel
+
+	if !s.decodeBody(w, r, &logLevel, "Failed to decode request body to log level.") {
+		return
+	}
+
+	level, err := logging.ParseLevel(logLevel.Level)
+	if err != nil {
+		ctxLogger.Error("failed to parse log level", slog.Any("err", err))
+
+		Error(w, http.StatusUnprocessableEntity, codeValidationFailed, "Unknown log level.")
+
+		return
+	}
+
+	previousLevel := s.logLevel.Level()
+	s.logLevel.Set(level)
+
+	ctxLogger.Info(
+		"Changed log level.",
+		slog.String("previous_level", previousLevel.String()),
+		slog.String("level", level.String()),
+	)
+
+	JSON(w, http.StatusOK, model.LogLevel{Level: level.String()})
+}
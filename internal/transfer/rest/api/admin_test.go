@@ -0,0 +1,116 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const testRiderUUID = "8212d8ba-74d1-49af-8a84-6d6c392ec71c"
+
+func TestSetLogLevel(t *testing.T) {
+	tests := map[string]struct {
+		token        string
+		clientID     string
+		body         string
+		expectedCode int
+		expectedBody string
+		wantLevel    slog.Level
+	}{
+		"successfully changed log level": {
+			token:        testAdminToken,
+			body:         `{"level":"debug"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"level":"DEBUG"}`,
+			wantLevel:    slog.LevelDebug,
+		},
+		"failed changing log level because the level is unknown": {
+			token:        testAdminToken,
+			body:         `{"level":"verbose"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Unknown log level.", testRequestID,
+			),
+			wantLevel: slog.LevelInfo,
+		},
+		"failed changing log level because the request has invalid body": {
+			token:        testAdminToken,
+			body:         `{}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating request body.", testRequestID,
+				model.FieldError{Field: "level", Code: "required", Detail: "is required"},
+			),
+			wantLevel: slog.LevelInfo,
+		},
+		"failed changing log level because the admin token is wrong": {
+			token:        "wrong-token",
+			body:         `{"level":"debug"}`,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.", testRequestID,
+			),
+			wantLevel: slog.LevelInfo,
+		},
+		"failed changing log level because riders lack the permission": {
+			clientID:     testRiderUUID,
+			body:         `{"level":"debug"}`,
+			expectedCode: http.StatusForbidden,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusForbidden, auth.ReasonMissingPermission, "Permission denied.", testRequestID,
+			),
+			wantLevel: slog.LevelInfo,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, _, mockUserService := beforeTest(t)
+
+			request := httptest.NewRequest(http.MethodPut, api+version+logLevelPath, bytes.NewBufferString(tt.body))
+			request.Header.Set(headerRequestID, testRequestID)
+
+			if tt.token != "" {
+				request.Header.Set(headerAuthorization, bearerPrefix+tt.token)
+			}
+
+			if tt.clientID != "" {
+				request.Header.Set(headerClientID, tt.clientID)
+
+				clientUUID := uuid.MustParse(tt.clientID)
+
+				mockUserService.EXPECT().GetUser(gomock.Any(), clientUUID).
+					Return(usermodel.NewUser(clientUUID, usermodel.Profile{}, time.Now()), nil)
+			}
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.router.ServeHTTP(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+
+			if level := s.logLevel.Level(); level != tt.wantLevel {
+				t.Errorf("handler left log level = %v, want %v", level, tt.wantLevel)
+			}
+		})
+	}
+}
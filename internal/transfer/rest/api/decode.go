@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/schema"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	maxRequestBodyBytes = 1 << 20
+
+	tagLatitude  = "lat"
+	tagLongitude = "lon"
+
+	fieldCodeUnknown     = "unknown"
+	fieldCodeInvalidType = "invalid_type"
+)
+
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// NewValidator creates the validator of the requests. It names the fields after their JSON names, so the clients
+// can find them in the field errors, and checks the coordinates with the lat and lon tags.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	_ = validate.RegisterValidation(tagLatitude, func(fl validator.FieldLevel) bool {
+		return fl.Field().Float() >= -90 && fl.Field().Float() <= 90
+	})
+	_ = validate.RegisterValidation(tagLongitude, func(fl validator.FieldLevel) bool {
+		return fl.Field().Float() >= -180 && fl.Field().Float() <= 180
+	})
+
+	return validate
+}
+
+// decodeBody decodes the JSON body of the request into the payload and validates it. The body is limited in size and
+// may not carry unknown fields. When it is malformed or invalid, the problem response is written, with the detail
+// given for the malformed ones, and false is returned.
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, payload any, detail string) bool {
+	ctxLogger := logging.FromContext(r.Context())
+
+	if err := decodeJSON(w, r, payload); err != nil {
+		ctxLogger.Error("failed to decode request body", slog.Any("err", err))
+
+		decodeError(w, err, detail)
+
+		return false
+	}
+
+	return s.validate(w, r, payload, "Failed validating request body.")
+}
+
+// validate validates the decoded payload, writing the problem response listing the invalid fields and returning false
+// when it is invalid.
+func (s *Server) validate(w http.ResponseWriter, r *http.Request, payload any, detail string) bool {
+	err := s.validator.Struct(payload)
+	if err == nil {
+		return true
+	}
+
+	logging.FromContext(r.Context()).Error("failed to validate request", slog.Any("err", err))
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		Error(w, http.StatusUnprocessableEntity, codeValidationFailed, detail)
+
+		return false
+	}
+
+	fieldErrors := make([]model.FieldError, len(validationErrs))
+
+	for i, fieldErr := range validationErrs {
+		fieldErrors[i] = model.FieldError{
+			Field:  fieldPath(fieldErr),
+			Code:   fieldErr.Tag(),
+			Detail: fieldErrorDetail(fieldErr),
+		}
+	}
+
+	problem(w, http.StatusUnprocessableEntity, codeValidationFailed, detail, fieldErrors)
+
+	return false
+}
+
+// decodeQuery decodes the query params of the request into the payload and validates them like decodeBody does with
+// the body.
+func (s *Server) decodeQuery(w http.ResponseWriter, r *http.Request, payload any, detail string) bool {
+	if err := schema.NewDecoder().Decode(payload, r.URL.Query()); err != nil {
+		logging.FromContext(r.Context()).Error("failed to decode query params", slog.Any("err", err))
+
+		var multiErr schema.MultiError
+		if !errors.As(err, &multiErr) {
+			Error(w, http.StatusBadRequest, codeMalformedRequest, detail)
+
+			return false
+		}
+
+		fieldErrors := make([]model.FieldError, 0, len(multiErr))
+
+		for key, keyErr := range multiErr {
+			fieldErr := model.FieldError{Field: key, Code: fieldCodeInvalidType, Detail: "has a value of a wrong type"}
+
+			var unknownKeyErr schema.UnknownKeyError
+			if errors.As(keyErr, &unknownKeyErr) {
+				fieldErr.Code, fieldErr.Detail = fieldCodeUnknown, "is not a known field"
+			}
+
+			fieldErrors = append(fieldErrors, fieldErr)
+		}
+
+		slices.SortFunc(fieldErrors, func(a, b model.FieldError) int {
+			return strings.Compare(a.Field, b.Field)
+		})
+
+		problem(w, http.StatusBadRequest, codeMalformedRequest, detail, fieldErrors)
+
+		return false
+	}
+
+	return s.validate(w, r, payload, "Failed validating query params.")
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, payload any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(payload); err != nil {
+		return fmt.Errorf("decoding request body: %w", err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+
+	return nil
+}
+
+// decodeError writes the problem response for a body that failed decoding, pointing at the field when it is known.
+func decodeError(w http.ResponseWriter, err error, detail string) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		Error(
+			w,
+			http.StatusRequestEntityTooLarge,
+			codeRequestTooLarge,
+			fmt.Sprintf("Request body must not be larger than %d bytes.", maxBytesErr.Limit),
+		)
+
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		problem(w, http.StatusBadRequest, codeMalformedRequest, detail, []model.FieldError{{
+			Field:  typeErr.Field,
+			Code:   fieldCodeInvalidType,
+			Detail: "must be of type " + typeErr.Type.String(),
+		}})
+
+		return
+	}
+
+	// the json package doesn't export the error of an unknown field
+	if _, field, found := strings.Cut(err.Error(), "json: unknown field "); found {
+		problem(w, http.StatusBadRequest, codeMalformedRequest, detail, []model.FieldError{{
+			Field:  strings.Trim(field, `"`),
+			Code:   fieldCodeUnknown,
+			Detail: "is not a known field",
+		}})
+
+		return
+	}
+
+	Error(w, http.StatusBadRequest, codeMalformedRequest, detail)
+}
+
+// fieldPath returns the path of the field within the payload, e.g. readings[0].battery, leaving out the name of the
+// payload itself.
+func fieldPath(fieldErr validator.FieldError) string {
+	if _, path, found := strings.Cut(fieldErr.Namespace(), "."); found {
+		return path
+	}
+
+	return fieldErr.Field()
+}
+
+// fieldErrorDetail describes the validation rule the field broke.
+func fieldErrorDetail(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case tagLatitude:
+		return "must be a latitude between -90 and 90"
+	case tagLongitude:
+		return "must be a longitude between -180 and 180"
+	case "email":
+		return "must be an email address"
+	case "e164":
+		return "must be a phone number in the E.164 format"
+	case "oneof":
+		return "must be one of: " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "http_url":
+		return "must be an HTTP or HTTPS URL"
+	case "min":
+		switch fieldErr.Kind() {
+		case reflect.String:
+			return "must be at least " + fieldErr.Param() + " characters long"
+		case reflect.Slice:
+			return "must have at least " + fieldErr.Param() + " items"
+		default:
+			return "must be at least " + fieldErr.Param()
+		}
+	case "max":
+		switch fieldErr.Kind() {
+		case reflect.String:
+			return "must be at most " + fieldErr.Param() + " characters long"
+		case reflect.Slice:
+			return "must have at most " + fieldErr.Param() + " items"
+		default:
+			return "must be at most " + fieldErr.Param()
+		}
+	default:
+		return "is invalid"
+	}
+}
@@ -0,0 +1,94 @@
+//go:build unit
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const testDecodeDetail = "Failed to decode request body to rental information."
+
+func TestDecodeBody(t *testing.T) {
+	s, _, _, _ := beforeTest(t)
+
+	tests := map[string]struct {
+		body         string
+		wantOK       bool
+		expectedCode int
+		expectedBody string
+	}{
+		"successfully decoded coordinates of zero": {
+			body:   `{"UUID":"0dae4f8c-dbbf-4bac-90f2-b80f07255ba5","longitude":0,"latitude":0,"city":"Ottawa"}`,
+			wantOK: true,
+		},
+		"failed due to unknown field": {
+			body: `{"UUID":"0dae4f8c-dbbf-4bac-90f2-b80f07255ba5","longitude":0,"latitude":0,"city":"Ottawa",` +
+				`"speed":10}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(
+				http.StatusBadRequest, codeMalformedRequest, testDecodeDetail,
+				model.FieldError{Field: "speed", Code: fieldCodeUnknown, Detail: "is not a known field"},
+			),
+		},
+		"failed due to field of wrong type": {
+			body:         `{"UUID":"0dae4f8c-dbbf-4bac-90f2-b80f07255ba5","longitude":"east"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(
+				http.StatusBadRequest, codeMalformedRequest, testDecodeDetail,
+				model.FieldError{Field: "longitude", Code: fieldCodeInvalidType, Detail: "must be of type float64"},
+			),
+		},
+		"failed due to trailing data": {
+			body:         `{"UUID":"0dae4f8c-dbbf-4bac-90f2-b80f07255ba5"}{}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(http.StatusBadRequest, codeMalformedRequest, testDecodeDetail),
+		},
+		"failed due to too large body": {
+			body:         `{"city":"` + strings.Repeat("a", maxRequestBodyBytes) + `"}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: problemBody(
+				http.StatusRequestEntityTooLarge,
+				codeRequestTooLarge,
+				"Request body must not be larger than 1048576 bytes.",
+			),
+		},
+		"failed due to invalid fields": {
+			body:         `{"UUID":"0dae4f8c-dbbf-4bac-90f2-b80f07255ba5","longitude":0,"latitude":-90.5}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: problemBody(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating request body.",
+				model.FieldError{Field: "latitude", Code: tagLatitude, Detail: "must be a latitude between -90 and 90"},
+				model.FieldError{Field: "city", Code: "required", Detail: "is required"},
+			),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, rentPath, strings.NewReader(tt.body))
+			responseRecorder := httptest.NewRecorder()
+
+			var rentPost model.RentPost
+
+			if ok := s.decodeBody(responseRecorder, request, &rentPost, testDecodeDetail); ok != tt.wantOK {
+				t.Errorf("decodeBody() = %v, want %v", ok, tt.wantOK)
+			}
+
+			if tt.wantOK {
+				return
+			}
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("decodeBody() wrote status code = %v, want %v", status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("decodeBody() wrote body = %v, want %v", body, tt.expectedBody)
+			}
+		})
+	}
+}
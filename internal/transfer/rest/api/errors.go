@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/rental"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	contentTypeProblemJSON = "application/problem+json"
+	problemTypeBlank       = "about:blank"
+)
+
+// Codes of the problems, meant for the clients to tell the failures apart. The reasons of the permission denials
+// are used as the codes of the 403 responses.
+const (
+	codeUnauthenticated     = "unauthenticated"
+	codeMalformedRequest    = "malformed_request"
+	codeValidationFailed    = "validation_failed"
+	codeRequestTooLarge     = "request_too_large"
+	codeNotAcceptable       = "not_acceptable"
+	codeScooterNotFound     = "scooter_not_found"
+	codeScooterNotAvailable = "scooter_not_available"
+	codeInvalidScooterID    = "invalid_scooter_id"
+	codeRentalNotFound      = "rental_not_found"
+	codeRentalEnded         = "rental_ended"
+	codeUserNotFound        = "user_not_found"
+	codeUserAlreadyExists   = "user_already_registered"
+	codeConflict            = "conflict"
+	codeTrackerDegraded     = "tracker_degraded"
+	codeTelemetryDisabled   = "telemetry_disabled"
+	codeTelemetryOutOfOrder = "telemetry_out_of_order"
+	codeTelemetryInFuture   = "telemetry_in_future"
+	codeWebhookNotFound     = "webhook_not_found"
+	codeDeadLetterNotFound  = "dead_letter_not_found"
+	codeDeliveryFailed      = "webhook_delivery_failed"
+	codeServiceUnavailable  = "service_unavailable"
+	codeRateLimitExceeded   = "rate_limit_exceeded"
+	codeInternal            = "internal_error"
+)
+
+// domainErrors maps the errors of the services and the repository to the responses, the first matching one wins.
+var domainErrors = []struct {
+	err        error
+	statusCode int
+	code       string
+	detail     string
+}{
+	{repository.ErrScooterNotFound, http.StatusNotFound, codeScooterNotFound, "Scooter not found."},
+	{repository.ErrScooterNotAvailable, http.StatusConflict, codeScooterNotAvailable, "Scooter is not available."},
+	{rental.ErrInvalidScooterUUID, http.StatusUnprocessableEntity, codeInvalidScooterID, "Scooter ID is invalid."},
+	{service.ErrRentalNotFound, http.StatusNotFound, codeRentalNotFound, "Rental not found."},
+	{rental.ErrRentalEnded, http.StatusConflict, codeRentalEnded, "Rental has already ended."},
+	{service.ErrUserNotFound, http.StatusNotFound, codeUserNotFound, "User not found."},
+	{service.ErrUserAlreadyExists, http.StatusConflict, codeUserAlreadyExists, "User is already registered."},
+	{service.ErrUserConflict, http.StatusConflict, codeConflict, "User was changed concurrently, try again."},
+	{redis.TxFailedErr, http.StatusConflict, codeConflict, "Changed concurrently by another request, try again."},
+	{tracker.ErrTrackerDegraded, http.StatusServiceUnavailable, codeTrackerDegraded, "Scooter tracking is degraded."},
+	{tracker.ErrDeviceTrackingDisabled, http.StatusConflict, codeTelemetryDisabled, "Scooter rides are simulated."},
+	{tracker.ErrTelemetryOutOfOrder, http.StatusUnprocessableEntity, codeTelemetryOutOfOrder, "Unordered telemetry."},
+	{tracker.ErrTelemetryInFuture, http.StatusUnprocessableEntity, codeTelemetryInFuture, "Telemetry from the future."},
+	{service.ErrWebhookNotFound, http.StatusNotFound, codeWebhookNotFound, "Webhook not found."},
+	{service.ErrDeadLetterNotFound, http.StatusNotFound, codeDeadLetterNotFound, "Dead letter not found."},
+	{webhook.ErrDeliveryFailed, http.StatusBadGateway, codeDeliveryFailed, "Webhook receiver rejected the delivery."},
+}
+
+// Error writes a problem details response (RFC 7807) carrying the stable code of the problem and the ID of the
+// request.
+func Error(w http.ResponseWriter, statusCode int, code, detail string) {
+	problem(w, statusCode, code, detail, nil)
+}
+
+// problem writes the problem details response, listing the fields of the request that caused it, if any.
+func problem(w http.ResponseWriter, statusCode int, code, detail string, fieldErrors []model.FieldError) {
+	body, err := json.Marshal(&model.Problem{
+		Type:      problemTypeBlank,
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    detail,
+		Code:      code,
+		RequestID: w.Header().Get(headerRequestID),
+		Errors:    fieldErrors,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set(headerContentType, contentTypeProblemJSON)
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+// domainError writes the response for a failed service call. Known domain errors are mapped to their statuses, a call
+// rejected by an open circuit breaker ends with 503 and a Retry-After header and any other error with 500 and the
+// given detail.
+func domainError(w http.ResponseWriter, err error, detail string) {
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		retryAfter := int(math.Ceil(openErr.RetryAfter.Seconds()))
+
+		w.Header().Set(headerRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+
+		Error(w, http.StatusServiceUnavailable, codeServiceUnavailable, "Service temporarily unavailable.")
+
+		return
+	}
+
+	if errors.Is(err, auth.ErrPermissionDenied) {
+		forbidden(w, err)
+
+		return
+	}
+
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr.err) {
+			Error(w, domainErr.statusCode, domainErr.code, domainErr.detail)
+
+			return
+		}
+	}
+
+	Error(w, http.StatusInternalServerError, codeInternal, detail)
+}
+
+// forbidden writes the 403 response carrying the reason of the denial as its code.
+func forbidden(w http.ResponseWriter, err error) {
+	reason := auth.ReasonMissingPermission
+
+	var deniedErr *auth.DeniedError
+	if errors.As(err, &deniedErr) {
+		reason = deniedErr.Reason
+	}
+
+	Error(w, http.StatusForbidden, reason, "Permission denied.")
+}
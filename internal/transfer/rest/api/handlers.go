@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,34 +9,41 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/schema"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
 	modelrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
-	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
 	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
 )
 
 const (
 	headerContentType = "Content-Type"
+	headerRetryAfter  = "Retry-After"
 	contentTypeJSON   = "application/json"
 )
 
 var (
-	errExpectedHeaderParamNotFound = errors.New("expected header parameter was not found")
+	errIdentityNotFound = errors.New("identity of the client was not found in the request context")
 )
 
 //	@title			Scootin Aboot
 //	@version		1.0
 //	@description	The API that enables user to rent, track and free scooters operated by Scootin Aboot company.
 //	@Schema			http https
-//	@BasePath		/api/v1
+//	@BasePath		/api
+
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Bearer token signed by the identity provider, e.g. "Bearer eyJhbGciOi...".
 
 // getScooters returns all the scooters owned by Scootin Aboot company in the queried rectangle area of a given city.
 //
 //	@Summary	Gets scooters in the queried area of given city.
 //	@Tags		scooters
 //
-//	@Param		Client-Id		header		string	true	"ClientID"									minlength(36)	maxlength(36)	default(00000000-0000-0000-0000-000000000000)
+//	@Security	BearerAuth
+//	@Param		Client-Id		header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
 //	@Param		city			query		string	true	"City"										default(Ottawa)
 //	@Param		longitude		query		number	true	"Longitude of the center of the rectangle"	default(73.4)
 //	@Param		latitude		query		number	true	"Latitude of the center of the rectangle"	default(45.4)
@@ -44,58 +52,49 @@ var (
 //	@Param		availability	query		bool	false	"Value of availability to filter by"
 //
 //	@Success	200				{object}	[]model.ScooterGet
-//	@Failure	400				{object}	model.ApiError
-//	@Failure	403				{object}	model.ApiError
-//	@Failure	500				{object}	model.ApiError
-//	@Router		/scooters [get]
+//	@Failure	400				{object}	model.Problem
+//	@Failure	401				{object}	model.Problem
+//	@Failure	403				{object}	model.Problem
+//	@Failure	422				{object}	model.Problem
+//	@Failure	429				{object}	model.Problem
+//	@Failure	500				{object}	model.Problem
+//	@Failure	503				{object}	model.Problem
+//	@Router		/v1/scooters [get]
 func (s *Server) getScooters(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	clientUUID, err := clientUUIDFromHeader(r)
-	if err != nil {
-		s.logger.Error("failed to get clientID from header", slog.Any("err", err))
+	ctxLogger := logging.FromContext(ctx)
 
-		Error(w, http.StatusBadRequest, "Failed getting clientUUID from header.")
+	if _, err := clientUUIDFromContext(ctx); err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
 
 		return
 	}
 
-	ctxLogger := s.logger.With(
-		slog.String("client_id", clientUUID.String()),
-	)
-
 	var queryParams model.ScooterQueryParams
 
-	decoder := schema.NewDecoder()
-
-	if err = decoder.Decode(&queryParams, r.URL.Query()); err != nil {
-		ctxLogger.Error("failed to decode query params", slog.Any("err", err))
-
-		Error(w, http.StatusBadRequest, "Failed decoding query params.")
-
+	if !s.decodeQuery(w, r, &queryParams, "Failed decoding query params.") {
 		return
 	}
 
-	if err = s.validator.Struct(queryParams); err != nil {
-		ctxLogger.Error("failed to validate query params", slog.Any("err", err))
-
-		Error(w, http.StatusBadRequest, "Failed validating query params.")
-
+	if !authorizeCity(w, r, queryParams.City) {
 		return
 	}
 
 	geoRectangle := modelrental.NewRectangle(
 		queryParams.City,
-		queryParams.Longitude,
-		queryParams.Latitude,
+		*queryParams.Longitude,
+		*queryParams.Latitude,
 		queryParams.Height,
 		queryParams.Width,
 	)
 
-	ctxLogger = s.logger.With(
+	ctxLogger = ctxLogger.With(
 		slog.String("city", queryParams.City),
-		slog.Float64("longitude", queryParams.Longitude),
-		slog.Float64("latitude", queryParams.Latitude),
+		slog.Float64("longitude", *queryParams.Longitude),
+		slog.Float64("latitude", *queryParams.Latitude),
 		slog.Float64("height", queryParams.Height),
 		slog.Float64("width", queryParams.Width),
 	)
@@ -106,7 +105,7 @@ func (s *Server) getScooters(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		ctxLogger.Error("failed to get scooters from rental service", slog.Any("err", err))
 
-		Error(w, http.StatusInternalServerError, "Failed getting scooters.")
+		domainError(w, err, "Failed getting scooters.")
 
 		return
 	}
@@ -118,7 +117,7 @@ func (s *Server) getScooters(w http.ResponseWriter, r *http.Request) {
 		if innerErr != nil {
 			ctxLogger.Error("failed to get parse scooterID to ScooterUUID", slog.Any("err", err))
 
-			Error(w, http.StatusInternalServerError, "Failed parsing scooterID.")
+			Error(w, http.StatusInternalServerError, codeInternal, "Failed parsing scooterID.")
 
 			return
 		}
@@ -143,171 +142,177 @@ func (s *Server) getScooters(w http.ResponseWriter, r *http.Request) {
 }
 
 // rentScooter enables user to rent the given scooter from the pool owned by Scootin Aboot company in a given city.
+// The scooter is rented in the city and at the position it is stored with, the city in the body must match it.
+// Deprecated in favour of createRental.
 //
 //	@Summary	Rents the chosen scooter in given city.
 //	@Tags		scooters
 //
-//	@Param		Client-Id	header	string			true	"ClientID"	minlength(36)	maxlength(36)	default(00000000-0000-0000-0000-000000000000)
+//	@Security	BearerAuth
+//	@Param		Client-Id	header	string			false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
 //	@Param		Payload		body	model.RentPost	true	"Rental information details"
 //
 //	@Success	204
-//	@Failure	400	{object}	model.ApiError
-//	@Failure	403	{object}	model.ApiError
-//	@Failure	500	{object}	model.ApiError
-//	@Router		/rent [post]
+//	@Failure	400	{object}	model.Problem
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Failure	404	{object}	model.Problem
+//	@Failure	409	{object}	model.Problem
+//	@Failure	413	{object}	model.Problem
+//	@Failure	422	{object}	model.Problem
+//	@Failure	429	{object}	model.Problem
+//	@Failure	500	{object}	model.Problem
+//	@Failure	503	{object}	model.Problem
+//	@Deprecated
+//	@Router		/v1/rent [post]
 func (s *Server) rentScooter(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	clientUUID, err := clientUUIDFromHeader(r)
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
 	if err != nil {
-		s.logger.Error("failed to get clientID from header", slog.Any("err", err))
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
 
-		Error(w, http.StatusBadRequest, "Failed getting clientUUID from header.")
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
 
 		return
 	}
 
-	ctxLogger := s.logger.With(
-		slog.String("client_id", clientUUID.String()),
-	)
-
 	var rentPost model.RentPost
 
-	if err = json.NewDecoder(r.Body).Decode(&rentPost); err != nil {
-		ctxLogger.Error("failed to decode request body", slog.Any("err", err))
+	if !s.decodeBody(w, r, &rentPost, "Failed to decode request body to rental information.") {
+		return
+	}
 
-		Error(w, http.StatusBadRequest, "Failed to decode request body to rental information.")
+	ctxLogger = ctxLogger.With(slog.String("scooter_id", rentPost.ScooterUUID.String()))
+
+	rentalScooter, err := s.rentalService.GetScooter(ctx, rentPost.ScooterUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get scooter", slog.Any("err", err))
+
+		domainError(w, err, "Failed renting scooter.")
 
 		return
 	}
 
-	if err = s.validator.Struct(rentPost); err != nil {
-		ctxLogger.Error("failed to validate request body", slog.Any("err", err))
+	ctxLogger = ctxLogger.With(slog.String("city", rentalScooter.City))
+
+	if rentPost.City != rentalScooter.City {
+		ctxLogger.Error("city of request doesn't match scooter's city", slog.String("requested_city", rentPost.City))
 
-		Error(w, http.StatusBadRequest, "Failed validating request body.")
+		Error(w, http.StatusUnprocessableEntity, codeValidationFailed, "City doesn't match the city of the scooter.")
 
 		return
 	}
 
-	ctxLogger = ctxLogger.With(
-		slog.String("scooter_id", rentPost.ScooterUUID.String()),
-		slog.String("city", rentPost.City),
-	)
-
-	rentalScooter := modelrental.NewRentInfo(rentPost.ScooterUUID.String(), rentPost.City)
+	if !authorizeCity(w, r, rentalScooter.City) {
+		return
+	}
 
 	ctxLogger.Info("Renting scooter.")
 
-	if err = s.rentalService.Rent(ctx, rentalScooter); err != nil {
+	rentInfo := modelrental.NewRentInfo(
+		rentalScooter.Name,
+		rentalScooter.City,
+		rentalScooter.Longitude,
+		rentalScooter.Latitude,
+	)
+
+	if _, err = s.rentalService.Rent(ctx, clientUUID, rentInfo); err != nil {
 		ctxLogger.Error("failed to rent a scooter", slog.Any("err", err))
 
-		Error(w, http.StatusInternalServerError, "Failed renting scooter.")
+		domainError(w, err, "Failed renting scooter.")
 
 		return
 	}
 
 	ctxLogger.Info("Successfully rented scooter.")
 
-	trackerInfo := trackermodel.NewScooter(
-		rentPost.ScooterUUID.String(),
-		rentPost.City,
-		rentPost.Longitude,
-		rentPost.Latitude,
-	)
-
-	if err = s.trackerService.Track(clientUUID, trackerInfo); err != nil {
-		ctxLogger.Warn("Failed to enable tracking for rented scooter.")
-	} else {
-		ctxLogger.Info("Tracking rented scooter.")
-	}
-
 	JSON(w, http.StatusNoContent, nil)
 }
 
-// freeScooter enables user to free the scooter that is used by the user.
+// freeScooter enables user to free the scooter that is used by the user. Deprecated in favour of endRental.
 //
 //	@Summary	Free the given scooter.
 //	@Tags		scooters
 //
-//	@Param		Client-Id	header	string			true	"ClientID"	minlength(36)	maxlength(36)	default(00000000-0000-0000-0000-000000000000)
+//	@Security	BearerAuth
+//	@Param		Client-Id	header	string			false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
 //	@Param		Payload		body	model.FreePost	true	"Scooter to free information"
 //
 //	@Success	204
-//	@Failure	400	{object}	model.ApiError
-//	@Failure	403	{object}	model.ApiError
-//	@Failure	500	{object}	model.ApiError
-//	@Router		/free [post]
+//	@Failure	400	{object}	model.Problem
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Failure	404	{object}	model.Problem
+//	@Failure	409	{object}	model.Problem
+//	@Failure	413	{object}	model.Problem
+//	@Failure	422	{object}	model.Problem
+//	@Failure	429	{object}	model.Problem
+//	@Failure	500	{object}	model.Problem
+//	@Failure	503	{object}	model.Problem
+//	@Deprecated
+//	@Router		/v1/free [post]
 func (s *Server) freeScooter(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	clientUUID, err := clientUUIDFromHeader(r)
-	if err != nil {
-		s.logger.Error("failed to get clientID from header", slog.Any("err", err))
+	ctxLogger := logging.FromContext(ctx)
 
-		Error(w, http.StatusBadRequest, "Failed getting clientUUID from header.")
+	if _, err := clientUUIDFromContext(ctx); err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
 
 		return
 	}
 
-	ctxLogger := s.logger.With(
-		slog.String("client_id", clientUUID.String()),
-	)
-
 	var freePost model.FreePost
 
-	if err = json.NewDecoder(r.Body).Decode(&freePost); err != nil {
-		ctxLogger.Error("failed to decode scooterID from request body", slog.Any("err", err))
-
-		Error(w, http.StatusBadRequest, "Failed decoding request body to scooterID.")
-
+	if !s.decodeBody(w, r, &freePost, "Failed decoding request body to scooterID.") {
 		return
 	}
 
-	if err = s.validator.Struct(freePost); err != nil {
-		ctxLogger.Error("failed to validate request body", slog.Any("err", err))
+	ctxLogger = ctxLogger.With(
+		slog.String("scooter_id", freePost.ScooterUUID.String()),
+	)
+
+	rentalScooter, err := s.rentalService.GetScooter(ctx, freePost.ScooterUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get scooter", slog.Any("err", err))
 
-		Error(w, http.StatusBadRequest, "Failed validating request body.")
+		domainError(w, err, "Failed freeing scooter.")
 
 		return
 	}
 
-	ctxLogger = s.logger.With(
-		slog.String("scooter_id", freePost.ScooterUUID.String()),
-	)
+	if !authorizeCity(w, r, rentalScooter.City) {
+		return
+	}
 
 	ctxLogger.Info("Freeing the scooter.")
 
-	if err = s.rentalService.Free(ctx, freePost.ScooterUUID); err != nil {
+	if _, err = s.rentalService.Free(ctx, freePost.ScooterUUID); err != nil {
 		ctxLogger.Error("failed to free the scooter", slog.Any("err", err))
 
-		Error(w, http.StatusInternalServerError, "Failed freeing scooter.")
+		domainError(w, err, "Failed freeing scooter.")
 
 		return
 	}
 
 	ctxLogger.Info("Successfully freed the scooter.")
 
-	if err = s.trackerService.StopTracking(clientUUID, freePost.ScooterUUID); err != nil {
-		ctxLogger.Warn("Failed to stop tracking the scooter.", err)
-	} else {
-		ctxLogger.Info("Stopped tracking the scooter.")
-	}
-
 	JSON(w, http.StatusNoContent, nil)
 }
 
-func clientUUIDFromHeader(r *http.Request) (uuid.UUID, error) {
-	clientUUIDAsString := r.Header.Get("Client-Id")
-	if len(clientUUIDAsString) == 0 {
-		return uuid.Nil, errExpectedHeaderParamNotFound
+// clientUUIDFromContext returns the ID of the client authenticated by the AuthenticateUser middleware.
+func clientUUIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return uuid.Nil, errIdentityNotFound
 	}
 
-	clientUUID, err := uuid.Parse(clientUUIDAsString)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("parsing clientID: %w", err)
-	}
-
-	return clientUUID, nil
+	return identity.Subject, nil
 }
 
 // JSON writes a JSON response.
@@ -324,12 +329,3 @@ func JSON(w http.ResponseWriter, statusCode int, payload interface{}) {
 	w.WriteHeader(statusCode)
 	_, _ = w.Write(body)
 }
-
-// Error writes an error response.
-func Error(w http.ResponseWriter, statusCode int, message string) {
-	apiError := model.ApiError{
-		Message: message,
-	}
-
-	JSON(w, statusCode, &apiError)
-}
@@ -15,17 +15,22 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/health"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/livemap"
 	mockrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/mock"
 	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 	mocktracker "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/mock"
-	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	mockuser "github.com/PatrykPasterny/scooter-rental/internal/service/user/mock"
 	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
 )
 
@@ -35,16 +40,19 @@ const (
 	testLatitude  = 60.0
 	testHeight    = 10000.0
 	testWidth     = 15000.0
+
+	testAdminToken = "test-admin-token"
+	testRequestID  = "test-request-id"
 )
 
 func TestGetScooters(t *testing.T) {
-	s, mockRentalService, _ := beforeTest(t)
-
-	ctx := context.Background()
+	s, mockRentalService, _, _ := beforeTest(t)
 
 	clientUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	ctx := auth.WithIdentity(context.Background(), newTestIdentity(clientUUID))
+
 	scooterUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
@@ -67,26 +75,25 @@ func TestGetScooters(t *testing.T) {
 		},
 	}
 
-	params := &model.ScooterQueryParams{
-		Longitude: testLongitude,
-		Latitude:  testLatitude,
-		Height:    testHeight,
-		Width:     testWidth,
-		City:      testCity,
-	}
-
-	rectangle := rentalmodel.NewRectangle(params.City, params.Longitude, params.Latitude, params.Height, params.Width)
+	rectangle := rentalmodel.NewRectangle(testCity, testLongitude, testLatitude, testHeight, testWidth)
 
 	validURLQuery := &url.Values{}
-	validURLQuery.Add("longitude", strconv.FormatFloat(params.Longitude, 'f', -1, 64))
-	validURLQuery.Add("latitude", strconv.FormatFloat(params.Latitude, 'f', -1, 64))
-	validURLQuery.Add("height", strconv.FormatFloat(params.Height, 'f', -1, 64))
-	validURLQuery.Add("width", strconv.FormatFloat(params.Width, 'f', -1, 64))
-	validURLQuery.Add("city", params.City)
+	validURLQuery.Add("longitude", strconv.FormatFloat(testLongitude, 'f', -1, 64))
+	validURLQuery.Add("latitude", strconv.FormatFloat(testLatitude, 'f', -1, 64))
+	validURLQuery.Add("height", strconv.FormatFloat(testHeight, 'f', -1, 64))
+	validURLQuery.Add("width", strconv.FormatFloat(testWidth, 'f', -1, 64))
+	validURLQuery.Add("city", testCity)
 
 	invalidURLQuery := &url.Values{}
 	invalidURLQuery.Add("wrong", "wrong")
 
+	outOfRangeURLQuery := &url.Values{}
+	outOfRangeURLQuery.Add("longitude", "181")
+	outOfRangeURLQuery.Add("latitude", strconv.FormatFloat(testLatitude, 'f', -1, 64))
+	outOfRangeURLQuery.Add("height", strconv.FormatFloat(testHeight, 'f', -1, 64))
+	outOfRangeURLQuery.Add("width", strconv.FormatFloat(testWidth, 'f', -1, 64))
+	outOfRangeURLQuery.Add("city", testCity)
+
 	expectedScootersJSON, err := json.Marshal(expectedScooters)
 	require.NoError(t, err)
 
@@ -107,19 +114,38 @@ func TestGetScooters(t *testing.T) {
 			expectedCode: http.StatusOK,
 			expectedBody: string(expectedScootersJSON),
 		},
-		"failed getting scooter because request has no clientUUID in header": {
+		"failed getting scooter because request has no authenticated client": {
 			mockRentalServiceHandler: nil,
 			urlQuery:                 validURLQuery,
 			clientUUID:               uuid.NullUUID{Valid: false},
-			expectedCode:             http.StatusBadRequest,
-			expectedBody:             `{"Message":"Failed getting clientUUID from header."}`,
+			expectedCode:             http.StatusUnauthorized,
+			expectedBody: problemBody(
+				http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.",
+			),
 		},
 		"failed getting scooter because request has wrong query params": {
 			mockRentalServiceHandler: nil,
 			urlQuery:                 invalidURLQuery,
 			clientUUID:               uuid.NullUUID{UUID: clientUUID, Valid: true},
 			expectedCode:             http.StatusBadRequest,
-			expectedBody:             `{"Message":"Failed decoding query params."}`,
+			expectedBody: problemBody(
+				http.StatusBadRequest, codeMalformedRequest, "Failed decoding query params.",
+				model.FieldError{Field: "wrong", Code: fieldCodeUnknown, Detail: "is not a known field"},
+			),
+		},
+		"failed getting scooter because longitude is out of range": {
+			mockRentalServiceHandler: nil,
+			urlQuery:                 outOfRangeURLQuery,
+			clientUUID:               uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode:             http.StatusUnprocessableEntity,
+			expectedBody: problemBody(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating query params.",
+				model.FieldError{
+					Field:  "longitude",
+					Code:   tagLongitude,
+					Detail: "must be a longitude between -180 and 180",
+				},
+			),
 		},
 		"failed getting scooter because redis service threw error while getting scooters": {
 			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
@@ -129,7 +155,19 @@ func TestGetScooters(t *testing.T) {
 			urlQuery:     validURLQuery,
 			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: `{"Message":"Failed getting scooters."}`,
+			expectedBody: problemBody(http.StatusInternalServerError, codeInternal, "Failed getting scooters."),
+		},
+		"failed getting scooter because repository circuit breaker is open": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooters(ctx, rectangle).
+					Return(nil, &resilience.OpenError{RetryAfter: 5 * time.Second}).Times(1)
+			},
+			urlQuery:     validURLQuery,
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: problemBody(
+				http.StatusServiceUnavailable, codeServiceUnavailable, "Service temporarily unavailable.",
+			),
 		},
 	}
 	for name, tt := range tests {
@@ -160,20 +198,22 @@ func TestGetScooters(t *testing.T) {
 }
 
 func TestRentScooter(t *testing.T) {
-	s, mockRentalService, mockTrackerService := beforeTest(t)
-
-	ctx := context.Background()
+	s, mockRentalService, _, _ := beforeTest(t)
 
 	clientUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	ctx := auth.WithIdentity(context.Background(), newTestIdentity(clientUUID))
+
 	scooterUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	longitude, latitude := testLongitude, testLatitude
+
 	scooter := model.RentPost{
 		ScooterUUID: scooterUUID,
-		Longitude:   testLongitude,
-		Latitude:    testLatitude,
+		Longitude:   &longitude,
+		Latitude:    &latitude,
 		City:        testCity,
 	}
 
@@ -183,65 +223,116 @@ func TestRentScooter(t *testing.T) {
 	invalidScooterJSON, err := json.Marshal("invalidScooter")
 	require.NoError(t, err)
 
-	rentInfo := rentalmodel.NewRentInfo(scooter.ScooterUUID.String(), scooter.City)
-	trackerInfo := trackermodel.NewScooter(scooter.ScooterUUID.String(), scooter.City, scooter.Longitude, scooter.Latitude)
+	otherCityScooter := scooter
+	otherCityScooter.City = "Ottawa"
+
+	otherCityScooterJSON, err := json.Marshal(otherCityScooter)
+	require.NoError(t, err)
+
+	// the scooter is rented where it is stored, not where the client claims it is
+	storedScooter := rentalmodel.NewScooter(scooterUUID.String(), testCity, longitude+0.01, latitude+0.01, true)
+
+	rentInfo := rentalmodel.NewRentInfo(
+		scooterUUID.String(),
+		storedScooter.City,
+		storedScooter.Longitude,
+		storedScooter.Latitude,
+	)
+
+	ottawaOperator := &auth.Identity{
+		Subject: clientUUID,
+		Method:  auth.MethodJWT,
+		Roles:   []auth.Role{auth.RoleOperator},
+		Cities:  []string{"Ottawa"},
+	}
 
 	tests := map[string]struct {
-		mockRentalServiceHandler  func(mock *mockrental.MockRentalService)
-		mockTrackerServiceHandler func(mock *mocktracker.MockService)
-		body                      *bytes.Buffer
-		clientUUID                uuid.NullUUID
-		expectedCode              int
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		body                     *bytes.Buffer
+		clientUUID               uuid.NullUUID
+		identity                 *auth.Identity
+		expectedCode             int
 	}{
 		"successfully renting scooter": {
 			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
-				mock.EXPECT().Rent(ctx, rentInfo).Return(nil).Times(1)
-			},
-			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
-				mock.EXPECT().Track(clientUUID, trackerInfo).Return(nil).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(storedScooter, nil).Times(1)
+				mock.EXPECT().Rent(ctx, clientUUID, rentInfo).Return(&rentalmodel.Rental{}, nil).Times(1)
 			},
 			body:         bytes.NewBuffer(scooterJSON),
 			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
 			expectedCode: http.StatusNoContent,
 		},
-		"failed renting scooter because request has no clientUUID in header": {
-			mockRentalServiceHandler:  nil,
-			mockTrackerServiceHandler: nil,
-			body:                      bytes.NewBuffer(scooterJSON),
-			clientUUID:                uuid.NullUUID{Valid: false},
-			expectedCode:              http.StatusBadRequest,
+		"failed renting scooter because request has no authenticated client": {
+			mockRentalServiceHandler: nil,
+			body:                     bytes.NewBuffer(scooterJSON),
+			clientUUID:               uuid.NullUUID{Valid: false},
+			expectedCode:             http.StatusUnauthorized,
 		},
 		"failed renting scooter because request has invalid body": {
-			mockRentalServiceHandler:  nil,
-			mockTrackerServiceHandler: nil,
-			body:                      bytes.NewBuffer(invalidScooterJSON),
-			clientUUID:                uuid.NullUUID{UUID: clientUUID, Valid: true},
-			expectedCode:              http.StatusBadRequest,
+			mockRentalServiceHandler: nil,
+			body:                     bytes.NewBuffer(invalidScooterJSON),
+			clientUUID:               uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode:             http.StatusBadRequest,
+		},
+		"failed renting scooter because it does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(nil, repository.ErrScooterNotFound).Times(1)
+			},
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusNotFound,
+		},
+		"failed renting scooter because the city doesn't match the city of the scooter": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(storedScooter, nil).Times(1)
+			},
+			body:         bytes.NewBuffer(otherCityScooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			identity:     ottawaOperator,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		"failed renting scooter because the operator is scoped to another city": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(storedScooter, nil).Times(1)
+			},
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			identity:     ottawaOperator,
+			expectedCode: http.StatusForbidden,
+		},
+		"failed renting scooter because it is already rented": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(storedScooter, nil).Times(1)
+				mock.EXPECT().Rent(ctx, clientUUID, rentInfo).Return(nil, repository.ErrScooterNotAvailable).Times(1)
+			},
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusConflict,
 		},
 		"failed renting scooter because rental service threw error while renting scooter": {
 			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
-				mock.EXPECT().Rent(ctx, rentInfo).Return(errors.New("")).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(storedScooter, nil).Times(1)
+				mock.EXPECT().Rent(ctx, clientUUID, rentInfo).Return(nil, errors.New("")).Times(1)
 			},
-			mockTrackerServiceHandler: nil,
-			body:                      bytes.NewBuffer(scooterJSON),
-			clientUUID:                uuid.NullUUID{UUID: clientUUID, Valid: true},
-			expectedCode:              http.StatusInternalServerError,
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusInternalServerError,
 		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			request := buildRequest(t, rentPath, http.MethodPost, tt.body, tt.clientUUID)
 
+			if tt.identity != nil {
+				request = request.WithContext(auth.WithIdentity(request.Context(), tt.identity))
+			}
+
 			responseRecorder := httptest.NewRecorder()
 
 			if tt.mockRentalServiceHandler != nil {
 				tt.mockRentalServiceHandler(mockRentalService)
 			}
 
-			if tt.mockTrackerServiceHandler != nil {
-				tt.mockTrackerServiceHandler(mockTrackerService)
-			}
-
 			s.rentScooter(responseRecorder, request)
 
 			if status := responseRecorder.Code; status != tt.expectedCode {
@@ -253,13 +344,13 @@ func TestRentScooter(t *testing.T) {
 }
 
 func TestFreeScooter(t *testing.T) {
-	s, mockRentalService, mockTrackerService := beforeTest(t)
-
-	ctx := context.Background()
+	s, mockRentalService, _, _ := beforeTest(t)
 
 	clientUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
+	ctx := auth.WithIdentity(context.Background(), newTestIdentity(clientUUID))
+
 	scooterUUID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
@@ -273,62 +364,84 @@ func TestFreeScooter(t *testing.T) {
 	invalidScooterJSON, err := json.Marshal("invalidScooter")
 	require.NoError(t, err)
 
+	montrealScooter := rentalmodel.NewScooter(scooterUUID.String(), "Montreal", 73.5673, 45.5017, false)
+
+	ottawaOperator := &auth.Identity{
+		Subject: clientUUID,
+		Method:  auth.MethodJWT,
+		Roles:   []auth.Role{auth.RoleOperator},
+		Cities:  []string{"Ottawa"},
+	}
+
 	tests := map[string]struct {
-		mockRentalServiceHandler  func(mock *mockrental.MockRentalService)
-		mockTrackerServiceHandler func(mock *mocktracker.MockService)
-		body                      *bytes.Buffer
-		clientUUID                uuid.NullUUID
-		expectedCode              int
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		body                     *bytes.Buffer
+		clientUUID               uuid.NullUUID
+		identity                 *auth.Identity
+		expectedCode             int
 	}{
 		"successfully freeing scooter": {
 			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
-				mock.EXPECT().Free(ctx, scooterUUID).Return(nil).Times(1)
-			},
-			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
-				mock.EXPECT().StopTracking(clientUUID, scooterUUID).Return(nil).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(montrealScooter, nil).Times(1)
+				mock.EXPECT().Free(ctx, scooterUUID).Return(nil, nil).Times(1)
 			},
 			body:         bytes.NewBuffer(scooterJSON),
 			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
 			expectedCode: http.StatusNoContent,
 		},
-		"failed freeing scooter because request has no clientUUID in header": {
-			mockRentalServiceHandler:  nil,
-			mockTrackerServiceHandler: nil,
-			body:                      bytes.NewBuffer(scooterJSON),
-			clientUUID:                uuid.NullUUID{Valid: false},
-			expectedCode:              http.StatusBadRequest,
+		"failed freeing scooter because request has no authenticated client": {
+			mockRentalServiceHandler: nil,
+			body:                     bytes.NewBuffer(scooterJSON),
+			clientUUID:               uuid.NullUUID{Valid: false},
+			expectedCode:             http.StatusUnauthorized,
 		},
 		"failed freeing scooter because request has invalid body": {
-			mockRentalServiceHandler:  nil,
-			mockTrackerServiceHandler: nil,
-			body:                      bytes.NewBuffer(invalidScooterJSON),
-			clientUUID:                uuid.NullUUID{UUID: clientUUID, Valid: true},
-			expectedCode:              http.StatusBadRequest,
+			mockRentalServiceHandler: nil,
+			body:                     bytes.NewBuffer(invalidScooterJSON),
+			clientUUID:               uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode:             http.StatusBadRequest,
+		},
+		"failed freeing scooter because it does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(nil, repository.ErrScooterNotFound).Times(1)
+			},
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusNotFound,
+		},
+		"failed freeing scooter because the operator is scoped to another city": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(montrealScooter, nil).Times(1)
+			},
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			identity:     ottawaOperator,
+			expectedCode: http.StatusForbidden,
 		},
 		"failed freeing scooter because rental service threw error while renting scooter": {
 			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
-				mock.EXPECT().Free(ctx, scooterUUID).Return(errors.New("")).Times(1)
+				mock.EXPECT().GetScooter(ctx, scooterUUID).Return(montrealScooter, nil).Times(1)
+				mock.EXPECT().Free(ctx, scooterUUID).Return(nil, errors.New("")).Times(1)
 			},
-			mockTrackerServiceHandler: nil,
-			body:                      bytes.NewBuffer(scooterJSON),
-			clientUUID:                uuid.NullUUID{UUID: clientUUID, Valid: true},
-			expectedCode:              http.StatusInternalServerError,
+			body:         bytes.NewBuffer(scooterJSON),
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusInternalServerError,
 		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			request := buildRequest(t, freePath, http.MethodPost, tt.body, tt.clientUUID)
 
+			if tt.identity != nil {
+				request = request.WithContext(auth.WithIdentity(request.Context(), tt.identity))
+			}
+
 			responseRecorder := httptest.NewRecorder()
 
 			if tt.mockRentalServiceHandler != nil {
 				tt.mockRentalServiceHandler(mockRentalService)
 			}
 
-			if tt.mockTrackerServiceHandler != nil {
-				tt.mockTrackerServiceHandler(mockTrackerService)
-			}
-
 			s.freeScooter(responseRecorder, request)
 
 			if status := responseRecorder.Code; status != tt.expectedCode {
@@ -339,18 +452,22 @@ func TestFreeScooter(t *testing.T) {
 	}
 }
 
-func beforeTest(t *testing.T) (*Server, *mockrental.MockRentalService, *mocktracker.MockService) {
+func beforeTest(t *testing.T) (
+	*Server,
+	*mockrental.MockRentalService,
+	*mocktracker.MockService,
+	*mockuser.MockService,
+) {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	validate := validator.New()
+	validate := NewValidator()
 
 	controller := gomock.NewController(t)
 	httpRouter := mux.NewRouter()
 
 	mockRentalService := mockrental.NewMockRentalService(controller)
 	mockTrackerService := mocktracker.NewMockService(controller)
-
-	users := make(map[string]bool)
+	mockUserService := mockuser.NewMockService(controller)
 
 	s := NewServer(
 		logger,
@@ -362,10 +479,17 @@ func beforeTest(t *testing.T) (*Server, *mockrental.MockRentalService, *mocktrac
 		httpRouter,
 		mockRentalService,
 		mockTrackerService,
-		users,
+		livemap.NewFeed(),
+		mockUserService,
+		nil,
+		auth.NewAuthenticator(nil, testAdminToken, true),
+		nil,
+		health.NewService(time.Second),
+		0,
+		new(slog.LevelVar),
 	)
 
-	return s, mockRentalService, mockTrackerService
+	return s, mockRentalService, mockTrackerService, mockUserService
 }
 
 func buildRequest(t *testing.T, path, method string, body *bytes.Buffer, clientUUID uuid.NullUUID) *http.Request {
@@ -380,8 +504,45 @@ func buildRequest(t *testing.T, path, method string, body *bytes.Buffer, clientU
 	require.NoErrorf(t, err, "Building new request")
 
 	if clientUUID.Valid {
-		request.Header.Set("Client-Id", clientUUID.UUID.String())
+		request = request.WithContext(auth.WithIdentity(request.Context(), newTestIdentity(clientUUID.UUID)))
 	}
 
 	return request
 }
+
+func newTestIdentity(clientUUID uuid.UUID) *auth.Identity {
+	return &auth.Identity{
+		Subject: clientUUID,
+		Method:  auth.MethodClientID,
+		Roles:   []auth.Role{auth.RoleRider},
+	}
+}
+
+// problemBody returns the problem details response body written for a request without an ID.
+func problemBody(statusCode int, code, detail string, fieldErrors ...model.FieldError) string {
+	return problemBodyWithRequestID(statusCode, code, detail, "", fieldErrors...)
+}
+
+// problemBodyWithRequestID returns the problem details response body written for the request with the ID.
+func problemBodyWithRequestID(
+	statusCode int,
+	code, detail, requestID string,
+	fieldErrors ...model.FieldError,
+) string {
+	body := fmt.Sprintf(
+		`{"type":"about:blank","title":%q,"status":%d,"detail":%q,"code":%q`,
+		http.StatusText(statusCode), statusCode, detail, code,
+	)
+
+	if requestID != "" {
+		body += fmt.Sprintf(`,"requestId":%q`, requestID)
+	}
+
+	if len(fieldErrors) > 0 {
+		fieldErrorsJSON, _ := json.Marshal(fieldErrors)
+
+		body += `,"errors":` + string(fieldErrorsJSON)
+	}
+
+	return body + "}"
+}
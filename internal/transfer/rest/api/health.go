@@ -0,0 +1,45 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const statusAlive = "alive"
+
+// liveness reports that the process is up and able to serve HTTP requests. It does not check any dependencies, so
+// a failing Redis does not get the process restarted.
+func (s *Server) liveness(w http.ResponseWriter, _ *http.Request) {
+	JSON(w, http.StatusOK, model.HealthGet{Status: statusAlive})
+}
+
+// readiness reports whether the service and its dependencies are able to handle traffic, with the details of every
+// checked component.
+func (s *Server) readiness(w http.ResponseWriter, r *http.Request) {
+	report := s.health.Readiness(r.Context())
+
+	response := model.HealthGet{
+		Status:     report.Status,
+		Components: make(map[string]model.ComponentHealthGet, len(report.Components)),
+	}
+
+	for name, component := range report.Components {
+		response.Components[name] = model.ComponentHealthGet{
+			Status: component.Status,
+			Error:  component.Error,
+		}
+	}
+
+	if !report.Ready() {
+		logging.FromContext(r.Context()).Warn("service is not ready", slog.Any("components", response.Components))
+
+		JSON(w, http.StatusServiceUnavailable, response)
+
+		return
+	}
+
+	JSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := map[string]struct {
+		check        func(ctx context.Context) error
+		shuttingDown bool
+		expectedCode int
+		expectedBody string
+	}{
+		"service is ready": {
+			check: func(ctx context.Context) error {
+				return nil
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ready","components":{"redis":{"status":"up"}}}`,
+		},
+		"service is not ready, because redis is down": {
+			check: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"not_ready","components":{"redis":{"status":"down","error":"connection refused"}}}`,
+		},
+		"service is not ready, because it is shutting down": {
+			check: func(ctx context.Context) error {
+				return nil
+			},
+			shuttingDown: true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"shutting_down"}`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, _, _ := beforeTest(t)
+
+			s.health.Register("redis", tt.check)
+
+			if tt.shuttingDown {
+				s.health.SetShuttingDown()
+			}
+
+			request := httptest.NewRequest(http.MethodGet, readyzPath, nil)
+			responseRecorder := httptest.NewRecorder()
+
+			s.router.ServeHTTP(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	s, _, _, _ := beforeTest(t)
+
+	s.health.SetShuttingDown()
+
+	request := httptest.NewRequest(http.MethodGet, healthzPath, nil)
+	responseRecorder := httptest.NewRecorder()
+
+	s.router.ServeHTTP(responseRecorder, request)
+
+	if status := responseRecorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got = %v want = %v", status, http.StatusOK)
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/livemap"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	liveMapSnapshot = "snapshot"
+
+	// liveMapPingInterval is how often the clients are pinged, they are dropped when they don't answer within
+	// liveMapPongTimeout.
+	liveMapPingInterval = 30 * time.Second
+	liveMapPongTimeout  = 2 * liveMapPingInterval
+	liveMapWriteTimeout = 10 * time.Second
+
+	// liveMapReadLimit bounds the messages of the clients, which aren't expected to send anything but control frames.
+	liveMapReadLimit = 512
+)
+
+var upgrader = websocket.Upgrader{}
+
+// watchScooters upgrades the connection to a WebSocket sending the scooters in the viewport and then their changes:
+// the scooters entering and leaving the viewport, moving within it and getting rented or freed. To watch another
+// viewport the client connects again.
+//
+//	@Summary	Watches the scooters in the viewport over a WebSocket.
+//	@Tags		scooters
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id		header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		longitude		query		number	true	"Longitude of the center of the viewport"	minimum(-180)	maximum(180)
+//	@Param		latitude		query		number	true	"Latitude of the center of the viewport"	minimum(-90)	maximum(90)
+//	@Param		height			query		number	true	"Height of the viewport in meters"			minimum(0)
+//	@Param		width			query		number	true	"Width of the viewport in meters"			minimum(0)
+//	@Param		city			query		string	true	"City of the viewport"						maxlength(100)
+//	@Param		availability	query		bool	false	"Availability of the scooters shown"
+//
+//	@Success	101				{object}	model.LiveMapEvent	"snapshot message followed by the event messages"
+//	@Failure	400				{object}	model.Problem
+//	@Failure	401				{object}	model.Problem
+//	@Failure	403				{object}	model.Problem
+//	@Failure	422				{object}	model.Problem
+//	@Failure	429				{object}	model.Problem
+//	@Failure	500				{object}	model.Problem
+//	@Failure	503				{object}	model.Problem
+//	@Router		/v1/scooters/live [get]
+func (s *Server) watchScooters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	var queryParams model.ScooterQueryParams
+
+	if !s.decodeQuery(w, r, &queryParams, "Failed decoding query params.") {
+		return
+	}
+
+	if !authorizeCity(w, r, queryParams.City) {
+		return
+	}
+
+	geoRectangle := rentalmodel.NewRectangle(
+		queryParams.City,
+		*queryParams.Longitude,
+		*queryParams.Latitude,
+		queryParams.Height,
+		queryParams.Width,
+	)
+
+	ctxLogger = ctxLogger.With(slog.String("city", queryParams.City))
+
+	// subscribe before taking the snapshot, so no change made in between is missed
+	changes, unsubscribe := s.liveMap.Subscribe(queryParams.City)
+	defer unsubscribe()
+
+	scooters, err := s.rentalService.GetScooters(ctx, geoRectangle)
+	if err != nil {
+		ctxLogger.Error("failed to get scooters from rental service", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting scooters.")
+
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already answered the client
+		ctxLogger.Error("failed to upgrade connection", slog.Any("err", err))
+
+		return
+	}
+	defer conn.Close()
+
+	ctxLogger.Info("Watching scooters.")
+
+	viewport := livemap.NewViewport(geoRectangle, queryParams.Availability)
+
+	snapshot := viewport.Snapshot(scooters)
+
+	message := model.LiveMapSnapshot{Type: liveMapSnapshot, Scooters: make([]model.ScooterGet, 0, len(snapshot))}
+
+	for _, scooter := range snapshot {
+		scooterUUID, parseErr := uuid.Parse(scooter.Name)
+		if parseErr != nil {
+			ctxLogger.Error("failed to parse scooterID to ScooterUUID", slog.Any("err", parseErr))
+
+			closeLiveMap(conn, websocket.CloseInternalServerErr, "Failed parsing scooterID.")
+
+			return
+		}
+
+		message.Scooters = append(message.Scooters, toScooterGet(scooterUUID, scooter))
+	}
+
+	if err = writeLiveMap(conn, message); err != nil {
+		ctxLogger.Warn("Failed to send live map snapshot.", slog.Any("err", err))
+
+		return
+	}
+
+	disconnected := readLiveMap(conn)
+
+	ping := time.NewTicker(liveMapPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-disconnected:
+			ctxLogger.Info("Client stopped watching scooters.")
+
+			return
+		case <-s.closing:
+			closeLiveMap(conn, websocket.CloseGoingAway, "Server is shutting down.")
+
+			return
+		case <-ping.C:
+			if err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveMapWriteTimeout)); err != nil {
+				ctxLogger.Warn("Failed to ping live map client.", slog.Any("err", err))
+
+				return
+			}
+		case scooter, open := <-changes:
+			if !open {
+				ctxLogger.Warn("Live map client fell behind the changes.")
+
+				closeLiveMap(conn, websocket.CloseTryAgainLater, "Client fell behind, connect again.")
+
+				return
+			}
+
+			event, visible := viewport.Apply(scooter)
+			if !visible {
+				continue
+			}
+
+			scooterUUID, parseErr := uuid.Parse(scooter.Name)
+			if parseErr != nil {
+				ctxLogger.Warn("Failed to parse the UUID of the changed scooter.", slog.Any("err", parseErr))
+
+				continue
+			}
+
+			err = writeLiveMap(conn, model.LiveMapEvent{
+				Type:    string(event.Type),
+				Scooter: toScooterGet(scooterUUID, event.Scooter),
+			})
+			if err != nil {
+				ctxLogger.Warn("Failed to send live map event.", slog.Any("err", err))
+
+				return
+			}
+		}
+	}
+}
+
+// readLiveMap reads the connection in the background, as the control frames are handled only while reading, and
+// returns the channel closed once the client disconnects or stops answering the pings.
+func readLiveMap(conn *websocket.Conn) <-chan struct{} {
+	disconnected := make(chan struct{})
+
+	conn.SetReadLimit(liveMapReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(liveMapPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(liveMapPongTimeout))
+	})
+
+	go func() {
+		defer close(disconnected)
+
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return disconnected
+}
+
+func writeLiveMap(conn *websocket.Conn, message any) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(liveMapWriteTimeout))
+
+	return conn.WriteJSON(message)
+}
+
+// closeLiveMap tells the client why the connection is closed, not waiting for its answer.
+func closeLiveMap(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(liveMapWriteTimeout),
+	)
+}
@@ -0,0 +1,138 @@
+//go:build unit
+
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	mockrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+func TestWatchScooters(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooter := rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, true)
+	rectangle := rentalmodel.NewRectangle(testCity, testLongitude, testLatitude, testHeight, testWidth)
+
+	rentedScooter := rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, false)
+
+	validURLQuery := url.Values{}
+	validURLQuery.Add("longitude", strconv.FormatFloat(testLongitude, 'f', -1, 64))
+	validURLQuery.Add("latitude", strconv.FormatFloat(testLatitude, 'f', -1, 64))
+	validURLQuery.Add("height", strconv.FormatFloat(testHeight, 'f', -1, 64))
+	validURLQuery.Add("width", strconv.FormatFloat(testWidth, 'f', -1, 64))
+	validURLQuery.Add("city", testCity)
+
+	invalidURLQuery := url.Values{}
+	invalidURLQuery.Add("wrong", "wrong")
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		urlQuery                 url.Values
+		expectedCode             int
+		expectedBody             string
+		expectedSnapshot         *model.LiveMapSnapshot
+		expectedEvent            *model.LiveMapEvent
+	}{
+		"successfully watched scooters": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooters(gomock.Any(), rectangle).
+					Return([]*rentalmodel.Scooter{scooter}, nil).Times(1)
+			},
+			urlQuery:     validURLQuery,
+			expectedCode: http.StatusSwitchingProtocols,
+			expectedSnapshot: &model.LiveMapSnapshot{
+				Type:     liveMapSnapshot,
+				Scooters: []model.ScooterGet{toScooterGet(scooterUUID, scooter)},
+			},
+			expectedEvent: &model.LiveMapEvent{
+				Type:    "state",
+				Scooter: toScooterGet(scooterUUID, rentedScooter),
+			},
+		},
+		"failed watching scooters because of invalid query params": {
+			urlQuery:     invalidURLQuery,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(
+				http.StatusBadRequest,
+				codeMalformedRequest,
+				"Failed decoding query params.",
+				model.FieldError{Field: "wrong", Code: fieldCodeUnknown, Detail: "is not a known field"},
+			),
+		},
+		"failed watching scooters because rental service threw error": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooters(gomock.Any(), rectangle).Return(nil, redis.ErrClosed).Times(1)
+			},
+			urlQuery:     validURLQuery,
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: problemBody(http.StatusInternalServerError, codeInternal, "Failed getting scooters."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, _, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r = r.WithContext(auth.WithIdentity(r.Context(), newTestIdentity(clientUUID)))
+
+				s.watchScooters(w, r)
+			}))
+			defer server.Close()
+
+			address := "ws" + strings.TrimPrefix(server.URL, "http") + version + liveScootersPath +
+				"?" + tt.urlQuery.Encode()
+
+			conn, response, dialErr := websocket.DefaultDialer.Dial(address, nil)
+			require.Equal(t, tt.expectedCode, response.StatusCode)
+
+			if tt.expectedSnapshot == nil {
+				require.ErrorIs(t, dialErr, websocket.ErrBadHandshake)
+
+				var body strings.Builder
+
+				_, err = io.Copy(&body, response.Body)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedBody, body.String())
+
+				return
+			}
+
+			require.NoError(t, dialErr)
+			defer conn.Close()
+
+			var snapshot model.LiveMapSnapshot
+			require.NoError(t, conn.ReadJSON(&snapshot))
+			require.Equal(t, *tt.expectedSnapshot, snapshot)
+
+			s.liveMap.Publish(rentedScooter)
+
+			var event model.LiveMapEvent
+			require.NoError(t, conn.ReadJSON(&event))
+			require.Equal(t, *tt.expectedEvent, event)
+		})
+	}
+}
@@ -1,25 +1,182 @@
 package api
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/ratelimit"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user"
+)
+
+const (
+	headerRequestID       = "X-Request-Id"
+	headerAuthorization   = "Authorization"
+	headerClientID        = "Client-Id"
+	headerWWWAuthenticate = "WWW-Authenticate"
+	headerDeprecation     = "Deprecation"
+	headerLink            = "Link"
+	headerLocation        = "Location"
+	bearerPrefix          = "Bearer "
+
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+
+	maxRequestIDLength = 128
 )
 
-func AuthenticateUser(h http.HandlerFunc, logger *slog.Logger, users map[string]bool) http.HandlerFunc {
+// LogRequests assigns every request an ID (reusing the one sent in the X-Request-Id header), puts a logger carrying
+// it into the request context and writes an access log line once the request is handled.
+func LogRequests(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+
+			requestID := request.Header.Get(headerRequestID)
+			if !isValidRequestID(requestID) {
+				requestID = uuid.NewString()
+			}
+
+			writer.Header().Set(headerRequestID, requestID)
+
+			requestLogger := logger.With(
+				slog.String("request_id", requestID),
+				slog.String("method", request.Method),
+				slog.String("path", request.URL.Path),
+			)
+
+			recorder := &statusRecorder{ResponseWriter: writer, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, request.WithContext(logging.WithLogger(request.Context(), requestLogger)))
+
+			requestLogger.Info(
+				"request handled",
+				slog.Int("status", recorder.status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int("bytes", recorder.bytes),
+				slog.String("remote_addr", request.RemoteAddr),
+			)
+		})
+	}
+}
+
+// AuthenticateUser resolves the identity of the caller from the bearer token or, when enabled for the simulator, from
+// the Client-Id header and puts it into the request context. Apart from the admin token, the identity has to belong
+// to a registered and active user.
+func AuthenticateUser(h http.HandlerFunc, authenticator *auth.Authenticator, users user.Service) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		clientUUID, err := clientUUIDFromHeader(request)
+		ctx := request.Context()
+		logger := logging.FromContext(ctx)
+
+		bearerToken, _ := strings.CutPrefix(request.Header.Get(headerAuthorization), bearerPrefix)
+
+		identity, err := authenticator.Authenticate(ctx, bearerToken, request.Header.Get(headerClientID))
 		if err != nil {
-			logger.Error("Failed to parse the clientID", slog.Any("err", err))
+			logger.Error("Failed to authenticate user", slog.Any("err", err))
+
+			writer.Header().Set(headerWWWAuthenticate, bearerChallenge(err))
 
-			Error(writer, http.StatusForbidden, "Failed getting clientID from header.")
+			Error(writer, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
 
 			return
 		}
 
-		if _, ok := users[clientUUID.String()]; !ok {
-			logger.Error("Failed to authenticate user", slog.Any("err", err))
+		logger = logger.With(
+			slog.String("client_id", identity.Subject.String()),
+			slog.String("auth_method", identity.Method),
+		)
+
+		if identity.Method != auth.MethodAdminToken {
+			registeredUser, userErr := users.GetUser(ctx, identity.Subject)
+
+			switch {
+			case errors.Is(userErr, service.ErrUserNotFound):
+				logger.Error("Failed to authenticate unregistered user")
+
+				forbidden(writer, &auth.DeniedError{Reason: auth.ReasonUserNotRegistered})
+
+				return
+			case userErr != nil:
+				logger.Error("Failed to get user", slog.Any("err", userErr))
+
+				domainError(writer, userErr, "Failed authenticating client.")
+
+				return
+			case !registeredUser.Active():
+				logger.Error("Failed to authenticate suspended user")
+
+				forbidden(writer, &auth.DeniedError{Reason: auth.ReasonUserSuspended})
+
+				return
+			}
+		}
+
+		h(writer, request.WithContext(auth.WithIdentity(logging.WithLogger(ctx, logger), identity)))
+	}
+}
+
+// AuthenticateDevice resolves the identity of the scooter from the bearer token and puts it into the request context.
+// The scooters are not users, so neither the Client-Id header nor the registration of the subject is checked.
+func AuthenticateDevice(h http.HandlerFunc, authenticator *auth.Authenticator) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		logger := logging.FromContext(ctx)
+
+		bearerToken, _ := strings.CutPrefix(request.Header.Get(headerAuthorization), bearerPrefix)
+
+		identity, err := authenticator.Authenticate(ctx, bearerToken, "")
+		if err != nil {
+			logger.Error("Failed to authenticate device", slog.Any("err", err))
 
-			Error(writer, http.StatusForbidden, "Failed authenticating client.")
+			writer.Header().Set(headerWWWAuthenticate, bearerChallenge(err))
+
+			Error(writer, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+			return
+		}
+
+		logger = logger.With(
+			slog.String("client_id", identity.Subject.String()),
+			slog.String("auth_method", identity.Method),
+		)
+
+		h(writer, request.WithContext(auth.WithIdentity(logging.WithLogger(ctx, logger), identity)))
+	}
+}
+
+// Authorize lets through only the requests of identities granted the permission by any of their roles. It has to
+// wrap a handler already wrapped by AuthenticateUser or AuthenticateDevice.
+func Authorize(h http.HandlerFunc, permission auth.Permission) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		logger := logging.FromContext(request.Context())
+
+		identity, ok := auth.IdentityFromContext(request.Context())
+		if !ok {
+			logger.Error("Failed to authorize request without identity")
+
+			Error(writer, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+			return
+		}
+
+		if err := identity.Authorize(permission); err != nil {
+			logger.Error("Failed to authorize user", slog.Any("err", err))
+
+			forbidden(writer, err)
 
 			return
 		}
@@ -27,3 +184,204 @@ func AuthenticateUser(h http.HandlerFunc, logger *slog.Logger, users map[string]
 		h(writer, request)
 	}
 }
+
+// authorizeCity checks whether the authenticated client may act in the city, responding with 403 if it may not.
+func authorizeCity(w http.ResponseWriter, r *http.Request, city string) bool {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok {
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return false
+	}
+
+	if err := identity.AuthorizeCity(city); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to authorize user in city", slog.Any("err", err))
+
+		forbidden(w, err)
+
+		return false
+	}
+
+	return true
+}
+
+// authorizeScooter checks whether the authenticated client may act as the scooter, responding with 403 if it may not.
+func authorizeScooter(w http.ResponseWriter, r *http.Request, scooterUUID uuid.UUID) bool {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok {
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return false
+	}
+
+	if err := identity.AuthorizeScooter(scooterUUID); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to authorize device as scooter", slog.Any("err", err))
+
+		forbidden(w, err)
+
+		return false
+	}
+
+	return true
+}
+
+// LimitRate lets through only the requests within the budget of the client in the bucket, telling the client about
+// its budget in the RateLimit headers. Authenticated clients are told apart by their identity and limited by their
+// roles, the others by their address and limited as riders. When the limiter fails, the request is let through.
+func LimitRate(h http.HandlerFunc, limiter *ratelimit.Limiter, bucket ratelimit.Bucket) http.HandlerFunc {
+	if limiter == nil {
+		return h
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		logger := logging.FromContext(ctx)
+
+		clientKey, roles := rateLimitClient(request)
+
+		limit, limited := limiter.LimitFor(bucket, roles)
+		if !limited {
+			h(writer, request)
+
+			return
+		}
+
+		result, err := limiter.Allow(ctx, bucket, clientKey, limit)
+		if err != nil {
+			logger.Warn("Failed to check the rate limit, letting the request through.", slog.Any("err", err))
+
+			h(writer, request)
+
+			return
+		}
+
+		resetSeconds := strconv.Itoa(max(int(math.Ceil(result.Reset.Seconds())), 1))
+
+		writer.Header().Set(headerRateLimitLimit, strconv.Itoa(limit.Requests))
+		writer.Header().Set(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+		writer.Header().Set(headerRateLimitReset, resetSeconds)
+		writer.Header().Set(
+			headerRateLimitPolicy,
+			fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Window.Seconds())),
+		)
+
+		if !result.Allowed {
+			logger.Warn("Rate limit exceeded.", slog.String("bucket", string(bucket)))
+
+			writer.Header().Set(headerRetryAfter, resetSeconds)
+
+			Error(writer, http.StatusTooManyRequests, codeRateLimitExceeded, "Too many requests.")
+
+			return
+		}
+
+		h(writer, request)
+	}
+}
+
+// Deprecate marks the responses of the route as deprecated, pointing the clients at the API succeeding it.
+func Deprecate(h http.HandlerFunc, successor string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(headerDeprecation, "true")
+		writer.Header().Set(headerLink, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+
+		h(writer, request)
+	}
+}
+
+// rateLimitClient returns the key telling the client apart and its roles.
+func rateLimitClient(request *http.Request) (string, []auth.Role) {
+	if identity, ok := auth.IdentityFromContext(request.Context()); ok {
+		if identity.Method == auth.MethodAdminToken {
+			return auth.MethodAdminToken, identity.Roles
+		}
+
+		return identity.Subject.String(), identity.Roles
+	}
+
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+
+	return "addr:" + host, []auth.Role{auth.RoleRider}
+}
+
+// bearerChallenge builds the WWW-Authenticate header value telling the client whether its token was rejected or
+// missing, as described in RFC 6750.
+func bearerChallenge(err error) string {
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return `Bearer error="invalid_token"`
+	}
+
+	return "Bearer"
+}
+
+// isValidRequestID accepts IDs generated by other services as long as they are reasonably short and printable, so
+// they can't break the log lines.
+func isValidRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statusRecorder remembers the status code and the size of the response written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	if !sr.wroteHeader {
+		sr.status = statusCode
+		sr.wroteHeader = true
+	}
+
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	sr.wroteHeader = true
+
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+
+	return n, err
+}
+
+// Flush lets streaming handlers push the data to the client through the recorder.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the WebSocket handlers take over the connection through the recorder, logged as switching protocols.
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, readWriter, err := http.NewResponseController(sr.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, fmt.Errorf("hijacking connection: %w", err)
+	}
+
+	if !sr.wroteHeader {
+		sr.status = http.StatusSwitchingProtocols
+		sr.wroteHeader = true
+	}
+
+	return conn, readWriter, nil
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
@@ -8,10 +8,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-redis/redismock/v9"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/ratelimit"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	mockuser "github.com/PatrykPasterny/scooter-rental/internal/service/user/mock"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+)
+
+const (
+	testHMACKey  = "test-hmac-key"
+	testIssuer   = "https://auth.scootin-aboot.test"
+	testAudience = "scooter-rental"
 )
 
 type testResponseWriter struct {
@@ -49,53 +68,100 @@ func wrapHandlerFunction(t *testing.T, wrappedHandler http.Handler, wantStatus i
 }
 
 func TestAuthenticateUser(t *testing.T) {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-
 	userUUID, err := uuid.NewUUID()
 	require.NoError(t, err)
 
-	wrongUserUUID, err := uuid.NewUUID()
+	unknownUserUUID, err := uuid.NewUUID()
 	require.NoError(t, err)
 
+	activeUser := usermodel.NewUser(userUUID, usermodel.Profile{}, time.Now())
+
+	suspendedUser := usermodel.NewUser(userUUID, usermodel.Profile{}, time.Now())
+	suspendedUser.Status = usermodel.StatusSuspended
+
 	correctHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+		if _, ok := auth.IdentityFromContext(r.Context()); !ok {
+			t.Errorf("AuthenticateUser() passed request without identity")
+		}
 
-	wrongUserHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	noHeaderHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+	keyPath := filepath.Join(t.TempDir(), "hmac.key")
+	require.NoError(t, os.WriteFile(keyPath, []byte(testHMACKey), 0o600))
 
-	users := map[string]bool{
-		userUUID.String(): true,
-	}
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.LoadHMACKey(keyPath))
+
+	verifier := auth.NewJWTVerifier(keys, testIssuer, testAudience, 0)
 
 	tests := map[string]struct {
-		h          http.HandlerFunc
-		clientID   uuid.UUID
-		wantStatus int
+		mockUserServiceHandler func(mock *mockuser.MockService)
+		clientID               uuid.UUID
+		bearerToken            string
+		allowClientID          bool
+		wantStatus             int
 	}{
 		"successfully processed ": {
-			h:          correctHandler,
-			clientID:   userUUID,
-			wantStatus: http.StatusOK,
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().GetUser(gomock.Any(), userUUID).Return(activeUser, nil).Times(1)
+			},
+			clientID:      userUUID,
+			allowClientID: true,
+			wantStatus:    http.StatusOK,
+		},
+		"successfully processed with bearer token": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().GetUser(gomock.Any(), userUUID).Return(activeUser, nil).Times(1)
+			},
+			bearerToken: signTestToken(t, userUUID.String(), time.Hour),
+			wantStatus:  http.StatusOK,
+		},
+		"successfully processed with admin token": {
+			bearerToken: testAdminToken,
+			wantStatus:  http.StatusOK,
+		},
+		"failed due to lacking credentials": {
+			clientID:      uuid.Nil,
+			allowClientID: true,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		"failed due to the fact that user in header is not registered": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().GetUser(gomock.Any(), unknownUserUUID).Return(nil, service.ErrUserNotFound).Times(1)
+			},
+			clientID:      unknownUserUUID,
+			allowClientID: true,
+			wantStatus:    http.StatusForbidden,
+		},
+		"failed due to the fact that user is suspended": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().GetUser(gomock.Any(), userUUID).Return(suspendedUser, nil).Times(1)
+			},
+			bearerToken: signTestToken(t, userUUID.String(), time.Hour),
+			wantStatus:  http.StatusForbidden,
 		},
-		"failed due to lacking clientID in header": {
-			h:          noHeaderHandler,
-			clientID:   uuid.Nil,
-			wantStatus: http.StatusForbidden,
+		"failed due to client id header being disabled": {
+			clientID:      userUUID,
+			allowClientID: false,
+			wantStatus:    http.StatusUnauthorized,
 		},
-		"failed due to the fact that user in header is not in users map": {
-			h:          wrongUserHandler,
-			clientID:   wrongUserUUID,
-			wantStatus: http.StatusForbidden,
+		"failed due to expired bearer token": {
+			bearerToken: signTestToken(t, userUUID.String(), -time.Minute),
+			wantStatus:  http.StatusUnauthorized,
 		},
 	}
 	for tName, tt := range tests {
 		t.Run(tName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			mockUserService := mockuser.NewMockService(controller)
+
+			if tt.mockUserServiceHandler != nil {
+				tt.mockUserServiceHandler(mockUserService)
+			}
+
 			request, innerErr := http.NewRequestWithContext(context.Background(), http.MethodGet, "test", nil)
 			require.NoError(t, innerErr)
 
@@ -103,13 +169,296 @@ func TestAuthenticateUser(t *testing.T) {
 				request.Header.Set("Client-Id", tt.clientID.String())
 			}
 
+			if tt.bearerToken != "" {
+				request.Header.Set(headerAuthorization, bearerPrefix+tt.bearerToken)
+			}
+
 			responseRecorder := httptest.NewRecorder()
 
+			authenticator := auth.NewAuthenticator(verifier, testAdminToken, tt.allowClientID)
+
 			wrapHandlerFunction(
 				t,
-				AuthenticateUser(tt.h, logger, users),
+				AuthenticateUser(correctHandler, authenticator, mockUserService),
 				tt.wantStatus,
 			).ServeHTTP(responseRecorder, request)
+
+			if tt.wantStatus == http.StatusUnauthorized && responseRecorder.Header().Get(headerWWWAuthenticate) == "" {
+				t.Errorf("AuthenticateUser() responded without %s header", headerWWWAuthenticate)
+			}
+		})
+	}
+}
+
+func TestAuthenticateDevice(t *testing.T) {
+	scooterUUID := uuid.New()
+
+	correctHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.IdentityFromContext(r.Context()); !ok {
+			t.Errorf("AuthenticateDevice() passed request without identity")
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	keyPath := filepath.Join(t.TempDir(), "hmac.key")
+	require.NoError(t, os.WriteFile(keyPath, []byte(testHMACKey), 0o600))
+
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.LoadHMACKey(keyPath))
+
+	authenticator := auth.NewAuthenticator(auth.NewJWTVerifier(keys, testIssuer, testAudience, 0), testAdminToken, true)
+
+	tests := map[string]struct {
+		clientID    uuid.UUID
+		bearerToken string
+		wantStatus  int
+	}{
+		"successfully processed with bearer token of unregistered subject": {
+			bearerToken: signTestToken(t, scooterUUID.String(), time.Hour),
+			wantStatus:  http.StatusOK,
+		},
+		"successfully processed with admin token": {
+			bearerToken: testAdminToken,
+			wantStatus:  http.StatusOK,
+		},
+		"failed due to client id header not being accepted from devices": {
+			clientID:   scooterUUID,
+			wantStatus: http.StatusUnauthorized,
+		},
+		"failed due to expired bearer token": {
+			bearerToken: signTestToken(t, scooterUUID.String(), -time.Minute),
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "test", nil)
+			require.NoError(t, err)
+
+			if tt.clientID != uuid.Nil {
+				request.Header.Set("Client-Id", tt.clientID.String())
+			}
+
+			if tt.bearerToken != "" {
+				request.Header.Set(headerAuthorization, bearerPrefix+tt.bearerToken)
+			}
+
+			responseRecorder := httptest.NewRecorder()
+
+			wrapHandlerFunction(
+				t,
+				AuthenticateDevice(correctHandler, authenticator),
+				tt.wantStatus,
+			).ServeHTTP(responseRecorder, request)
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := map[string]struct {
+		identity     *auth.Identity
+		permission   auth.Permission
+		wantStatus   int
+		expectedBody string
+	}{
+		"successfully authorized": {
+			identity:   &auth.Identity{Subject: uuid.New(), Roles: []auth.Role{auth.RoleOperator}},
+			permission: auth.PermissionScootersFree,
+			wantStatus: http.StatusOK,
+		},
+		"failed due to missing permission": {
+			identity:     &auth.Identity{Subject: uuid.New(), Roles: []auth.Role{auth.RoleOperator}},
+			permission:   auth.PermissionScootersRent,
+			wantStatus:   http.StatusForbidden,
+			expectedBody: problemBody(http.StatusForbidden, auth.ReasonMissingPermission, "Permission denied."),
+		},
+		"failed due to missing identity": {
+			identity:     nil,
+			permission:   auth.PermissionScootersRead,
+			wantStatus:   http.StatusUnauthorized,
+			expectedBody: problemBody(http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client."),
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.identity != nil {
+				ctx = auth.WithIdentity(ctx, tt.identity)
+			}
+
+			request, innerErr := http.NewRequestWithContext(ctx, http.MethodGet, "test", nil)
+			require.NoError(t, innerErr)
+
+			responseRecorder := httptest.NewRecorder()
+
+			Authorize(handler, tt.permission).ServeHTTP(responseRecorder, request)
+
+			if responseRecorder.Code != tt.wantStatus {
+				t.Errorf("Authorize() = %d, want %d", responseRecorder.Code, tt.wantStatus)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("Authorize() body = %v, want %v", body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestLimitRate(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	identity := &auth.Identity{Subject: uuid.New(), Roles: []auth.Role{auth.RoleRider}}
+
+	tests := map[string]struct {
+		scriptResult     []interface{}
+		scriptErr        error
+		wantStatus       int
+		wantRemaining    string
+		wantRetryAfter   string
+		wantRateLimitSet bool
+	}{
+		"request within the limit": {
+			scriptResult:     []interface{}{int64(1), int64(1), int64(60000)},
+			wantStatus:       http.StatusOK,
+			wantRemaining:    "9",
+			wantRateLimitSet: true,
+		},
+		"request over the limit": {
+			scriptResult:     []interface{}{int64(0), int64(10), int64(1500)},
+			wantStatus:       http.StatusTooManyRequests,
+			wantRemaining:    "0",
+			wantRetryAfter:   "2",
+			wantRateLimitSet: true,
+		},
+		"request let through when redis fails": {
+			scriptErr:        redis.ErrClosed,
+			wantStatus:       http.StatusOK,
+			wantRateLimitSet: false,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+
+			// the script arguments hold a random member, only the command itself is checked
+			expectation := redisMock.CustomMatch(func(_, _ []interface{}) error { return nil }).
+				ExpectEvalSha("", []string{""}, 0, 0, "")
+			if tt.scriptErr != nil {
+				expectation.SetErr(tt.scriptErr)
+			} else {
+				expectation.SetVal(tt.scriptResult)
+			}
+
+			limiter := ratelimit.NewLimiter(redisClient, time.Minute, map[ratelimit.Bucket]map[auth.Role]int{
+				ratelimit.BucketSearch: {auth.RoleRider: 10},
+			})
+
+			request, innerErr := http.NewRequestWithContext(
+				auth.WithIdentity(context.Background(), identity),
+				http.MethodGet,
+				"test",
+				nil,
+			)
+			require.NoError(t, innerErr)
+
+			responseRecorder := httptest.NewRecorder()
+
+			LimitRate(handler, limiter, ratelimit.BucketSearch).ServeHTTP(responseRecorder, request)
+
+			if responseRecorder.Code != tt.wantStatus {
+				t.Errorf("LimitRate() = %d, want %d", responseRecorder.Code, tt.wantStatus)
+			}
+
+			if got := responseRecorder.Header().Get(headerRateLimitRemaining); got != tt.wantRemaining {
+				t.Errorf("LimitRate() %s = %q, want %q", headerRateLimitRemaining, got, tt.wantRemaining)
+			}
+
+			if got := responseRecorder.Header().Get(headerRetryAfter); got != tt.wantRetryAfter {
+				t.Errorf("LimitRate() %s = %q, want %q", headerRetryAfter, got, tt.wantRetryAfter)
+			}
+
+			if got := responseRecorder.Header().Get(headerRateLimitPolicy) != ""; got != tt.wantRateLimitSet {
+				t.Errorf("LimitRate() set %s = %v, want %v", headerRateLimitPolicy, got, tt.wantRateLimitSet)
+			}
+		})
+	}
+}
+
+func signTestToken(t *testing.T, subject string, expiresIn time.Duration) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    testIssuer,
+		Audience:  jwt.ClaimStrings{testAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+	}).SignedString([]byte(testHMACKey))
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestLogRequests(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := map[string]struct {
+		requestID      string
+		wantSameAsSent bool
+	}{
+		"propagates the request ID sent by the client": {
+			requestID:      "client-request-1",
+			wantSameAsSent: true,
+		},
+		"assigns a request ID when the client did not send one": {
+			requestID:      "",
+			wantSameAsSent: false,
+		},
+		"assigns a request ID when the one sent by the client is invalid": {
+			requestID:      "invalid request id",
+			wantSameAsSent: false,
+		},
+	}
+	for tName, tt := range tests {
+		t.Run(tName, func(t *testing.T) {
+			request, innerErr := http.NewRequestWithContext(context.Background(), http.MethodGet, "test", nil)
+			require.NoError(t, innerErr)
+
+			if tt.requestID != "" {
+				request.Header.Set(headerRequestID, tt.requestID)
+			}
+
+			var handlerLogger *slog.Logger
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerLogger = logging.FromContext(r.Context())
+
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			responseRecorder := httptest.NewRecorder()
+
+			wrapHandlerFunction(t, LogRequests(logger)(handler), http.StatusTeapot).
+				ServeHTTP(responseRecorder, request)
+
+			gotRequestID := responseRecorder.Header().Get(headerRequestID)
+			if gotRequestID == "" {
+				t.Errorf("LogRequests() did not set the %s header", headerRequestID)
+			}
+
+			if (gotRequestID == tt.requestID) != tt.wantSameAsSent {
+				t.Errorf("LogRequests() request ID = %q, sent %q", gotRequestID, tt.requestID)
+			}
+
+			if handlerLogger == nil || handlerLogger == slog.Default() {
+				t.Errorf("LogRequests() did not put a request scoped logger into the context")
+			}
 		})
 	}
 }
@@ -0,0 +1,418 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	scooterIDParam = "scooterID"
+	rentalIDParam  = "rentalID"
+
+	rentalStatusActive = "active"
+	rentalStatusEnded  = "ended"
+
+	scooterStateAvailable = "available"
+	scooterStateRented    = "rented"
+)
+
+// getScooter returns the scooter with its position, state and the time of its last update, found by its ID alone.
+//
+//	@Summary	Gets the scooter.
+//	@Tags		scooters
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		scooterID	path		string	true	"ID of the scooter"							minlength(36)	maxlength(36)
+//
+//	@Success	200			{object}	model.ScooterGet
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/scooters/{scooterID} [get]
+//	@Router		/v2/scooters/{scooterID} [get]
+func (s *Server) getScooter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	scooterUUID, err := uuid.Parse(mux.Vars(r)[scooterIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse scooterID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing scooterID.")
+
+		return
+	}
+
+	rentalScooter, err := s.rentalService.GetScooter(ctx, scooterUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get scooter", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting scooter.")
+
+		return
+	}
+
+	if !authorizeCity(w, r, rentalScooter.City) {
+		return
+	}
+
+	JSON(w, http.StatusOK, toScooterGet(scooterUUID, rentalScooter))
+}
+
+// createRental rents the scooter to the authenticated user, starting the ride where the scooter stands.
+//
+//	@Summary	Rents the scooter.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		scooterID	path		string	true	"ID of the scooter"							minlength(36)	maxlength(36)
+//
+//	@Success	201			{object}	model.RentalGet
+//	@Header		201			{string}	Location	"URL of the rental"
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	409			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v2/scooters/{scooterID}/rentals [post]
+func (s *Server) createRental(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return
+	}
+
+	scooterUUID, err := uuid.Parse(mux.Vars(r)[scooterIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse scooterID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing scooterID.")
+
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("scooter_id", scooterUUID.String()))
+
+	rentalScooter, err := s.rentalService.GetScooter(ctx, scooterUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get scooter", slog.Any("err", err))
+
+		domainError(w, err, "Failed renting scooter.")
+
+		return
+	}
+
+	if !authorizeCity(w, r, rentalScooter.City) {
+		return
+	}
+
+	ctxLogger.Info("Renting scooter.")
+
+	rental, err := s.rentalService.Rent(ctx, clientUUID, rentalmodel.NewRentInfo(
+		rentalScooter.Name,
+		rentalScooter.City,
+		rentalScooter.Longitude,
+		rentalScooter.Latitude,
+	))
+	if err != nil {
+		ctxLogger.Error("failed to rent a scooter", slog.Any("err", err))
+
+		domainError(w, err, "Failed renting scooter.")
+
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rental.UUID.String()))
+
+	ctxLogger.Info("Successfully rented scooter.")
+
+	w.Header().Set(headerLocation, api+version2+"/rentals/"+rental.UUID.String())
+
+	JSON(w, http.StatusCreated, toRentalGet(rental))
+}
+
+// getRental returns the rental of the authenticated user.
+//
+//	@Summary	Gets the rental.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		rentalID	path		string	true	"ID of the rental"							minlength(36)	maxlength(36)
+//
+//	@Success	200			{object}	model.RentalGet
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v2/rentals/{rentalID} [get]
+func (s *Server) getRental(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, rentalUUID, ok := rentalRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rental, err := s.rentalService.GetRental(ctx, clientUUID, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get rental", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting rental.")
+
+		return
+	}
+
+	JSON(w, http.StatusOK, toRentalGet(rental))
+}
+
+// endRental ends the active rental of the authenticated user, making the scooter available.
+//
+//	@Summary	Ends the rental.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		rentalID	path		string	true	"ID of the rental"							minlength(36)	maxlength(36)
+//
+//	@Success	200			{object}	model.RentalGet
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	409			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v2/rentals/{rentalID}/end [post]
+func (s *Server) endRental(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, rentalUUID, ok := rentalRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rentalUUID.String()))
+
+	ctxLogger.Info("Ending rental.")
+
+	rental, err := s.rentalService.EndRental(ctx, clientUUID, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to end rental", slog.Any("err", err))
+
+		domainError(w, err, "Failed ending rental.")
+
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("scooter_id", rental.ScooterUUID.String()))
+
+	ctxLogger.Info("Successfully ended rental.")
+
+	JSON(w, http.StatusOK, toRentalGet(rental))
+}
+
+// getUserRentals returns the rentals of the authenticated user, the latest first.
+//
+//	@Summary	Gets the rentals of the authenticated user.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//
+//	@Success	200			{object}	[]model.RentalGet
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v2/me/rentals [get]
+func (s *Server) getUserRentals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return
+	}
+
+	rentals, err := s.rentalService.GetUserRentals(ctx, clientUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get user's rentals", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting rentals.")
+
+		return
+	}
+
+	result := make([]model.RentalGet, len(rentals))
+
+	for i := range rentals {
+		result[i] = toRentalGet(rentals[i])
+	}
+
+	JSON(w, http.StatusOK, result)
+}
+
+// getActiveRentals returns the rides of the authenticated user in progress, the latest first, with the tracked
+// positions of their scooters and the fares so far.
+//
+//	@Summary	Gets the active rentals of the authenticated user.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//
+//	@Success	200			{object}	[]model.ActiveRentalGet
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/rentals/active [get]
+func (s *Server) getActiveRentals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return
+	}
+
+	activeRentals, err := s.rentalService.GetActiveRentals(ctx, clientUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get user's active rentals", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting active rentals.")
+
+		return
+	}
+
+	result := make([]model.ActiveRentalGet, len(activeRentals))
+
+	for i, activeRental := range activeRentals {
+		result[i] = model.ActiveRentalGet{
+			RentalUUID:     activeRental.Rental.UUID,
+			ScooterUUID:    activeRental.Rental.ScooterUUID,
+			City:           activeRental.Rental.City,
+			StartedAt:      activeRental.Rental.StartedAt,
+			ElapsedSeconds: int64(activeRental.Elapsed.Seconds()),
+			Longitude:      activeRental.Longitude,
+			Latitude:       activeRental.Latitude,
+			Fare: model.FareGet{
+				Amount:   activeRental.Fare,
+				Currency: activeRental.Currency,
+			},
+		}
+	}
+
+	JSON(w, http.StatusOK, result)
+}
+
+// rentalRequest returns the authenticated client and the rental from the path, writing the problem response when
+// either is missing.
+func rentalRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	ctxLogger := logging.FromContext(r.Context())
+
+	clientUUID, err := clientUUIDFromContext(r.Context())
+	if err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	rentalUUID, err := uuid.Parse(mux.Vars(r)[rentalIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse rentalID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing rentalID.")
+
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return clientUUID, rentalUUID, true
+}
+
+func toScooterGet(scooterUUID uuid.UUID, scooter *rentalmodel.Scooter) model.ScooterGet {
+	state := scooterStateAvailable
+	if !scooter.Availability {
+		state = scooterStateRented
+	}
+
+	scooterGet := model.ScooterGet{
+		ScooterUUID:  scooterUUID,
+		City:         scooter.City,
+		Longitude:    scooter.Longitude,
+		Latitude:     scooter.Latitude,
+		Availability: scooter.Availability,
+		State:        state,
+	}
+
+	if !scooter.UpdatedAt.IsZero() {
+		scooterGet.UpdatedAt = &scooter.UpdatedAt
+	}
+
+	return scooterGet
+}
+
+func toRentalGet(rental *rentalmodel.Rental) model.RentalGet {
+	status := rentalStatusActive
+	if !rental.Active() {
+		status = rentalStatusEnded
+	}
+
+	return model.RentalGet{
+		RentalUUID:     rental.UUID,
+		ScooterUUID:    rental.ScooterUUID,
+		City:           rental.City,
+		Status:         status,
+		StartLongitude: rental.StartLongitude,
+		StartLatitude:  rental.StartLatitude,
+		StartedAt:      rental.StartedAt,
+		EndedAt:        rental.EndedAt,
+	}
+}
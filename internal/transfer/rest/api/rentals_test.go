@@ -0,0 +1,412 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/rental"
+	mockrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+)
+
+var testStartedAt = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+func TestGetScooter(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	updatedScooter := rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, false)
+	updatedScooter.UpdatedAt = testStartedAt
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		scooterID                string
+		expectedCode             int
+		expectedBody             string
+	}{
+		"successfully got scooter": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(updatedScooter, nil).Times(1)
+			},
+			scooterID:    scooterUUID.String(),
+			expectedCode: http.StatusOK,
+			expectedBody: `{"UUID":"` + scooterUUID.String() + `","city":"Montreal","longitude":70,"latitude":60,` +
+				`"availability":false,"state":"rented","updatedAt":"2024-05-01T12:00:00Z"}`,
+		},
+		"successfully got scooter that was never updated": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).
+					Return(rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, true), nil).
+					Times(1)
+			},
+			scooterID:    scooterUUID.String(),
+			expectedCode: http.StatusOK,
+			expectedBody: `{"UUID":"` + scooterUUID.String() + `","city":"Montreal","longitude":70,"latitude":60,` +
+				`"availability":true,"state":"available"}`,
+		},
+		"failed getting scooter because of invalid scooterID": {
+			scooterID:    "scooter",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(http.StatusBadRequest, codeMalformedRequest, "Failed parsing scooterID."),
+		},
+		"failed getting scooter because it does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(nil, repository.ErrScooterNotFound).Times(1)
+			},
+			scooterID:    scooterUUID.String(),
+			expectedCode: http.StatusNotFound,
+			expectedBody: problemBody(http.StatusNotFound, codeScooterNotFound, "Scooter not found."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, _, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			request := buildRequest(
+				t,
+				scooterPath,
+				http.MethodGet,
+				bytes.NewBuffer(nil),
+				uuid.NullUUID{UUID: clientUUID, Valid: true},
+			)
+			request = mux.SetURLVars(request, map[string]string{scooterIDParam: tt.scooterID})
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.getScooter(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestCreateRental(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooter := rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, true)
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	createdRental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		scooterID                string
+		expectedCode             int
+		expectedLocation         string
+		expectedBody             string
+	}{
+		"successfully created rental": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(scooter, nil).Times(1)
+				mock.EXPECT().Rent(gomock.Any(), clientUUID, rentInfo).Return(createdRental, nil).Times(1)
+			},
+			scooterID:        scooterUUID.String(),
+			expectedCode:     http.StatusCreated,
+			expectedLocation: "/api/v2/rentals/" + rentalUUID.String(),
+			expectedBody: `{"UUID":"` + rentalUUID.String() + `","scooterUUID":"` + scooterUUID.String() +
+				`","city":"Montreal","status":"active","startLongitude":70,"startLatitude":60,` +
+				`"startedAt":"2024-05-01T12:00:00Z"}`,
+		},
+		"failed creating rental because of invalid scooterID": {
+			scooterID:    "scooter",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(http.StatusBadRequest, codeMalformedRequest, "Failed parsing scooterID."),
+		},
+		"failed creating rental because scooter does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(nil, repository.ErrScooterNotFound).Times(1)
+			},
+			scooterID:    scooterUUID.String(),
+			expectedCode: http.StatusNotFound,
+			expectedBody: problemBody(http.StatusNotFound, codeScooterNotFound, "Scooter not found."),
+		},
+		"failed creating rental because scooter is already rented": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(scooter, nil).Times(1)
+				mock.EXPECT().Rent(gomock.Any(), clientUUID, rentInfo).Return(nil, repository.ErrScooterNotAvailable).Times(1)
+			},
+			scooterID:    scooterUUID.String(),
+			expectedCode: http.StatusConflict,
+			expectedBody: problemBody(http.StatusConflict, codeScooterNotAvailable, "Scooter is not available."),
+		},
+		"failed creating rental because another client rented the scooter at the same time": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(scooter, nil).Times(1)
+				mock.EXPECT().Rent(gomock.Any(), clientUUID, rentInfo).
+					Return(nil, fmt.Errorf("renting scooter: %w", redis.TxFailedErr)).Times(1)
+			},
+			scooterID:    scooterUUID.String(),
+			expectedCode: http.StatusConflict,
+			expectedBody: problemBody(
+				http.StatusConflict, codeConflict, "Changed concurrently by another request, try again.",
+			),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, _, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			request := buildRequest(
+				t,
+				scooterRentalsPath,
+				http.MethodPost,
+				bytes.NewBuffer(nil),
+				uuid.NullUUID{UUID: clientUUID, Valid: true},
+			)
+			request = mux.SetURLVars(request, map[string]string{scooterIDParam: tt.scooterID})
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.createRental(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if location := responseRecorder.Header().Get(headerLocation); location != tt.expectedLocation {
+				t.Errorf("handler returned wrong location: got = %v want = %v",
+					location, tt.expectedLocation)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestEndRentalHandler(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	endedRental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+	endedAt := testStartedAt.Add(time.Hour)
+	endedRental.EndedAt = &endedAt
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		rentalID                 string
+		expectedCode             int
+		expectedBody             string
+	}{
+		"successfully ended rental": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().EndRental(gomock.Any(), clientUUID, rentalUUID).Return(endedRental, nil).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusOK,
+			expectedBody: `{"UUID":"` + rentalUUID.String() + `","scooterUUID":"` + scooterUUID.String() +
+				`","city":"Montreal","status":"ended","startLongitude":70,"startLatitude":60,` +
+				`"startedAt":"2024-05-01T12:00:00Z","endedAt":"2024-05-01T13:00:00Z"}`,
+		},
+		"failed ending rental because of invalid rentalID": {
+			rentalID:     "rental",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(http.StatusBadRequest, codeMalformedRequest, "Failed parsing rentalID."),
+		},
+		"failed ending rental because it does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().EndRental(gomock.Any(), clientUUID, rentalUUID).Return(nil, service.ErrRentalNotFound).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusNotFound,
+			expectedBody: problemBody(http.StatusNotFound, codeRentalNotFound, "Rental not found."),
+		},
+		"failed ending rental because it has already ended": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().EndRental(gomock.Any(), clientUUID, rentalUUID).Return(nil, rental.ErrRentalEnded).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusConflict,
+			expectedBody: problemBody(http.StatusConflict, codeRentalEnded, "Rental has already ended."),
+		},
+		"failed ending rental because rental service threw error": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().EndRental(gomock.Any(), clientUUID, rentalUUID).Return(nil, errors.New("")).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: problemBody(http.StatusInternalServerError, codeInternal, "Failed ending rental."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, _, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			request := buildRequest(
+				t,
+				rentalEndPath,
+				http.MethodPost,
+				bytes.NewBuffer(nil),
+				uuid.NullUUID{UUID: clientUUID, Valid: true},
+			)
+			request = mux.SetURLVars(request, map[string]string{rentalIDParam: tt.rentalID})
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.endRental(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestGetActiveRentals(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	activeRental := &rentalmodel.ActiveRental{
+		Rental:    rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt),
+		Longitude: testLongitude + 1,
+		Latitude:  testLatitude + 1,
+		Elapsed:   90 * time.Second,
+		Fare:      170,
+		Currency:  "CAD",
+	}
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		clientUUID               uuid.NullUUID
+		expectedCode             int
+		expectedBody             string
+	}{
+		"successfully got active rentals": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetActiveRentals(gomock.Any(), clientUUID).
+					Return([]*rentalmodel.ActiveRental{activeRental}, nil).Times(1)
+			},
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"UUID":"` + rentalUUID.String() + `","scooterUUID":"` + scooterUUID.String() +
+				`","city":"Montreal","startedAt":"2024-05-01T12:00:00Z","elapsedSeconds":90,"longitude":71,` +
+				`"latitude":61,"fare":{"amount":170,"currency":"CAD"}}]`,
+		},
+		"successfully got no active rentals": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetActiveRentals(gomock.Any(), clientUUID).Return(nil, nil).Times(1)
+			},
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusOK,
+			expectedBody: `[]`,
+		},
+		"failed getting active rentals because request has no authenticated client": {
+			clientUUID:   uuid.NullUUID{Valid: false},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: problemBody(http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client."),
+		},
+		"failed getting active rentals because rental service threw error": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetActiveRentals(gomock.Any(), clientUUID).Return(nil, errors.New("")).Times(1)
+			},
+			clientUUID:   uuid.NullUUID{UUID: clientUUID, Valid: true},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: problemBody(http.StatusInternalServerError, codeInternal, "Failed getting active rentals."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, _, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			request := buildRequest(t, activeRentalsPath, http.MethodGet, bytes.NewBuffer(nil), tt.clientUUID)
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.getActiveRentals(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, rentPath, nil)
+	responseRecorder := httptest.NewRecorder()
+
+	Deprecate(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, api+version2)(responseRecorder, request)
+
+	require.Equal(t, http.StatusNoContent, responseRecorder.Code)
+	require.Equal(t, "true", responseRecorder.Header().Get(headerDeprecation))
+	require.Equal(t, `</api/v2>; rel="successor-version"`, responseRecorder.Header().Get(headerLink))
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	contentTypeGeoJSON = "application/geo+json"
+	contentTypeGPX     = "application/gpx+xml"
+
+	headerAccept             = "Accept"
+	headerVary               = "Vary"
+	headerContentDisposition = "Content-Disposition"
+
+	gpxNamespace = "http://www.topografix.com/GPX/1/1"
+	gpxVersion   = "1.1"
+	gpxCreator   = "Scootin Aboot"
+)
+
+// routeContentTypes lists the formats of the route by the media types accepted for them, the wildcards choosing
+// GeoJSON.
+var routeContentTypes = map[string]string{
+	contentTypeGeoJSON: contentTypeGeoJSON,
+	contentTypeJSON:    contentTypeGeoJSON,
+	contentTypeGPX:     contentTypeGPX,
+	"application/*":    contentTypeGeoJSON,
+	"*/*":              contentTypeGeoJSON,
+}
+
+// getRentalRoute returns the route the scooter went through during the rental of the authenticated user, as a
+// GeoJSON feature or a GPX file, depending on the Accept header.
+//
+//	@Summary	Returns the route of the rental.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		rentalID	path		string	true	"ID of the rental"							minlength(36)	maxlength(36)
+//
+//	@Produce	application/geo+json,application/gpx+xml
+//	@Success	200			{object}	model.RouteGet	"GeoJSON feature, or GPX document for application/gpx+xml"
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	406			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/rentals/{rentalID}/route [get]
+func (s *Server) getRentalRoute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, rentalUUID, ok := rentalRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rentalUUID.String()))
+
+	w.Header().Add(headerVary, headerAccept)
+
+	contentType, ok := negotiateRouteContentType(r.Header.Get(headerAccept))
+	if !ok {
+		Error(w, http.StatusNotAcceptable, codeNotAcceptable, "Route is served as GeoJSON or GPX only.")
+
+		return
+	}
+
+	rental, err := s.rentalService.GetRental(ctx, clientUUID, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get rental", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting rental.")
+
+		return
+	}
+
+	route, err := s.trackerService.GetRoute(ctx, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get route", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting route.")
+
+		return
+	}
+
+	if contentType == contentTypeGPX {
+		w.Header().Set(
+			headerContentDisposition,
+			fmt.Sprintf(`attachment; filename="rental-%s.gpx"`, rentalUUID),
+		)
+
+		writeRoute(w, contentTypeGPX, toRouteGPX(rental, route), xmlBody)
+
+		return
+	}
+
+	writeRoute(w, contentTypeGeoJSON, toRouteGet(rental, route), json.Marshal)
+}
+
+// negotiateRouteContentType picks the format of the route most preferred by the Accept header, GeoJSON when the
+// header is missing. The media types of the same quality are preferred in the order they are listed.
+func negotiateRouteContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return contentTypeGeoJSON, true
+	}
+
+	var (
+		chosen        string
+		chosenQuality float64
+	)
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+
+		contentType, ok := routeContentTypes[mediaType]
+		if !ok {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > chosenQuality {
+			chosen, chosenQuality = contentType, quality
+		}
+	}
+
+	return chosen, chosen != ""
+}
+
+// writeRoute writes the route encoded with the marshal function, answering with 500 when it fails.
+func writeRoute(w http.ResponseWriter, contentType string, route any, marshal func(any) ([]byte, error)) {
+	body, err := marshal(route)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, codeInternal, "Failed encoding route.")
+
+		return
+	}
+
+	w.Header().Set(headerContentType, contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// xmlBody marshals the document preceded by the XML declaration.
+func xmlBody(document any) ([]byte, error) {
+	body, err := xml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling xml: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+func toRouteGet(rental *rentalmodel.Rental, route []*trackermodel.RoutePoint) model.RouteGet {
+	routeGet := model.RouteGet{
+		Type: "Feature",
+		Properties: model.RoutePropertyGet{
+			RentalUUID:  rental.UUID,
+			ScooterUUID: rental.ScooterUUID,
+			Timestamps:  make([]time.Time, 0, len(route)),
+		},
+	}
+
+	// a line needs two positions at least
+	if len(route) < 2 {
+		return routeGet
+	}
+
+	routeGet.Geometry = &model.LineStringGet{
+		Type:        "LineString",
+		Coordinates: make([][2]float64, len(route)),
+	}
+
+	for i, point := range route {
+		routeGet.Geometry.Coordinates[i] = [2]float64{point.Longitude, point.Latitude}
+		routeGet.Properties.Timestamps = append(routeGet.Properties.Timestamps, point.At)
+	}
+
+	return routeGet
+}
+
+func toRouteGPX(rental *rentalmodel.Rental, route []*trackermodel.RoutePoint) model.RouteGPX {
+	points := make([]model.RouteGPXPoint, len(route))
+
+	for i, point := range route {
+		points[i] = model.RouteGPXPoint{
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+			Time:      point.At,
+		}
+	}
+
+	return model.RouteGPX{
+		XMLNS:   gpxNamespace,
+		Version: gpxVersion,
+		Creator: gpxCreator,
+		Track: model.RouteGPXTrack{
+			Name:    "Rental " + rental.UUID.String(),
+			Segment: model.RouteGPXTrackSegment{Points: points},
+		},
+	}
+}
@@ -0,0 +1,167 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	mockrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	mocktracker "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/mock"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestGetRentalRoute(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	rental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+
+	route := []*trackermodel.RoutePoint{
+		{Longitude: 70, Latitude: 60, At: testStartedAt},
+		{Longitude: 70, Latitude: 60.5, At: testStartedAt.Add(3 * time.Second)},
+	}
+
+	geoJSONBody := `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[70,60],[70,60.5]]},` +
+		`"properties":{"rentalUUID":"` + rentalUUID.String() + `","scooterUUID":"` + scooterUUID.String() + `",` +
+		`"timestamps":["2024-05-01T12:00:00Z","2024-05-01T12:00:03Z"]}}`
+
+	tests := map[string]struct {
+		mockRentalServiceHandler  func(mock *mockrental.MockRentalService)
+		mockTrackerServiceHandler func(mock *mocktracker.MockService)
+		rentalID                  string
+		accept                    string
+		expectedCode              int
+		expectedContentType       string
+		expectedBody              string
+	}{
+		"successfully got route as geojson by default": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(rental, nil).Times(1)
+			},
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().GetRoute(gomock.Any(), rentalUUID).Return(route, nil).Times(1)
+			},
+			rentalID:            rentalUUID.String(),
+			accept:              "",
+			expectedCode:        http.StatusOK,
+			expectedContentType: contentTypeGeoJSON,
+			expectedBody:        geoJSONBody,
+		},
+		"successfully got route as gpx": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(rental, nil).Times(1)
+			},
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().GetRoute(gomock.Any(), rentalUUID).Return(route, nil).Times(1)
+			},
+			rentalID:            rentalUUID.String(),
+			accept:              "application/json;q=0.5, application/gpx+xml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: contentTypeGPX,
+			expectedBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="Scootin Aboot">` +
+				`<trk><name>Rental ` + rentalUUID.String() + `</name><trkseg>` +
+				`<trkpt lat="60" lon="70"><time>2024-05-01T12:00:00Z</time></trkpt>` +
+				`<trkpt lat="60.5" lon="70"><time>2024-05-01T12:00:03Z</time></trkpt>` +
+				`</trkseg></trk></gpx>`,
+		},
+		"successfully got route of the ride not moved yet": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(rental, nil).Times(1)
+			},
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().GetRoute(gomock.Any(), rentalUUID).Return(route[:1], nil).Times(1)
+			},
+			rentalID:            rentalUUID.String(),
+			accept:              "application/geo+json",
+			expectedCode:        http.StatusOK,
+			expectedContentType: contentTypeGeoJSON,
+			expectedBody: `{"type":"Feature","geometry":null,"properties":{"rentalUUID":"` + rentalUUID.String() +
+				`","scooterUUID":"` + scooterUUID.String() + `","timestamps":[]}}`,
+		},
+		"failed getting route because the format is not acceptable": {
+			rentalID:            rentalUUID.String(),
+			accept:              "text/html, application/gpx+xml;q=0",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: contentTypeProblemJSON,
+			expectedBody: problemBody(
+				http.StatusNotAcceptable, codeNotAcceptable, "Route is served as GeoJSON or GPX only.",
+			),
+		},
+		"failed getting route because of invalid rentalID": {
+			rentalID:            "rental",
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: contentTypeProblemJSON,
+			expectedBody:        problemBody(http.StatusBadRequest, codeMalformedRequest, "Failed parsing rentalID."),
+		},
+		"failed getting route because the rental does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(nil, service.ErrRentalNotFound).Times(1)
+			},
+			rentalID:            rentalUUID.String(),
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: contentTypeProblemJSON,
+			expectedBody:        problemBody(http.StatusNotFound, codeRentalNotFound, "Rental not found."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, mockTrackerService, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			if tt.mockTrackerServiceHandler != nil {
+				tt.mockTrackerServiceHandler(mockTrackerService)
+			}
+
+			request := buildRequest(
+				t,
+				rentalRoutePath,
+				http.MethodGet,
+				bytes.NewBuffer(nil),
+				uuid.NullUUID{UUID: clientUUID, Valid: true},
+			)
+			request = mux.SetURLVars(request, map[string]string{rentalIDParam: tt.rentalID})
+
+			if tt.accept != "" {
+				request.Header.Set(headerAccept, tt.accept)
+			}
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.getRentalRoute(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			require.Equal(t, tt.expectedContentType, responseRecorder.Header().Get(headerContentType))
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
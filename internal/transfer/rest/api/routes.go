@@ -6,28 +6,129 @@ import (
 	swagger "github.com/swaggo/http-swagger/v2"
 
 	_ "github.com/PatrykPasterny/scooter-rental/docs"
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/ratelimit"
 )
 
 const (
 	api          = "/api"
 	version      = "/v1"
+	version2     = "/v2"
 	scootersPath = "/scooters"
 	rentPath     = "/rent"
 	freePath     = "/free"
+	logLevelPath = "/admin/log-level"
+	usersPath    = "/users"
+	profilePath  = "/users/me"
+	statusPath   = "/users/{" + userIDParam + "}/status"
 	swaggerDocs  = "/api-docs"
+	healthzPath  = "/healthz"
+	readyzPath   = "/readyz"
+
+	liveScootersPath   = "/scooters/live"
+	scooterPath        = "/scooters/{" + scooterIDParam + "}"
+	scooterRentalsPath = "/scooters/{" + scooterIDParam + "}/rentals"
+	telemetryPath      = "/scooters/{" + scooterIDParam + "}/telemetry"
+	rentalPath         = "/rentals/{" + rentalIDParam + "}"
+	rentalEndPath      = "/rentals/{" + rentalIDParam + "}/end"
+	userRentalsPath    = "/me/rentals"
+	activeRentalsPath  = "/rentals/active"
+	rentalStreamPath   = "/rentals/{" + rentalIDParam + "}/stream"
+	rentalRoutePath    = "/rentals/{" + rentalIDParam + "}/route"
+
+	webhooksPath         = "/admin/webhooks"
+	webhookPath          = "/admin/webhooks/{" + webhookIDParam + "}"
+	deadLettersPath      = "/admin/webhooks/dead-letters"
+	deadLetterReplayPath = "/admin/webhooks/dead-letters/{" + deliveryIDParam + "}/replay"
 )
 
 // registerRoutes sets service routes.
 func (s *Server) registerRoutes() {
+	s.router.Use(LogRequests(s.logger))
+
+	s.router.Path(healthzPath).Methods(http.MethodGet).HandlerFunc(s.liveness)
+	s.router.Path(readyzPath).Methods(http.MethodGet).HandlerFunc(s.readiness)
+
 	versionRoute := s.router.PathPrefix(api + version).Subrouter()
 
 	versionRoute.PathPrefix(swaggerDocs).Handler(swagger.WrapHandler)
 
 	versionRoute.Path(scootersPath).Methods(http.MethodGet).
-		HandlerFunc(AuthenticateUser(s.getScooters, s.logger, s.eligibleUsers))
+		HandlerFunc(s.authorized(s.limited(s.getScooters, ratelimit.BucketSearch), auth.PermissionScootersRead))
+	// registered before the scooter, so live is not taken for its ID
+	versionRoute.Path(liveScootersPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.watchScooters, ratelimit.BucketSearch), auth.PermissionScootersRead))
+	versionRoute.Path(scooterPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getScooter, ratelimit.BucketSearch), auth.PermissionScootersRead))
+	versionRoute.Path(telemetryPath).Methods(http.MethodPost).
+		HandlerFunc(s.deviceAuthorized(s.ingestTelemetry, auth.PermissionTelemetryWrite))
+	versionRoute.Path(activeRentalsPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getActiveRentals, ratelimit.BucketSearch), auth.PermissionRentalsRead))
+	versionRoute.Path(rentalStreamPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.streamRental, ratelimit.BucketSearch), auth.PermissionRentalsRead))
+	versionRoute.Path(rentalRoutePath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getRentalRoute, ratelimit.BucketSearch), auth.PermissionRentalsRead))
+
+	versionRoute.Path(rentPath).Methods(http.MethodPost).HandlerFunc(Deprecate(
+		s.authorized(s.limited(s.rentScooter, ratelimit.BucketMutation), auth.PermissionScootersRent),
+		api+version2,
+	))
+	versionRoute.Path(freePath).Methods(http.MethodPost).HandlerFunc(Deprecate(
+		s.authorized(s.limited(s.freeScooter, ratelimit.BucketMutation), auth.PermissionScootersFree),
+		api+version2,
+	))
+
+	versionRoute.Path(usersPath).Methods(http.MethodPost).HandlerFunc(s.limited(s.signUp, ratelimit.BucketMutation))
+	versionRoute.Path(profilePath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getProfile, ratelimit.BucketSearch), auth.PermissionProfileRead))
+	versionRoute.Path(profilePath).Methods(http.MethodPut).
+		HandlerFunc(s.authorized(s.limited(s.updateProfile, ratelimit.BucketMutation), auth.PermissionProfileWrite))
+	versionRoute.Path(statusPath).Methods(http.MethodPut).
+		HandlerFunc(s.authorized(s.limited(s.setUserStatus, ratelimit.BucketMutation), auth.PermissionUsersManage))
+
+	versionRoute.Path(logLevelPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.getLogLevel, auth.PermissionLogLevelRead))
+	versionRoute.Path(logLevelPath).Methods(http.MethodPut).
+		HandlerFunc(s.authorized(s.setLogLevel, auth.PermissionLogLevelWrite))
+
+	versionRoute.Path(webhooksPath).Methods(http.MethodPost).
+		HandlerFunc(s.authorized(s.createWebhook, auth.PermissionWebhooksManage))
+	versionRoute.Path(webhooksPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.getWebhooks, auth.PermissionWebhooksManage))
+	// registered before the webhook, so dead-letters is not taken for its ID
+	versionRoute.Path(deadLettersPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.getDeadLetters, auth.PermissionWebhooksManage))
+	versionRoute.Path(deadLetterReplayPath).Methods(http.MethodPost).
+		HandlerFunc(s.authorized(s.replayDeadLetter, auth.PermissionWebhooksManage))
+	versionRoute.Path(webhookPath).Methods(http.MethodDelete).
+		HandlerFunc(s.authorized(s.deleteWebhook, auth.PermissionWebhooksManage))
+
+	version2Route := s.router.PathPrefix(api + version2).Subrouter()
+
+	version2Route.Path(scooterPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getScooter, ratelimit.BucketSearch), auth.PermissionScootersRead))
+	version2Route.Path(scooterRentalsPath).Methods(http.MethodPost).
+		HandlerFunc(s.authorized(s.limited(s.createRental, ratelimit.BucketMutation), auth.PermissionScootersRent))
+	version2Route.Path(rentalPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getRental, ratelimit.BucketSearch), auth.PermissionRentalsRead))
+	version2Route.Path(rentalEndPath).Methods(http.MethodPost).
+		HandlerFunc(s.authorized(s.limited(s.endRental, ratelimit.BucketMutation), auth.PermissionScootersFree))
+	version2Route.Path(userRentalsPath).Methods(http.MethodGet).
+		HandlerFunc(s.authorized(s.limited(s.getUserRentals, ratelimit.BucketSearch), auth.PermissionRentalsRead))
+}
+
+// limited wraps the handler with the rate limit of the bucket.
+func (s *Server) limited(h http.HandlerFunc, bucket ratelimit.Bucket) http.HandlerFunc {
+	return LimitRate(h, s.rateLimiter, bucket)
+}
+
+// authorized wraps the handler with authentication followed by the check of the permission the route requires.
+func (s *Server) authorized(h http.HandlerFunc, permission auth.Permission) http.HandlerFunc {
+	return AuthenticateUser(Authorize(h, permission), s.authenticator, s.userService)
+}
 
-	versionRoute.Path(rentPath).Methods(http.MethodPost).
-		HandlerFunc(AuthenticateUser(s.rentScooter, s.logger, s.eligibleUsers))
-	versionRoute.Path(freePath).Methods(http.MethodPost).
-		HandlerFunc(AuthenticateUser(s.freeScooter, s.logger, s.eligibleUsers))
+// deviceAuthorized wraps the handler with the authentication of the scooter followed by the check of the permission
+// the route requires.
+func (s *Server) deviceAuthorized(h http.HandlerFunc, permission auth.Permission) http.HandlerFunc {
+	return AuthenticateDevice(Authorize(h, permission), s.authenticator)
 }
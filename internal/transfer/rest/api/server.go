@@ -9,14 +9,29 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/ratelimit"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/health"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/livemap"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/rental"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook"
 )
 
+// Companion is a server run next to the HTTP one, like the gRPC server or the event worker, started by Run and shut
+// down with it.
+type Companion interface {
+	// Serve blocks until the companion is shut down.
+	Serve() error
+	Shutdown()
+}
+
 type Server struct {
 	logger         *slog.Logger
 	validator      *validator.Validate
@@ -24,7 +39,17 @@ type Server struct {
 	router         *mux.Router
 	rentalService  rental.RentalService
 	trackerService tracker.Service
-	eligibleUsers  map[string]bool
+	liveMap        *livemap.Feed
+	authenticator  *auth.Authenticator
+	userService    user.Service
+	webhookService webhook.Service
+	rateLimiter    *ratelimit.Limiter
+	health         *health.Service
+	drainDelay     time.Duration
+	logLevel       *slog.LevelVar
+	// closing is closed when the server shuts down, ending the streams that would otherwise keep it waiting.
+	closing    chan struct{}
+	companions []Companion
 }
 
 func NewServer(
@@ -34,7 +59,15 @@ func NewServer(
 	router *mux.Router,
 	rental rental.RentalService,
 	tracker tracker.Service,
-	users map[string]bool,
+	liveMap *livemap.Feed,
+	users user.Service,
+	webhooks webhook.Service,
+	authenticator *auth.Authenticator,
+	rateLimiter *ratelimit.Limiter,
+	health *health.Service,
+	drainDelay time.Duration,
+	logLevel *slog.LevelVar,
+	companions ...Companion,
 ) *Server {
 
 	s := &Server{
@@ -44,9 +77,22 @@ func NewServer(
 		router:         router,
 		rentalService:  rental,
 		trackerService: tracker,
-		eligibleUsers:  users,
+		liveMap:        liveMap,
+		authenticator:  authenticator,
+		userService:    users,
+		webhookService: webhooks,
+		rateLimiter:    rateLimiter,
+		health:         health,
+		drainDelay:     drainDelay,
+		logLevel:       logLevel,
+		closing:        make(chan struct{}),
+		companions:     companions,
 	}
 
+	server.RegisterOnShutdown(func() {
+		close(s.closing)
+	})
+
 	s.registerRoutes()
 
 	return s
@@ -84,8 +130,33 @@ func (s *Server) Run() {
 		s.logger.Info("Stopping HTTP server.")
 	}(&waitGroup)
 
+	for _, companion := range s.companions {
+		waitGroup.Add(1)
+
+		go func(running *sync.WaitGroup, companion Companion) {
+			defer running.Done()
+
+			if err := companion.Serve(); err != nil {
+				cancel()
+
+				s.logger.Error("can't run companion server", slog.Any("err", err))
+			}
+		}(&waitGroup, companion)
+	}
+
 	<-ctx.Done()
 
+	// fail the readiness check first, so load balancers stop sending traffic before the connections get closed
+	s.health.SetShuttingDown()
+
+	s.logger.Info("Draining traffic before shutdown.", slog.Duration("delay", s.drainDelay))
+
+	time.Sleep(s.drainDelay)
+
+	for _, companion := range s.companions {
+		companion.Shutdown()
+	}
+
 	if err := s.httpServer.Shutdown(context.Background()); err != nil {
 		s.logger.Error("can't shutdown gracefully", slog.Any("err", err))
 
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	contentTypeEventStream = "text/event-stream"
+	headerCacheControl     = "Cache-Control"
+	headerAccelBuffering   = "X-Accel-Buffering"
+
+	// streamHeartbeatInterval keeps the idle streams from being closed by the proxies on the way.
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+// streamRental streams the ride of the authenticated user as server-sent events: a position event for every move of
+// the scooter and the ended event closing the stream.
+//
+//	@Summary	Streams the rental.
+//	@Tags		rentals
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		rentalID	path		string	true	"ID of the rental"							minlength(36)	maxlength(36)
+//
+//	@Produce	text/event-stream
+//	@Success	200			{object}	model.RideEventGet	"position and ended events"
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/rentals/{rentalID}/stream [get]
+func (s *Server) streamRental(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, rentalUUID, ok := rentalRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rentalUUID.String()))
+
+	rental, err := s.rentalService.GetRental(ctx, clientUUID, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get rental", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting rental.")
+
+		return
+	}
+
+	events, unsubscribe := s.trackerService.Subscribe(rental.ScooterUUID)
+	defer unsubscribe()
+
+	// the ride could have ended before the subscription, in which case its ended event was missed
+	if rental.Active() {
+		if rental, err = s.rentalService.GetRental(ctx, clientUUID, rentalUUID); err != nil {
+			ctxLogger.Error("failed to get rental", slog.Any("err", err))
+
+			domainError(w, err, "Failed getting rental.")
+
+			return
+		}
+	}
+
+	w.Header().Set(headerContentType, contentTypeEventStream)
+	w.Header().Set(headerCacheControl, "no-cache")
+	w.Header().Set(headerAccelBuffering, "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &eventStream{writer: w, controller: http.NewResponseController(w)}
+
+	if !rental.Active() {
+		stream.send(trackermodel.EventEnded, model.RideEventGet{
+			ElapsedSeconds: int64(rental.EndedAt.Sub(rental.StartedAt).Seconds()),
+			At:             *rental.EndedAt,
+		})
+
+		return
+	}
+
+	ctxLogger.Info("Streaming rental.")
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctxLogger.Info("Client stopped streaming rental.")
+
+			return
+		case <-s.closing:
+			return
+		case <-heartbeat.C:
+			if err = stream.comment("heartbeat"); err != nil {
+				ctxLogger.Warn("Failed to stream heartbeat.", slog.Any("err", err))
+
+				return
+			}
+		case event, open := <-events:
+			if !open {
+				return
+			}
+
+			if err = stream.send(event.Type, toRideEventGet(rental, event)); err != nil {
+				ctxLogger.Warn("Failed to stream rental event.", slog.Any("err", err))
+
+				return
+			}
+
+			if event.Type == trackermodel.EventEnded {
+				ctxLogger.Info("Streamed end of rental.")
+
+				return
+			}
+		}
+	}
+}
+
+// eventStream writes the server-sent events, numbering them and flushing each one right away.
+type eventStream struct {
+	writer     http.ResponseWriter
+	controller *http.ResponseController
+	lastID     int
+}
+
+func (es *eventStream) send(eventType trackermodel.EventType, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	es.lastID++
+
+	if _, err = fmt.Fprintf(es.writer, "id: %d\nevent: %s\ndata: %s\n\n", es.lastID, eventType, body); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+
+	return es.flush()
+}
+
+func (es *eventStream) comment(text string) error {
+	if _, err := fmt.Fprintf(es.writer, ": %s\n\n", text); err != nil {
+		return fmt.Errorf("writing comment: %w", err)
+	}
+
+	return es.flush()
+}
+
+func (es *eventStream) flush() error {
+	if err := es.controller.Flush(); err != nil {
+		return fmt.Errorf("flushing stream: %w", err)
+	}
+
+	return nil
+}
+
+func toRideEventGet(rental *rentalmodel.Rental, event trackermodel.Event) model.RideEventGet {
+	return model.RideEventGet{
+		Longitude:      &event.Longitude,
+		Latitude:       &event.Latitude,
+		DistanceMeters: &event.Distance,
+		ElapsedSeconds: int64(event.At.Sub(rental.StartedAt).Seconds()),
+		At:             event.At,
+	}
+}
@@ -0,0 +1,147 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	mockrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	mocktracker "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/mock"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestStreamRental(t *testing.T) {
+	clientUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentalUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	activeRental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+
+	endedRental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+	endedAt := testStartedAt.Add(time.Hour)
+	endedRental.EndedAt = &endedAt
+
+	rideEvents := func() <-chan trackermodel.Event {
+		events := make(chan trackermodel.Event, 2)
+
+		events <- trackermodel.Event{
+			Type:        trackermodel.EventPosition,
+			ScooterUUID: scooterUUID,
+			Longitude:   70,
+			Latitude:    60.5,
+			Distance:    92.5,
+			At:          testStartedAt.Add(3 * time.Second),
+		}
+		events <- trackermodel.Event{
+			Type:        trackermodel.EventEnded,
+			ScooterUUID: scooterUUID,
+			Longitude:   70,
+			Latitude:    60.5,
+			Distance:    92.5,
+			At:          testStartedAt.Add(5 * time.Second),
+		}
+		close(events)
+
+		return events
+	}
+
+	tests := map[string]struct {
+		mockRentalServiceHandler  func(mock *mockrental.MockRentalService)
+		mockTrackerServiceHandler func(mock *mocktracker.MockService)
+		rentalID                  string
+		expectedCode              int
+		expectedBody              string
+	}{
+		"successfully streamed rental until it ended": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(activeRental, nil).Times(2)
+			},
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().Subscribe(scooterUUID).Return(rideEvents(), func() {}).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusOK,
+			expectedBody: "id: 1\nevent: position\ndata: {\"longitude\":70,\"latitude\":60.5,\"distanceMeters\":92.5," +
+				"\"elapsedSeconds\":3,\"at\":\"2024-05-01T12:00:03Z\"}\n\n" +
+				"id: 2\nevent: ended\ndata: {\"longitude\":70,\"latitude\":60.5,\"distanceMeters\":92.5," +
+				"\"elapsedSeconds\":5,\"at\":\"2024-05-01T12:00:05Z\"}\n\n",
+		},
+		"successfully streamed end of rental that has already ended": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(endedRental, nil).Times(1)
+			},
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().Subscribe(scooterUUID).Return(make(chan trackermodel.Event), func() {}).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusOK,
+			expectedBody: "id: 1\nevent: ended\ndata: {\"elapsedSeconds\":3600,\"at\":\"2024-05-01T13:00:00Z\"}\n\n",
+		},
+		"failed streaming rental because of invalid rentalID": {
+			rentalID:     "rental",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: problemBody(http.StatusBadRequest, codeMalformedRequest, "Failed parsing rentalID."),
+		},
+		"failed streaming rental because it does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(nil, service.ErrRentalNotFound).Times(1)
+			},
+			rentalID:     rentalUUID.String(),
+			expectedCode: http.StatusNotFound,
+			expectedBody: problemBody(http.StatusNotFound, codeRentalNotFound, "Rental not found."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, mockRentalService, mockTrackerService, _ := beforeTest(t)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			if tt.mockTrackerServiceHandler != nil {
+				tt.mockTrackerServiceHandler(mockTrackerService)
+			}
+
+			request := buildRequest(
+				t,
+				rentalStreamPath,
+				http.MethodGet,
+				bytes.NewBuffer(nil),
+				uuid.NullUUID{UUID: clientUUID, Valid: true},
+			)
+			request = mux.SetURLVars(request, map[string]string{rentalIDParam: tt.rentalID})
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.streamRental(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/telemetry"
+)
+
+// ingestTelemetry takes the batch of the readings the scooter reports when the tracking is driven by the devices. The
+// readings already received are dropped, so the batch whose response got lost can be sent again.
+//
+//	@Summary	Reports the telemetry of the scooter.
+//	@Tags		scooters
+//
+//	@Security	BearerAuth
+//	@Param		scooterID	path		string				true	"ID of the scooter"				minlength(36)	maxlength(36)
+//	@Param		Payload		body		telemetry.Batch		true	"Readings of the scooter, the oldest first"
+//
+//	@Success	200			{object}	model.TelemetryResult
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	404			{object}	model.Problem
+//	@Failure	409			{object}	model.Problem
+//	@Failure	413			{object}	model.Problem
+//	@Failure	422			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/scooters/{scooterID}/telemetry [post]
+func (s *Server) ingestTelemetry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	scooterUUID, err := uuid.Parse(mux.Vars(r)[scooterIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse scooterID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing scooterID.")
+
+		return
+	}
+
+	if !authorizeScooter(w, r, scooterUUID) {
+		return
+	}
+
+	var batch telemetry.Batch
+
+	if !s.decodeBody(w, r, &batch, "Failed to decode request body to telemetry.") {
+		return
+	}
+
+	accepted, err := s.trackerService.IngestTelemetry(ctx, scooterUUID, batch.ToTracker())
+	if err != nil {
+		ctxLogger.Error("failed to ingest telemetry", slog.Any("err", err))
+
+		domainError(w, err, "Failed ingesting telemetry.")
+
+		return
+	}
+
+	JSON(w, http.StatusOK, model.TelemetryResult{
+		Accepted:   accepted,
+		Duplicates: len(batch.Readings) - accepted,
+	})
+}
@@ -0,0 +1,151 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	mocktracker "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/mock"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+func TestIngestTelemetry(t *testing.T) {
+	scooterUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	readingTime := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	readings := []*trackermodel.Telemetry{
+		{Longitude: 70.01, Latitude: 60.01, At: readingTime, Speed: 18.5, Battery: 80, Locked: false},
+		{Longitude: 70.02, Latitude: 60.01, At: readingTime.Add(time.Second), Speed: 0, Battery: 79, Locked: true},
+	}
+	readingsBody := `{"readings":[` +
+		`{"longitude":70.01,"latitude":60.01,"timestamp":"2024-05-01T14:00:00+02:00","speed":18.5,"battery":80,` +
+		`"locked":false},` +
+		`{"longitude":70.02,"latitude":60.01,"timestamp":"2024-05-01T12:00:01Z","speed":0,"battery":79,"locked":true}` +
+		`]}`
+
+	tests := map[string]struct {
+		mockTrackerServiceHandler func(mock *mocktracker.MockService)
+		path                      string
+		bearerToken               string
+		clientID                  string
+		body                      string
+		expectedCode              int
+		expectedBody              string
+	}{
+		"successfully ingested telemetry": {
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().IngestTelemetry(gomock.Any(), scooterUUID, readings).Return(1, nil).Times(1)
+			},
+			path:         "/scooters/" + scooterUUID.String() + "/telemetry",
+			bearerToken:  testAdminToken,
+			body:         readingsBody,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"accepted":1,"duplicates":1}`,
+		},
+		"failed ingesting telemetry because the client id header is not accepted from devices": {
+			mockTrackerServiceHandler: nil,
+			path:                      "/scooters/" + scooterUUID.String() + "/telemetry",
+			clientID:                  scooterUUID.String(),
+			body:                      readingsBody,
+			expectedCode:              http.StatusUnauthorized,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.", testRequestID,
+			),
+		},
+		"failed ingesting telemetry because of invalid scooterID": {
+			mockTrackerServiceHandler: nil,
+			path:                      "/scooters/scooter/telemetry",
+			bearerToken:               testAdminToken,
+			body:                      readingsBody,
+			expectedCode:              http.StatusBadRequest,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusBadRequest, codeMalformedRequest, "Failed parsing scooterID.", testRequestID,
+			),
+		},
+		"failed ingesting telemetry because of invalid body": {
+			mockTrackerServiceHandler: nil,
+			path:                      "/scooters/" + scooterUUID.String() + "/telemetry",
+			bearerToken:               testAdminToken,
+			body: `{"readings":[{"longitude":70.01,"latitude":60.01,"timestamp":"2024-05-01T12:00:00Z",` +
+				`"speed":18.5,"battery":101}]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating request body.", testRequestID,
+				model.FieldError{Field: "readings[0].battery", Code: "max", Detail: "must be at most 100"},
+				model.FieldError{Field: "readings[0].locked", Code: "required", Detail: "is required"},
+			),
+		},
+		"failed ingesting telemetry because the readings are out of order": {
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().IngestTelemetry(gomock.Any(), scooterUUID, readings).
+					Return(0, tracker.ErrTelemetryOutOfOrder).Times(1)
+			},
+			path:         "/scooters/" + scooterUUID.String() + "/telemetry",
+			bearerToken:  testAdminToken,
+			body:         readingsBody,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnprocessableEntity, codeTelemetryOutOfOrder, "Unordered telemetry.", testRequestID,
+			),
+		},
+		"failed ingesting telemetry because the rides are simulated": {
+			mockTrackerServiceHandler: func(mock *mocktracker.MockService) {
+				mock.EXPECT().IngestTelemetry(gomock.Any(), scooterUUID, readings).
+					Return(0, tracker.ErrDeviceTrackingDisabled).Times(1)
+			},
+			path:         "/scooters/" + scooterUUID.String() + "/telemetry",
+			bearerToken:  testAdminToken,
+			body:         readingsBody,
+			expectedCode: http.StatusConflict,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusConflict, codeTelemetryDisabled, "Scooter rides are simulated.", testRequestID,
+			),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, mockTrackerService, _ := beforeTest(t)
+
+			if tt.mockTrackerServiceHandler != nil {
+				tt.mockTrackerServiceHandler(mockTrackerService)
+			}
+
+			request := httptest.NewRequest(http.MethodPost, api+version+tt.path, bytes.NewBufferString(tt.body))
+			request.Header.Set(headerRequestID, testRequestID)
+
+			if tt.bearerToken != "" {
+				request.Header.Set(headerAuthorization, bearerPrefix+tt.bearerToken)
+			}
+
+			if tt.clientID != "" {
+				request.Header.Set(headerClientID, tt.clientID)
+			}
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.router.ServeHTTP(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const userIDParam = "userID"
+
+// signUp registers a new user. A user holding a bearer token is registered under the token's subject, otherwise
+// a new ID is generated, to be sent later in the Client-Id header, if the header is accepted.
+//
+//	@Summary	Signs up a new user.
+//	@Tags		users
+//
+//	@Param		Authorization	header		string				false	"Bearer token of the user to register"
+//	@Param		Payload			body		model.UserProfile	true	"Profile of the user"
+//
+//	@Success	201				{object}	model.UserGet
+//	@Failure	400				{object}	model.Problem
+//	@Failure	401				{object}	model.Problem
+//	@Failure	409				{object}	model.Problem
+//	@Failure	413				{object}	model.Problem
+//	@Failure	422				{object}	model.Problem
+//	@Failure	429				{object}	model.Problem
+//	@Failure	500				{object}	model.Problem
+//	@Failure	503				{object}	model.Problem
+//	@Router		/v1/users [post]
+func (s *Server) signUp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	userUUID := uuid.New()
+
+	if bearerToken, found := strings.CutPrefix(r.Header.Get(headerAuthorization), bearerPrefix); found {
+		identity, err := s.authenticator.Authenticate(ctx, bearerToken, "")
+		if err != nil || identity.Method != auth.MethodJWT {
+			ctxLogger.Error("failed to authenticate user signing up", slog.Any("err", err))
+
+			w.Header().Set(headerWWWAuthenticate, bearerChallenge(err))
+
+			Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+			return
+		}
+
+		userUUID = identity.Subject
+	} else if !s.authenticator.AllowsClientID() {
+		ctxLogger.Error("failed to sign up user without bearer token")
+
+		w.Header().Set(headerWWWAuthenticate, bearerChallenge(auth.ErrMissingCredentials))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return
+	}
+
+	profile, ok := s.decodeProfile(w, r)
+	if !ok {
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("user_id", userUUID.String()))
+
+	registeredUser, err := s.userService.SignUp(ctx, userUUID, profile)
+	if err != nil {
+		ctxLogger.Error("failed to sign up user", slog.Any("err", err))
+
+		domainError(w, err, "Failed signing up user.")
+
+		return
+	}
+
+	ctxLogger.Info("Signed up user.")
+
+	JSON(w, http.StatusCreated, toUserGet(registeredUser))
+}
+
+// getProfile returns the profile of the authenticated user.
+//
+//	@Summary	Gets the profile of the authenticated user.
+//	@Tags		users
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string	false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//
+//	@Success	200			{object}	model.UserGet
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/users/me [get]
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return
+	}
+
+	registeredUser, err := s.userService.GetUser(ctx, clientUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get user", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting user.")
+
+		return
+	}
+
+	JSON(w, http.StatusOK, toUserGet(registeredUser))
+}
+
+// updateProfile replaces the profile of the authenticated user.
+//
+//	@Summary	Updates the profile of the authenticated user.
+//	@Tags		users
+//
+//	@Security	BearerAuth
+//	@Param		Client-Id	header		string				false	"ClientID, accepted only for the simulator"	minlength(36)	maxlength(36)
+//	@Param		Payload		body		model.UserProfile	true	"Profile of the user"
+//
+//	@Success	200			{object}	model.UserGet
+//	@Failure	400			{object}	model.Problem
+//	@Failure	401			{object}	model.Problem
+//	@Failure	403			{object}	model.Problem
+//	@Failure	409			{object}	model.Problem
+//	@Failure	413			{object}	model.Problem
+//	@Failure	422			{object}	model.Problem
+//	@Failure	429			{object}	model.Problem
+//	@Failure	500			{object}	model.Problem
+//	@Failure	503			{object}	model.Problem
+//	@Router		/v1/users/me [put]
+func (s *Server) updateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		ctxLogger.Error("failed to get clientID from context", slog.Any("err", err))
+
+		Error(w, http.StatusUnauthorized, codeUnauthenticated, "Failed authenticating client.")
+
+		return
+	}
+
+	profile, ok := s.decodeProfile(w, r)
+	if !ok {
+		return
+	}
+
+	updatedUser, err := s.userService.UpdateProfile(ctx, clientUUID, profile)
+	if err != nil {
+		ctxLogger.Error("failed to update user profile", slog.Any("err", err))
+
+		domainError(w, err, "Failed updating user profile.")
+
+		return
+	}
+
+	ctxLogger.Info("Updated user profile.")
+
+	JSON(w, http.StatusOK, toUserGet(updatedUser))
+}
+
+// setUserStatus activates or suspends the user. Suspended users can't use the service.
+//
+//	@Summary	Changes the status of the user.
+//	@Tags		users
+//
+//	@Security	BearerAuth
+//	@Param		userID	path		string				true	"ID of the user"	minlength(36)	maxlength(36)
+//	@Param		Payload	body		model.UserStatusPut	true	"New status of the user (active or suspended)"
+//
+//	@Success	200		{object}	model.UserGet
+//	@Failure	400		{object}	model.Problem
+//	@Failure	401		{object}	model.Problem
+//	@Failure	403		{object}	model.Problem
+//	@Failure	404		{object}	model.Problem
+//	@Failure	409		{object}	model.Problem
+//	@Failure	413		{object}	model.Problem
+//	@Failure	422		{object}	model.Problem
+//	@Failure	429		{object}	model.Problem
+//	@Failure	500		{object}	model.Problem
+//	@Failure	503		{object}	model.Problem
+//	@Router		/v1/users/{userID}/status [put]
+func (s *Server) setUserStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	userUUID, err := uuid.Parse(mux.Vars(r)[userIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse userID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing userID.")
+
+		return
+	}
+
+	var statusPut model.UserStatusPut
+
+	if !s.decodeBody(w, r, &statusPut, "Failed to decode request body to user status.") {
+		return
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("user_id", userUUID.String()), slog.String("status", statusPut.Status))
+
+	updatedUser, err := s.userService.SetStatus(ctx, userUUID, usermodel.Status(statusPut.Status))
+	if err != nil {
+		ctxLogger.Error("failed to set user status", slog.Any("err", err))
+
+		domainError(w, err, "Failed setting user status.")
+
+		return
+	}
+
+	ctxLogger.Info("Changed user status.")
+
+	JSON(w, http.StatusOK, toUserGet(updatedUser))
+}
+
+func (s *Server) decodeProfile(w http.ResponseWriter, r *http.Request) (usermodel.Profile, bool) {
+	var profile model.UserProfile
+
+	if !s.decodeBody(w, r, &profile, "Failed to decode request body to user profile.") {
+		return usermodel.Profile{}, false
+	}
+
+	return usermodel.Profile{
+		Name:          profile.Name,
+		Email:         profile.Email,
+		Phone:         profile.Phone,
+		PreferredCity: profile.PreferredCity,
+	}, true
+}
+
+func toUserGet(user *usermodel.User) model.UserGet {
+	return model.UserGet{
+		UserUUID:      user.UUID,
+		Name:          user.Profile.Name,
+		Email:         user.Profile.Email,
+		Phone:         user.Profile.Phone,
+		PreferredCity: user.Profile.PreferredCity,
+		Status:        string(user.Status),
+		CreatedAt:     user.CreatedAt,
+	}
+}
@@ -0,0 +1,167 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	mockuser "github.com/PatrykPasterny/scooter-rental/internal/service/user/mock"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const testProfileJSON = `{"name":"Jane Doe","email":"jane@example.com","phone":"+15551234567"}`
+
+func TestSignUp(t *testing.T) {
+	profile := usermodel.Profile{Name: "Jane Doe", Email: "jane@example.com", Phone: "+15551234567"}
+
+	tests := map[string]struct {
+		mockUserServiceHandler func(mock *mockuser.MockService)
+		body                   string
+		expectedCode           int
+	}{
+		"successfully signed up user": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().SignUp(gomock.Any(), gomock.Any(), profile).
+					DoAndReturn(func(_, userUUID, _ any) (*usermodel.User, error) {
+						return usermodel.NewUser(userUUID.(uuid.UUID), profile, time.Now()), nil
+					}).Times(1)
+			},
+			body:         testProfileJSON,
+			expectedCode: http.StatusCreated,
+		},
+		"failed signing up user because of invalid email": {
+			mockUserServiceHandler: nil,
+			body:                   `{"name":"Jane Doe","email":"jane"}`,
+			expectedCode:           http.StatusUnprocessableEntity,
+		},
+		"failed signing up user because it is already registered": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().SignUp(gomock.Any(), gomock.Any(), profile).
+					Return(nil, service.ErrUserAlreadyExists).Times(1)
+			},
+			body:         testProfileJSON,
+			expectedCode: http.StatusConflict,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, _, mockUserService := beforeTest(t)
+
+			if tt.mockUserServiceHandler != nil {
+				tt.mockUserServiceHandler(mockUserService)
+			}
+
+			request := buildRequest(t, usersPath, http.MethodPost, bytes.NewBufferString(tt.body), uuid.NullUUID{})
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.signUp(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+		})
+	}
+}
+
+func TestSetUserStatus(t *testing.T) {
+	userUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		mockUserServiceHandler func(mock *mockuser.MockService)
+		userID                 string
+		body                   string
+		expectedCode           int
+		expectedBody           string
+	}{
+		"successfully suspended user": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				user := usermodel.NewUser(userUUID, usermodel.Profile{}, time.Time{})
+				user.Status = usermodel.StatusSuspended
+
+				mock.EXPECT().SetStatus(gomock.Any(), userUUID, usermodel.StatusSuspended).Return(user, nil).Times(1)
+			},
+			userID:       userUUID.String(),
+			body:         `{"status":"suspended"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"UUID":"` + userUUID.String() + `","name":"","email":"","status":"suspended",` +
+				`"createdAt":"0001-01-01T00:00:00Z"}`,
+		},
+		"failed setting status because of unknown status": {
+			mockUserServiceHandler: nil,
+			userID:                 userUUID.String(),
+			body:                   `{"status":"deleted"}`,
+			expectedCode:           http.StatusUnprocessableEntity,
+			expectedBody: problemBody(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating request body.",
+				model.FieldError{Field: "status", Code: "oneof", Detail: "must be one of: active suspended"},
+			),
+		},
+		"failed setting status because of invalid userID": {
+			mockUserServiceHandler: nil,
+			userID:                 "user",
+			body:                   `{"status":"suspended"}`,
+			expectedCode:           http.StatusBadRequest,
+			expectedBody:           problemBody(http.StatusBadRequest, codeMalformedRequest, "Failed parsing userID."),
+		},
+		"failed setting status because user is not registered": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().SetStatus(gomock.Any(), userUUID, usermodel.StatusSuspended).
+					Return(nil, service.ErrUserNotFound).Times(1)
+			},
+			userID:       userUUID.String(),
+			body:         `{"status":"suspended"}`,
+			expectedCode: http.StatusNotFound,
+			expectedBody: problemBody(http.StatusNotFound, codeUserNotFound, "User not found."),
+		},
+		"failed setting status because user was changed concurrently": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().SetStatus(gomock.Any(), userUUID, usermodel.StatusSuspended).
+					Return(nil, service.ErrUserConflict).Times(1)
+			},
+			userID:       userUUID.String(),
+			body:         `{"status":"suspended"}`,
+			expectedCode: http.StatusConflict,
+			expectedBody: problemBody(http.StatusConflict, codeConflict, "User was changed concurrently, try again."),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, _, mockUserService := beforeTest(t)
+
+			if tt.mockUserServiceHandler != nil {
+				tt.mockUserServiceHandler(mockUserService)
+			}
+
+			request := buildRequest(t, statusPath, http.MethodPut, bytes.NewBufferString(tt.body), uuid.NullUUID{})
+			request = mux.SetURLVars(request, map[string]string{userIDParam: tt.userID})
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.setUserStatus(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
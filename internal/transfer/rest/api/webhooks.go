@@ -0,0 +1,238 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	webhookmodel "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	webhookIDParam  = "webhookID"
+	deliveryIDParam = "deliveryID"
+)
+
+// createWebhook subscribes the endpoint of a partner to the events of the rentals. The deliveries are signed with
+// the secret, which is not returned afterwards.
+//
+//	@Summary	Creates a webhook subscription.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//	@Param		Payload	body		model.WebhookPost	true	"Endpoint, events (rental.started, rental.ended) and secret"
+//
+//	@Success	201		{object}	model.WebhookGet
+//	@Failure	400		{object}	model.Problem
+//	@Failure	401		{object}	model.Problem
+//	@Failure	403		{object}	model.Problem
+//	@Failure	413		{object}	model.Problem
+//	@Failure	422		{object}	model.Problem
+//	@Failure	500		{object}	model.Problem
+//	@Failure	503		{object}	model.Problem
+//	@Router		/v1/admin/webhooks [post]
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	var webhookPost model.WebhookPost
+
+	if !s.decodeBody(w, r, &webhookPost, "Failed to decode request body to webhook.") {
+		return
+	}
+
+	eventTypes := make([]webhookmodel.EventType, len(webhookPost.Events))
+	for i, event := range webhookPost.Events {
+		eventTypes[i] = webhookmodel.EventType(event)
+	}
+
+	subscription, err := s.webhookService.Subscribe(ctx, webhookPost.URL, eventTypes, webhookPost.Secret)
+	if err != nil {
+		ctxLogger.Error("failed to create webhook", slog.Any("err", err))
+
+		domainError(w, err, "Failed creating webhook.")
+
+		return
+	}
+
+	JSON(w, http.StatusCreated, toWebhookGet(subscription))
+}
+
+// getWebhooks lists the webhook subscriptions, the oldest first.
+//
+//	@Summary	Lists the webhook subscriptions.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//
+//	@Success	200	{array}		model.WebhookGet
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Failure	500	{object}	model.Problem
+//	@Failure	503	{object}	model.Problem
+//	@Router		/v1/admin/webhooks [get]
+func (s *Server) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	subscriptions, err := s.webhookService.GetSubscriptions(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get webhooks", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting webhooks.")
+
+		return
+	}
+
+	webhooks := make([]model.WebhookGet, len(subscriptions))
+	for i, subscription := range subscriptions {
+		webhooks[i] = toWebhookGet(subscription)
+	}
+
+	JSON(w, http.StatusOK, webhooks)
+}
+
+// deleteWebhook unsubscribes the endpoint. The deliveries in flight are finished.
+//
+//	@Summary	Deletes a webhook subscription.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//	@Param		webhookID	path	string	true	"ID of the webhook"	minlength(36)	maxlength(36)
+//
+//	@Success	204
+//	@Failure	400	{object}	model.Problem
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Failure	404	{object}	model.Problem
+//	@Failure	500	{object}	model.Problem
+//	@Failure	503	{object}	model.Problem
+//	@Router		/v1/admin/webhooks/{webhookID} [delete]
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	webhookUUID, err := uuid.Parse(mux.Vars(r)[webhookIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse webhookID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing webhookID.")
+
+		return
+	}
+
+	if err = s.webhookService.Unsubscribe(ctx, webhookUUID); err != nil {
+		ctxLogger.Error("failed to delete webhook", slog.Any("err", err))
+
+		domainError(w, err, "Failed deleting webhook.")
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getDeadLetters lists the deliveries that kept failing, the latest failed first.
+//
+//	@Summary	Lists the failed webhook deliveries.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//
+//	@Success	200	{array}		model.DeadLetterGet
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Failure	500	{object}	model.Problem
+//	@Failure	503	{object}	model.Problem
+//	@Router		/v1/admin/webhooks/dead-letters [get]
+func (s *Server) getDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deliveries, err := s.webhookService.GetDeadLetters(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get dead letters", slog.Any("err", err))
+
+		domainError(w, err, "Failed getting dead letters.")
+
+		return
+	}
+
+	deadLetters := make([]model.DeadLetterGet, len(deliveries))
+	for i, delivery := range deliveries {
+		deadLetters[i] = model.DeadLetterGet{
+			DeliveryUUID: delivery.UUID,
+			WebhookUUID:  delivery.SubscriptionUUID,
+			EventUUID:    delivery.Event.UUID,
+			Event:        string(delivery.Event.Type),
+			RentalUUID:   delivery.Event.Rental.UUID,
+			OccurredAt:   delivery.Event.OccurredAt,
+			Attempts:     delivery.Attempts,
+			LastError:    delivery.LastError,
+			FailedAt:     delivery.FailedAt,
+		}
+	}
+
+	JSON(w, http.StatusOK, deadLetters)
+}
+
+// replayDeadLetter sends the failed delivery once more, with the ID of the original event, and removes it from the
+// dead letters when the receiver accepts it.
+//
+//	@Summary	Replays a failed webhook delivery.
+//	@Tags		admin
+//
+//	@Security	BearerAuth
+//	@Param		deliveryID	path	string	true	"ID of the failed delivery"	minlength(36)	maxlength(36)
+//
+//	@Success	204
+//	@Failure	400	{object}	model.Problem
+//	@Failure	401	{object}	model.Problem
+//	@Failure	403	{object}	model.Problem
+//	@Failure	404	{object}	model.Problem
+//	@Failure	500	{object}	model.Problem
+//	@Failure	502	{object}	model.Problem
+//	@Failure	503	{object}	model.Problem
+//	@Router		/v1/admin/webhooks/dead-letters/{deliveryID}/replay [post]
+func (s *Server) replayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	deliveryUUID, err := uuid.Parse(mux.Vars(r)[deliveryIDParam])
+	if err != nil {
+		ctxLogger.Error("failed to parse deliveryID", slog.Any("err", err))
+
+		Error(w, http.StatusBadRequest, codeMalformedRequest, "Failed parsing deliveryID.")
+
+		return
+	}
+
+	if err = s.webhookService.Replay(ctx, deliveryUUID); err != nil {
+		ctxLogger.Error("failed to replay dead letter", slog.Any("err", err))
+
+		domainError(w, err, "Failed replaying dead letter.")
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toWebhookGet(subscription *webhookmodel.Subscription) model.WebhookGet {
+	events := make([]string, len(subscription.EventTypes))
+	for i, eventType := range subscription.EventTypes {
+		events[i] = string(eventType)
+	}
+
+	return model.WebhookGet{
+		WebhookUUID: subscription.UUID,
+		URL:         subscription.URL,
+		Events:      events,
+		CreatedAt:   subscription.CreatedAt,
+	}
+}
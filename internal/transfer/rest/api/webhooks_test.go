@@ -0,0 +1,200 @@
+//go:build unit
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook"
+	mockwebhook "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/mock"
+	webhookmodel "github.com/PatrykPasterny/scooter-rental/internal/service/webhook/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/model"
+)
+
+const (
+	testWebhookURL    = "https://partner.example.com/hooks"
+	testWebhookSecret = "0123456789abcdef"
+)
+
+var testWebhookCreatedAt = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+func TestWebhooks(t *testing.T) {
+	webhookUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	deliveryUUID, err := uuid.NewRandom()
+	require.NoError(t, err)
+
+	subscription := webhookmodel.NewSubscription(
+		webhookUUID,
+		testWebhookURL,
+		[]webhookmodel.EventType{webhookmodel.EventRentalStarted},
+		testWebhookSecret,
+		testWebhookCreatedAt,
+	)
+	webhookBody := `{"UUID":"` + webhookUUID.String() + `","url":"` + testWebhookURL +
+		`","events":["rental.started"],"createdAt":"2024-05-01T12:00:00Z"}`
+
+	tests := map[string]struct {
+		mockWebhookServiceHandler func(mock *mockwebhook.MockService)
+		method                    string
+		path                      string
+		body                      string
+		expectedCode              int
+		expectedBody              string
+	}{
+		"successfully created webhook": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().Subscribe(
+					gomock.Any(),
+					testWebhookURL,
+					[]webhookmodel.EventType{webhookmodel.EventRentalStarted},
+					testWebhookSecret,
+				).Return(subscription, nil).Times(1)
+			},
+			method: http.MethodPost,
+			path:   webhooksPath,
+			body: `{"url":"` + testWebhookURL + `","events":["rental.started"],` +
+				`"secret":"` + testWebhookSecret + `"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: webhookBody,
+		},
+		"failed creating webhook because of invalid body": {
+			mockWebhookServiceHandler: nil,
+			method:                    http.MethodPost,
+			path:                      webhooksPath,
+			body:                      `{"url":"ftp://partner.example.com","events":[],"secret":"short"}`,
+			expectedCode:              http.StatusUnprocessableEntity,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating request body.", testRequestID,
+				model.FieldError{Field: "url", Code: "http_url", Detail: "must be an HTTP or HTTPS URL"},
+				model.FieldError{Field: "events", Code: "min", Detail: "must have at least 1 items"},
+				model.FieldError{Field: "secret", Code: "min", Detail: "must be at least 16 characters long"},
+			),
+		},
+		"failed creating webhook because of unknown event": {
+			mockWebhookServiceHandler: nil,
+			method:                    http.MethodPost,
+			path:                      webhooksPath,
+			body: `{"url":"` + testWebhookURL + `","events":["rental.paused"],` +
+				`"secret":"` + testWebhookSecret + `"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusUnprocessableEntity, codeValidationFailed, "Failed validating request body.", testRequestID,
+				model.FieldError{
+					Field:  "events[0]",
+					Code:   "oneof",
+					Detail: "must be one of: rental.started rental.ended",
+				},
+			),
+		},
+		"successfully listed webhooks": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().GetSubscriptions(gomock.Any()).
+					Return([]*webhookmodel.Subscription{subscription}, nil).Times(1)
+			},
+			method:       http.MethodGet,
+			path:         webhooksPath,
+			expectedCode: http.StatusOK,
+			expectedBody: "[" + webhookBody + "]",
+		},
+		"successfully deleted webhook": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().Unsubscribe(gomock.Any(), webhookUUID).Return(nil).Times(1)
+			},
+			method:       http.MethodDelete,
+			path:         webhooksPath + "/" + webhookUUID.String(),
+			expectedCode: http.StatusNoContent,
+			expectedBody: "",
+		},
+		"failed deleting webhook because it does not exist": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().Unsubscribe(gomock.Any(), webhookUUID).Return(service.ErrWebhookNotFound).Times(1)
+			},
+			method:       http.MethodDelete,
+			path:         webhooksPath + "/" + webhookUUID.String(),
+			expectedCode: http.StatusNotFound,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusNotFound, codeWebhookNotFound, "Webhook not found.", testRequestID,
+			),
+		},
+		"successfully listed dead letters": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().GetDeadLetters(gomock.Any()).Return([]*webhookmodel.Delivery{}, nil).Times(1)
+			},
+			method:       http.MethodGet,
+			path:         deadLettersPath,
+			expectedCode: http.StatusOK,
+			expectedBody: "[]",
+		},
+		"successfully replayed dead letter": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().Replay(gomock.Any(), deliveryUUID).Return(nil).Times(1)
+			},
+			method:       http.MethodPost,
+			path:         deadLettersPath + "/" + deliveryUUID.String() + "/replay",
+			expectedCode: http.StatusNoContent,
+			expectedBody: "",
+		},
+		"failed replaying dead letter because the receiver is still failing": {
+			mockWebhookServiceHandler: func(mock *mockwebhook.MockService) {
+				mock.EXPECT().Replay(gomock.Any(), deliveryUUID).Return(webhook.ErrDeliveryFailed).Times(1)
+			},
+			method:       http.MethodPost,
+			path:         deadLettersPath + "/" + deliveryUUID.String() + "/replay",
+			expectedCode: http.StatusBadGateway,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusBadGateway, codeDeliveryFailed, "Webhook receiver rejected the delivery.",
+				testRequestID,
+			),
+		},
+		"failed replaying dead letter because of invalid deliveryID": {
+			mockWebhookServiceHandler: nil,
+			method:                    http.MethodPost,
+			path:                      deadLettersPath + "/delivery/replay",
+			expectedCode:              http.StatusBadRequest,
+			expectedBody: problemBodyWithRequestID(
+				http.StatusBadRequest, codeMalformedRequest, "Failed parsing deliveryID.", testRequestID,
+			),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _, _, _ := beforeTest(t)
+
+			mockWebhookService := mockwebhook.NewMockService(gomock.NewController(t))
+			s.webhookService = mockWebhookService
+
+			if tt.mockWebhookServiceHandler != nil {
+				tt.mockWebhookServiceHandler(mockWebhookService)
+			}
+
+			request := httptest.NewRequest(tt.method, api+version+tt.path, bytes.NewBufferString(tt.body))
+			request.Header.Set(headerRequestID, testRequestID)
+			request.Header.Set(headerAuthorization, bearerPrefix+testAdminToken)
+
+			responseRecorder := httptest.NewRecorder()
+
+			s.router.ServeHTTP(responseRecorder, request)
+
+			if status := responseRecorder.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got = %v want = %v",
+					status, tt.expectedCode)
+			}
+
+			if body := responseRecorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got = %v want = %v",
+					body, tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+type ComponentHealthGet struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthGet struct {
+	Status     string                        `json:"status"`
+	Components map[string]ComponentHealthGet `json:"components,omitempty"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// LiveMapSnapshot is the first message of the live map, listing the scooters in the viewport.
+type LiveMapSnapshot struct {
+	Type     string       `json:"type" enums:"snapshot"`
+	Scooters []ScooterGet `json:"scooters"`
+}
+
+// LiveMapEvent is the message of the live map telling about the scooter entering, moving within, changing its state
+// in or leaving the viewport.
+type LiveMapEvent struct {
+	Type    string     `json:"type" enums:"added,moved,state,removed"`
+	Scooter ScooterGet `json:"scooter"`
+}
@@ -0,0 +1,20 @@
+package model
+
+// Problem describes a failed request as defined by RFC 7807. Code is stable and meant for the clients to tell the
+// failures apart, while Title and Detail are meant for humans.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a field of the request was rejected, Code being the name of the broken rule.
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
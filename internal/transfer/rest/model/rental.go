@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RentalGet struct {
+	RentalUUID     uuid.UUID  `json:"UUID"`
+	ScooterUUID    uuid.UUID  `json:"scooterUUID"`
+	City           string     `json:"city"`
+	Status         string     `json:"status"`
+	StartLongitude float64    `json:"startLongitude"`
+	StartLatitude  float64    `json:"startLatitude"`
+	StartedAt      time.Time  `json:"startedAt"`
+	EndedAt        *time.Time `json:"endedAt,omitempty"`
+}
+
+// ActiveRentalGet is the ride in progress, positioned where its scooter was last tracked and priced so far.
+type ActiveRentalGet struct {
+	RentalUUID     uuid.UUID `json:"UUID"`
+	ScooterUUID    uuid.UUID `json:"scooterUUID"`
+	City           string    `json:"city"`
+	StartedAt      time.Time `json:"startedAt"`
+	ElapsedSeconds int64     `json:"elapsedSeconds"`
+	Longitude      float64   `json:"longitude"`
+	Latitude       float64   `json:"latitude"`
+	Fare           FareGet   `json:"fare"`
+}
+
+// FareGet is the price in the minor units of the currency, e.g. cents.
+type FareGet struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// RideEventGet is the data of the event streamed during the ride. The position and the distance travelled, in meters,
+// are left out of the ended event of a ride that ended before the stream was opened.
+type RideEventGet struct {
+	Longitude      *float64  `json:"longitude,omitempty"`
+	Latitude       *float64  `json:"latitude,omitempty"`
+	DistanceMeters *float64  `json:"distanceMeters,omitempty"`
+	ElapsedSeconds int64     `json:"elapsedSeconds"`
+	At             time.Time `json:"at"`
+}
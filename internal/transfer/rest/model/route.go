@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RouteGet is the route of the rental as a GeoJSON feature (RFC 7946). The timestamps follow the coordinates of the
+// line one to one, and the geometry is null until the route holds at least two points.
+type RouteGet struct {
+	Type       string           `json:"type" example:"Feature"`
+	Geometry   *LineStringGet   `json:"geometry"`
+	Properties RoutePropertyGet `json:"properties"`
+}
+
+// LineStringGet is the GeoJSON line, every position being the longitude followed by the latitude.
+type LineStringGet struct {
+	Type        string       `json:"type" example:"LineString"`
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+type RoutePropertyGet struct {
+	RentalUUID  uuid.UUID   `json:"rentalUUID"`
+	ScooterUUID uuid.UUID   `json:"scooterUUID"`
+	Timestamps  []time.Time `json:"timestamps"`
+}
+
+// RouteGPX is the route of the rental as a GPX 1.1 document holding a single track.
+type RouteGPX struct {
+	XMLName xml.Name      `xml:"gpx"`
+	XMLNS   string        `xml:"xmlns,attr"`
+	Version string        `xml:"version,attr"`
+	Creator string        `xml:"creator,attr"`
+	Track   RouteGPXTrack `xml:"trk"`
+}
+
+type RouteGPXTrack struct {
+	Name    string               `xml:"name"`
+	Segment RouteGPXTrackSegment `xml:"trkseg"`
+}
+
+type RouteGPXTrackSegment struct {
+	Points []RouteGPXPoint `xml:"trkpt"`
+}
+
+type RouteGPXPoint struct {
+	Latitude  float64   `xml:"lat,attr"`
+	Longitude float64   `xml:"lon,attr"`
+	Time      time.Time `xml:"time"`
+}
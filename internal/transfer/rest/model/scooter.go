@@ -1,31 +1,32 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type ScooterGet struct {
-	ScooterUUID  uuid.UUID `json:"UUID"`
-	Longitude    float64   `json:"longitude"`
-	Latitude     float64   `json:"latitude"`
-	Availability bool      `json:"availability"`
+	ScooterUUID  uuid.UUID  `json:"UUID"`
+	City         string     `json:"city,omitempty"`
+	Longitude    float64    `json:"longitude"`
+	Latitude     float64    `json:"latitude"`
+	Availability bool       `json:"availability"`
+	State        string     `json:"state,omitempty" enums:"available,rented"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
 }
 
 type RentPost struct {
 	ScooterUUID uuid.UUID `json:"UUID" validate:"required"`
-	Longitude   float64   `json:"longitude" validate:"required"`
-	Latitude    float64   `json:"latitude" validate:"required"`
-	City        string    `json:"city" validate:"required"`
+	Longitude   *float64  `json:"longitude" validate:"required,lon"`
+	Latitude    *float64  `json:"latitude" validate:"required,lat"`
+	City        string    `json:"city" validate:"required,max=100"`
 }
 
 type FreePost struct {
 	ScooterUUID uuid.UUID `json:"UUID" validate:"required"`
 }
 
-type ApiError struct {
-	Message string `json:"Message"`
-}
-
 func FilterScooters(scooters []ScooterGet, f func(s *ScooterGet) bool) []ScooterGet {
 	var filtered []ScooterGet
 
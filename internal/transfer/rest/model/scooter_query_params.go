@@ -1,10 +1,10 @@
 package model
 
 type ScooterQueryParams struct {
-	Longitude    float64 `json:"longitude" validate:"required"`
-	Latitude     float64 `json:"latitude" validate:"required"`
-	Height       float64 `json:"height" validate:"required"`
-	Width        float64 `json:"width" validate:"required"`
-	City         string  `json:"city" validate:"required"`
-	Availability *bool   `json:"availability"`
+	Longitude    *float64 `json:"longitude" validate:"required,lon"`
+	Latitude     *float64 `json:"latitude" validate:"required,lat"`
+	Height       float64  `json:"height" validate:"required,gt=0"`
+	Width        float64  `json:"width" validate:"required,gt=0"`
+	City         string   `json:"city" validate:"required,max=100"`
+	Availability *bool    `json:"availability"`
 }
@@ -0,0 +1,7 @@
+package model
+
+// TelemetryResult tells how many readings of the batch were accepted and how many were dropped as already received.
+type TelemetryResult struct {
+	Accepted   int `json:"accepted"`
+	Duplicates int `json:"duplicates"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserProfile struct {
+	Name          string `json:"name" validate:"required,max=100"`
+	Email         string `json:"email" validate:"required,email"`
+	Phone         string `json:"phone,omitempty" validate:"omitempty,e164"`
+	PreferredCity string `json:"preferredCity,omitempty" validate:"max=100"`
+}
+
+type UserGet struct {
+	UserUUID      uuid.UUID `json:"UUID"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Phone         string    `json:"phone,omitempty"`
+	PreferredCity string    `json:"preferredCity,omitempty"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type UserStatusPut struct {
+	Status string `json:"status" validate:"required,oneof=active suspended"`
+}
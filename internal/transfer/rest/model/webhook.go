@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookPost struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=rental.started rental.ended"`
+	Secret string   `json:"secret" validate:"required,min=16,max=256"`
+}
+
+// WebhookGet is the subscription without its secret, which is only ever sent by the admin creating it.
+type WebhookGet struct {
+	WebhookUUID uuid.UUID `json:"UUID"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type DeadLetterGet struct {
+	DeliveryUUID uuid.UUID `json:"UUID"`
+	WebhookUUID  uuid.UUID `json:"webhookUUID"`
+	EventUUID    uuid.UUID `json:"eventUUID"`
+	Event        string    `json:"event"`
+	RentalUUID   uuid.UUID `json:"rentalUUID"`
+	OccurredAt   time.Time `json:"occurredAt"`
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"lastError"`
+	FailedAt     time.Time `json:"failedAt"`
+}
@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/rental"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+)
+
+// domainErrors maps the errors of the services and the repository to the statuses, the first matching one wins.
+var domainErrors = []struct {
+	err     error
+	code    codes.Code
+	message string
+}{
+	{repository.ErrScooterNotFound, codes.NotFound, "Scooter not found."},
+	{repository.ErrScooterNotAvailable, codes.FailedPrecondition, "Scooter is not available."},
+	{rental.ErrInvalidScooterUUID, codes.InvalidArgument, "Scooter ID is invalid."},
+	{service.ErrRentalNotFound, codes.NotFound, "Rental not found."},
+	{rental.ErrRentalEnded, codes.FailedPrecondition, "Rental has already ended."},
+	{service.ErrUserNotFound, codes.NotFound, "User not found."},
+	{service.ErrUserAlreadyExists, codes.AlreadyExists, "User is already registered."},
+	{service.ErrUserConflict, codes.Aborted, "User was changed concurrently, try again."},
+	{redis.TxFailedErr, codes.Aborted, "Changed concurrently by another request, try again."},
+	{tracker.ErrTrackerDegraded, codes.Unavailable, "Scooter tracking is degraded."},
+}
+
+// domainError returns the status of a failed service call. Known domain errors are mapped to their codes, a call
+// rejected by an open circuit breaker ends with Unavailable, a denied one with PermissionDenied carrying the reason
+// and any other error with Internal and the given message.
+func domainError(err error, message string) error {
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		return status.Error(codes.Unavailable, "Service temporarily unavailable.")
+	}
+
+	if errors.Is(err, auth.ErrPermissionDenied) {
+		reason := auth.ReasonMissingPermission
+
+		var deniedErr *auth.DeniedError
+		if errors.As(err, &deniedErr) {
+			reason = deniedErr.Reason
+		}
+
+		return status.Error(codes.PermissionDenied, reason)
+	}
+
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr.err) {
+			return status.Error(domainErr.code, domainErr.message)
+		}
+	}
+
+	return status.Error(codes.Internal, message)
+}
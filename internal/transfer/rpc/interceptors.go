@@ -0,0 +1,188 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rpc/pb"
+)
+
+const (
+	metadataRequestID     = "x-request-id"
+	metadataAuthorization = "authorization"
+	metadataClientID      = "client-id"
+	bearerPrefix          = "Bearer "
+
+	maxRequestIDLength = 128
+)
+
+// methodPermissions lists the permission each method requires, the same as its REST counterpart.
+var methodPermissions = map[string]auth.Permission{
+	pb.RentalService_SearchScooters_FullMethodName: auth.PermissionScootersRead,
+	pb.RentalService_RentScooter_FullMethodName:    auth.PermissionScootersRent,
+	pb.RentalService_FreeScooter_FullMethodName:    auth.PermissionScootersFree,
+	pb.RentalService_TrackRide_FullMethodName:      auth.PermissionRentalsRead,
+}
+
+// LogUnaryCalls assigns every call an ID (reusing the one sent in the x-request-id metadata), puts a logger carrying
+// it into the call context and writes an access log line once the call is handled.
+func LogUnaryCalls(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, done := logCall(ctx, logger, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+		done(err)
+
+		return resp, err
+	}
+}
+
+// LogStreamCalls does for the streaming calls what LogUnaryCalls does for the unary ones.
+func LogStreamCalls(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := logCall(stream.Context(), logger, info.FullMethod)
+
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		done(err)
+
+		return err
+	}
+}
+
+// AuthenticateUnaryCalls resolves the identity of the caller like the AuthenticateUser middleware of the REST API
+// and checks the permission the method requires.
+func AuthenticateUnaryCalls(authenticator *auth.Authenticator, users user.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, users, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthenticateStreamCalls does for the streaming calls what AuthenticateUnaryCalls does for the unary ones.
+func AuthenticateStreamCalls(authenticator *auth.Authenticator, users user.Service) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator, users, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, method string) (context.Context, func(err error)) {
+	start := time.Now()
+
+	requestID := firstMetadata(ctx, metadataRequestID)
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = uuid.NewString()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID))
+
+	callLogger := logger.With(
+		slog.String("request_id", requestID),
+		slog.String("method", method),
+	)
+
+	return logging.WithLogger(ctx, callLogger), func(err error) {
+		callLogger.Info(
+			"call handled",
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("latency", time.Since(start)),
+		)
+	}
+}
+
+func authenticate(
+	ctx context.Context,
+	authenticator *auth.Authenticator,
+	users user.Service,
+	method string,
+) (context.Context, error) {
+	logger := logging.FromContext(ctx)
+
+	bearerToken, _ := strings.CutPrefix(firstMetadata(ctx, metadataAuthorization), bearerPrefix)
+
+	identity, err := authenticator.Authenticate(ctx, bearerToken, firstMetadata(ctx, metadataClientID))
+	if err != nil {
+		logger.Error("Failed to authenticate user", slog.Any("err", err))
+
+		return nil, status.Error(codes.Unauthenticated, "Failed authenticating client.")
+	}
+
+	logger = logger.With(
+		slog.String("client_id", identity.Subject.String()),
+		slog.String("auth_method", identity.Method),
+	)
+
+	if identity.Method != auth.MethodAdminToken {
+		registeredUser, userErr := users.GetUser(ctx, identity.Subject)
+
+		switch {
+		case errors.Is(userErr, service.ErrUserNotFound):
+			logger.Error("Failed to authenticate unregistered user")
+
+			return nil, status.Error(codes.PermissionDenied, auth.ReasonUserNotRegistered)
+		case userErr != nil:
+			logger.Error("Failed to get user", slog.Any("err", userErr))
+
+			return nil, domainError(userErr, "Failed authenticating client.")
+		case !registeredUser.Active():
+			logger.Error("Failed to authenticate suspended user")
+
+			return nil, status.Error(codes.PermissionDenied, auth.ReasonUserSuspended)
+		}
+	}
+
+	permission, ok := methodPermissions[method]
+	if !ok {
+		logger.Error("Failed to authorize call of method without permission")
+
+		return nil, status.Error(codes.PermissionDenied, auth.ReasonMissingPermission)
+	}
+
+	if err = identity.Authorize(permission); err != nil {
+		logger.Error("Failed to authorize user", slog.Any("err", err))
+
+		return nil, domainError(err, "Permission denied.")
+	}
+
+	return auth.WithIdentity(logging.WithLogger(ctx, logger), identity), nil
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// contextStream replaces the context of the stream with the one the interceptors put the logger and identity into.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (cs *contextStream) Context() context.Context {
+	return cs.ctx
+}
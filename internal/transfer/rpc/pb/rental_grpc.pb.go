@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: rental.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RentalService_SearchScooters_FullMethodName = "/scooterrental.v1.RentalService/SearchScooters"
+	RentalService_RentScooter_FullMethodName    = "/scooterrental.v1.RentalService/RentScooter"
+	RentalService_FreeScooter_FullMethodName    = "/scooterrental.v1.RentalService/FreeScooter"
+	RentalService_TrackRide_FullMethodName      = "/scooterrental.v1.RentalService/TrackRide"
+)
+
+// RentalServiceClient is the client API for RentalService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RentalService is the gRPC counterpart of the REST API for the backend services. The callers authenticate with the
+// same credentials, sent in the authorization (bearer token) or client-id metadata.
+type RentalServiceClient interface {
+	// SearchScooters returns the scooters in the rectangle of the city.
+	SearchScooters(ctx context.Context, in *SearchScootersRequest, opts ...grpc.CallOption) (*SearchScootersResponse, error)
+	// RentScooter rents the scooter where it stands and starts tracking it.
+	RentScooter(ctx context.Context, in *RentScooterRequest, opts ...grpc.CallOption) (*Rental, error)
+	// FreeScooter ends the rental of the caller, freeing its scooter, and stops tracking the scooter.
+	FreeScooter(ctx context.Context, in *FreeScooterRequest, opts ...grpc.CallOption) (*Rental, error)
+	// TrackRide streams the ride of the caller: a position event for every move of the scooter and the ended event
+	// closing the stream.
+	TrackRide(ctx context.Context, in *TrackRideRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RideEvent], error)
+}
+
+type rentalServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRentalServiceClient(cc grpc.ClientConnInterface) RentalServiceClient {
+	return &rentalServiceClient{cc}
+}
+
+func (c *rentalServiceClient) SearchScooters(ctx context.Context, in *SearchScootersRequest, opts ...grpc.CallOption) (*SearchScootersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchScootersResponse)
+	err := c.cc.Invoke(ctx, RentalService_SearchScooters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalServiceClient) RentScooter(ctx context.Context, in *RentScooterRequest, opts ...grpc.CallOption) (*Rental, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Rental)
+	err := c.cc.Invoke(ctx, RentalService_RentScooter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalServiceClient) FreeScooter(ctx context.Context, in *FreeScooterRequest, opts ...grpc.CallOption) (*Rental, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Rental)
+	err := c.cc.Invoke(ctx, RentalService_FreeScooter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalServiceClient) TrackRide(ctx context.Context, in *TrackRideRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RideEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RentalService_ServiceDesc.Streams[0], RentalService_TrackRide_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TrackRideRequest, RideEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RentalService_TrackRideClient = grpc.ServerStreamingClient[RideEvent]
+
+// RentalServiceServer is the server API for RentalService service.
+// All implementations must embed UnimplementedRentalServiceServer
+// for forward compatibility.
+//
+// RentalService is the gRPC counterpart of the REST API for the backend services. The callers authenticate with the
+// same credentials, sent in the authorization (bearer token) or client-id metadata.
+type RentalServiceServer interface {
+	// SearchScooters returns the scooters in the rectangle of the city.
+	SearchScooters(context.Context, *SearchScootersRequest) (*SearchScootersResponse, error)
+	// RentScooter rents the scooter where it stands and starts tracking it.
+	RentScooter(context.Context, *RentScooterRequest) (*Rental, error)
+	// FreeScooter ends the rental of the caller, freeing its scooter, and stops tracking the scooter.
+	FreeScooter(context.Context, *FreeScooterRequest) (*Rental, error)
+	// TrackRide streams the ride of the caller: a position event for every move of the scooter and the ended event
+	// closing the stream.
+	TrackRide(*TrackRideRequest, grpc.ServerStreamingServer[RideEvent]) error
+	mustEmbedUnimplementedRentalServiceServer()
+}
+
+// UnimplementedRentalServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRentalServiceServer struct{}
+
+func (UnimplementedRentalServiceServer) SearchScooters(context.Context, *SearchScootersRequest) (*SearchScootersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchScooters not implemented")
+}
+func (UnimplementedRentalServiceServer) RentScooter(context.Context, *RentScooterRequest) (*Rental, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RentScooter not implemented")
+}
+func (UnimplementedRentalServiceServer) FreeScooter(context.Context, *FreeScooterRequest) (*Rental, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FreeScooter not implemented")
+}
+func (UnimplementedRentalServiceServer) TrackRide(*TrackRideRequest, grpc.ServerStreamingServer[RideEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method TrackRide not implemented")
+}
+func (UnimplementedRentalServiceServer) mustEmbedUnimplementedRentalServiceServer() {}
+func (UnimplementedRentalServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeRentalServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RentalServiceServer will
+// result in compilation errors.
+type UnsafeRentalServiceServer interface {
+	mustEmbedUnimplementedRentalServiceServer()
+}
+
+func RegisterRentalServiceServer(s grpc.ServiceRegistrar, srv RentalServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRentalServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RentalService_ServiceDesc, srv)
+}
+
+func _RentalService_SearchScooters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchScootersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalServiceServer).SearchScooters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalService_SearchScooters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalServiceServer).SearchScooters(ctx, req.(*SearchScootersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalService_RentScooter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RentScooterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalServiceServer).RentScooter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalService_RentScooter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalServiceServer).RentScooter(ctx, req.(*RentScooterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalService_FreeScooter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreeScooterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalServiceServer).FreeScooter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalService_FreeScooter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalServiceServer).FreeScooter(ctx, req.(*FreeScooterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalService_TrackRide_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TrackRideRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RentalServiceServer).TrackRide(m, &grpc.GenericServerStream[TrackRideRequest, RideEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RentalService_TrackRideServer = grpc.ServerStreamingServer[RideEvent]
+
+// RentalService_ServiceDesc is the grpc.ServiceDesc for RentalService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RentalService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scooterrental.v1.RentalService",
+	HandlerType: (*RentalServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchScooters",
+			Handler:    _RentalService_SearchScooters_Handler,
+		},
+		{
+			MethodName: "RentScooter",
+			Handler:    _RentalService_RentScooter_Handler,
+		},
+		{
+			MethodName: "FreeScooter",
+			Handler:    _RentalService_FreeScooter_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TrackRide",
+			Handler:       _RentalService_TrackRide_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rental.proto",
+}
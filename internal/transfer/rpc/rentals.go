@@ -0,0 +1,327 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"math"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rpc/pb"
+)
+
+const maxCityLength = 100
+
+// SearchScooters returns the scooters in the rectangle of the city, of the requested availability if it is set.
+func (s *Server) SearchScooters(
+	ctx context.Context,
+	req *pb.SearchScootersRequest,
+) (*pb.SearchScootersResponse, error) {
+	ctxLogger := logging.FromContext(ctx)
+
+	if err := validateSearch(req); err != nil {
+		ctxLogger.Error("failed to validate request", slog.Any("err", err))
+
+		return nil, err
+	}
+
+	if err := authorizeCity(ctx, req.GetCity()); err != nil {
+		return nil, err
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("city", req.GetCity()))
+
+	scooters, err := s.rentalService.GetScooters(ctx, rentalmodel.NewRectangle(
+		req.GetCity(),
+		req.GetLongitude(),
+		req.GetLatitude(),
+		req.GetHeight(),
+		req.GetWidth(),
+	))
+	if err != nil {
+		ctxLogger.Error("failed to get scooters from rental service", slog.Any("err", err))
+
+		return nil, domainError(err, "Failed getting scooters.")
+	}
+
+	response := &pb.SearchScootersResponse{Scooters: make([]*pb.Scooter, 0, len(scooters))}
+
+	for _, scooter := range scooters {
+		if req.Availability != nil && scooter.Availability != req.GetAvailability() {
+			continue
+		}
+
+		response.Scooters = append(response.Scooters, toScooter(scooter))
+	}
+
+	ctxLogger.Info("successfully received scooters")
+
+	return response, nil
+}
+
+// RentScooter rents the scooter where it stands and starts tracking it, like POST /v2/scooters/{scooterID}/rentals.
+func (s *Server) RentScooter(ctx context.Context, req *pb.RentScooterRequest) (*pb.Rental, error) {
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scooterUUID, err := uuid.Parse(req.GetScooterId())
+	if err != nil {
+		ctxLogger.Error("failed to parse scooterID", slog.Any("err", err))
+
+		return nil, status.Error(codes.InvalidArgument, "Failed parsing scooterID.")
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("scooter_id", scooterUUID.String()))
+
+	rentalScooter, err := s.rentalService.GetScooter(ctx, scooterUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get scooter", slog.Any("err", err))
+
+		return nil, domainError(err, "Failed renting scooter.")
+	}
+
+	if err = authorizeCity(ctx, rentalScooter.City); err != nil {
+		return nil, err
+	}
+
+	ctxLogger.Info("Renting scooter.")
+
+	rental, err := s.rentalService.Rent(ctx, clientUUID, rentalmodel.NewRentInfo(
+		rentalScooter.Name,
+		rentalScooter.City,
+		rentalScooter.Longitude,
+		rentalScooter.Latitude,
+	))
+	if err != nil {
+		ctxLogger.Error("failed to rent a scooter", slog.Any("err", err))
+
+		return nil, domainError(err, "Failed renting scooter.")
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rental.UUID.String()))
+
+	ctxLogger.Info("Successfully rented scooter.")
+
+	return toRental(rental), nil
+}
+
+// FreeScooter ends the rental of the caller and stops tracking its scooter, like POST /v2/rentals/{rentalID}/end.
+func (s *Server) FreeScooter(ctx context.Context, req *pb.FreeScooterRequest) (*pb.Rental, error) {
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, rentalUUID, err := rentalRequest(ctx, req.GetRentalId())
+	if err != nil {
+		return nil, err
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rentalUUID.String()))
+
+	ctxLogger.Info("Ending rental.")
+
+	rental, err := s.rentalService.EndRental(ctx, clientUUID, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to end rental", slog.Any("err", err))
+
+		return nil, domainError(err, "Failed ending rental.")
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("scooter_id", rental.ScooterUUID.String()))
+
+	ctxLogger.Info("Successfully ended rental.")
+
+	return toRental(rental), nil
+}
+
+// TrackRide streams the ride of the caller like GET /v1/rentals/{rentalID}/stream does.
+func (s *Server) TrackRide(req *pb.TrackRideRequest, stream pb.RentalService_TrackRideServer) error {
+	ctx := stream.Context()
+
+	ctxLogger := logging.FromContext(ctx)
+
+	clientUUID, rentalUUID, err := rentalRequest(ctx, req.GetRentalId())
+	if err != nil {
+		return err
+	}
+
+	ctxLogger = ctxLogger.With(slog.String("rental_id", rentalUUID.String()))
+
+	rental, err := s.rentalService.GetRental(ctx, clientUUID, rentalUUID)
+	if err != nil {
+		ctxLogger.Error("failed to get rental", slog.Any("err", err))
+
+		return domainError(err, "Failed getting rental.")
+	}
+
+	events, unsubscribe := s.trackerService.Subscribe(rental.ScooterUUID)
+	defer unsubscribe()
+
+	// the ride could have ended before the subscription, in which case its ended event was missed
+	if rental.Active() {
+		if rental, err = s.rentalService.GetRental(ctx, clientUUID, rentalUUID); err != nil {
+			ctxLogger.Error("failed to get rental", slog.Any("err", err))
+
+			return domainError(err, "Failed getting rental.")
+		}
+	}
+
+	if !rental.Active() {
+		return stream.Send(&pb.RideEvent{
+			Type:    pb.RideEvent_TYPE_ENDED,
+			Elapsed: durationpb.New(rental.EndedAt.Sub(rental.StartedAt)),
+			At:      timestamppb.New(*rental.EndedAt),
+		})
+	}
+
+	ctxLogger.Info("Streaming rental.")
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctxLogger.Info("Client stopped streaming rental.")
+
+			return nil
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "Server is shutting down.")
+		case event, open := <-events:
+			if !open {
+				return nil
+			}
+
+			if err = stream.Send(toRideEvent(rental, event)); err != nil {
+				ctxLogger.Warn("Failed to stream rental event.", slog.Any("err", err))
+
+				return err
+			}
+
+			if event.Type == trackermodel.EventEnded {
+				ctxLogger.Info("Streamed end of rental.")
+
+				return nil
+			}
+		}
+	}
+}
+
+// validateSearch checks the request like the REST API validates the query params of the search.
+func validateSearch(req *pb.SearchScootersRequest) error {
+	switch {
+	case req.GetCity() == "" || len(req.GetCity()) > maxCityLength:
+		return status.Errorf(codes.InvalidArgument, "city must be between 1 and %d characters long", maxCityLength)
+	case math.Abs(req.GetLongitude()) > 180:
+		return status.Error(codes.InvalidArgument, "longitude must be between -180 and 180")
+	case math.Abs(req.GetLatitude()) > 90:
+		return status.Error(codes.InvalidArgument, "latitude must be between -90 and 90")
+	case req.GetHeight() <= 0:
+		return status.Error(codes.InvalidArgument, "height must be greater than 0")
+	case req.GetWidth() <= 0:
+		return status.Error(codes.InvalidArgument, "width must be greater than 0")
+	default:
+		return nil
+	}
+}
+
+// authorizeCity checks whether the authenticated client may act in the city.
+func authorizeCity(ctx context.Context, city string) error {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "Failed authenticating client.")
+	}
+
+	if err := identity.AuthorizeCity(city); err != nil {
+		logging.FromContext(ctx).Error("Failed to authorize user in city", slog.Any("err", err))
+
+		return domainError(err, "Permission denied.")
+	}
+
+	return nil
+}
+
+// clientUUIDFromContext returns the ID of the client authenticated by the interceptors.
+func clientUUIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		logging.FromContext(ctx).Error("failed to get clientID from context")
+
+		return uuid.Nil, status.Error(codes.Unauthenticated, "Failed authenticating client.")
+	}
+
+	return identity.Subject, nil
+}
+
+// rentalRequest returns the authenticated client and the rental it asks about.
+func rentalRequest(ctx context.Context, rentalID string) (uuid.UUID, uuid.UUID, error) {
+	clientUUID, err := clientUUIDFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	rentalUUID, err := uuid.Parse(rentalID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to parse rentalID", slog.Any("err", err))
+
+		return uuid.Nil, uuid.Nil, status.Error(codes.InvalidArgument, "Failed parsing rentalID.")
+	}
+
+	return clientUUID, rentalUUID, nil
+}
+
+func toScooter(scooter *rentalmodel.Scooter) *pb.Scooter {
+	result := &pb.Scooter{
+		Id:        scooter.Name,
+		City:      scooter.City,
+		Longitude: scooter.Longitude,
+		Latitude:  scooter.Latitude,
+		Available: scooter.Availability,
+	}
+
+	if !scooter.UpdatedAt.IsZero() {
+		result.UpdatedAt = timestamppb.New(scooter.UpdatedAt)
+	}
+
+	return result
+}
+
+func toRental(rental *rentalmodel.Rental) *pb.Rental {
+	result := &pb.Rental{
+		Id:             rental.UUID.String(),
+		ScooterId:      rental.ScooterUUID.String(),
+		City:           rental.City,
+		StartLongitude: rental.StartLongitude,
+		StartLatitude:  rental.StartLatitude,
+		StartedAt:      timestamppb.New(rental.StartedAt),
+	}
+
+	if rental.EndedAt != nil {
+		result.EndedAt = timestamppb.New(*rental.EndedAt)
+	}
+
+	return result
+}
+
+func toRideEvent(rental *rentalmodel.Rental, event trackermodel.Event) *pb.RideEvent {
+	eventType := pb.RideEvent_TYPE_POSITION
+	if event.Type == trackermodel.EventEnded {
+		eventType = pb.RideEvent_TYPE_ENDED
+	}
+
+	return &pb.RideEvent{
+		Type:           eventType,
+		Longitude:      event.Longitude,
+		Latitude:       event.Latitude,
+		DistanceMeters: event.Distance,
+		Elapsed:        durationpb.New(event.At.Sub(rental.StartedAt)),
+		At:             timestamppb.New(event.At),
+	}
+}
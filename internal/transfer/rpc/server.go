@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/rental"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rpc/pb"
+)
+
+//go:generate buf generate
+
+// Server serves the gRPC API on top of the same services as the REST one.
+type Server struct {
+	pb.UnimplementedRentalServiceServer
+
+	logger         *slog.Logger
+	address        string
+	grpcServer     *grpc.Server
+	rentalService  rental.RentalService
+	trackerService tracker.Service
+	// closing is closed when the server shuts down, ending the ride streams that would otherwise keep it waiting.
+	closing chan struct{}
+}
+
+func NewServer(
+	logger *slog.Logger,
+	address string,
+	rental rental.RentalService,
+	tracker tracker.Service,
+	users user.Service,
+	authenticator *auth.Authenticator,
+) *Server {
+	s := &Server{
+		logger:         logger,
+		address:        address,
+		rentalService:  rental,
+		trackerService: tracker,
+		closing:        make(chan struct{}),
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			LogUnaryCalls(logger),
+			AuthenticateUnaryCalls(authenticator, users),
+		),
+		grpc.ChainStreamInterceptor(
+			LogStreamCalls(logger),
+			AuthenticateStreamCalls(authenticator, users),
+		),
+	)
+
+	pb.RegisterRentalServiceServer(s.grpcServer, s)
+
+	return s
+}
+
+// Serve listens on the address and serves the calls until the server is shut down.
+func (s *Server) Serve() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.address, err)
+	}
+
+	s.logger.Info("Starting gRPC server.", slog.String("address", s.address))
+
+	if err = s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("serving grpc: %w", err)
+	}
+
+	s.logger.Info("Stopping gRPC server.")
+
+	return nil
+}
+
+// Shutdown ends the ride streams and waits for the other calls in progress to finish.
+func (s *Server) Shutdown() {
+	close(s.closing)
+
+	s.grpcServer.GracefulStop()
+}
@@ -0,0 +1,399 @@
+//go:build unit
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
+	"github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	mockrental "github.com/PatrykPasterny/scooter-rental/internal/service/rental/mock"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
+	mocktracker "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/mock"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+	mockuser "github.com/PatrykPasterny/scooter-rental/internal/service/user/mock"
+	usermodel "github.com/PatrykPasterny/scooter-rental/internal/service/user/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rpc/pb"
+)
+
+const (
+	testCity      = "Montreal"
+	testLongitude = 70.0
+	testLatitude  = 60.0
+	testHeight    = 10000.0
+	testWidth     = 15000.0
+)
+
+var testStartedAt = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSearchScooters(t *testing.T) {
+	clientUUID := uuid.New()
+
+	availableScooter := rentalmodel.NewScooter(uuid.NewString(), testCity, testLongitude, testLatitude, true)
+	rentedScooter := rentalmodel.NewScooter(uuid.NewString(), testCity, testLongitude, testLatitude, false)
+
+	rectangle := rentalmodel.NewRectangle(testCity, testLongitude, testLatitude, testHeight, testWidth)
+
+	available := true
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		request                  *pb.SearchScootersRequest
+		want                     *pb.SearchScootersResponse
+		wantCode                 codes.Code
+	}{
+		"successfully searched scooters": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooters(gomock.Any(), rectangle).
+					Return([]*rentalmodel.Scooter{availableScooter, rentedScooter}, nil).Times(1)
+			},
+			request: &pb.SearchScootersRequest{
+				City:         testCity,
+				Longitude:    testLongitude,
+				Latitude:     testLatitude,
+				Height:       testHeight,
+				Width:        testWidth,
+				Availability: &available,
+			},
+			want:     &pb.SearchScootersResponse{Scooters: []*pb.Scooter{toScooter(availableScooter)}},
+			wantCode: codes.OK,
+		},
+		"failed searching scooters because of invalid rectangle": {
+			request: &pb.SearchScootersRequest{
+				City:      testCity,
+				Longitude: 181,
+				Latitude:  testLatitude,
+				Height:    testHeight,
+				Width:     testWidth,
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"failed searching scooters because rental service threw error": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooters(gomock.Any(), rectangle).Return(nil, io.ErrUnexpectedEOF).Times(1)
+			},
+			request: &pb.SearchScootersRequest{
+				City:      testCity,
+				Longitude: testLongitude,
+				Latitude:  testLatitude,
+				Height:    testHeight,
+				Width:     testWidth,
+			},
+			wantCode: codes.Internal,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mockRentalService, _, _ := beforeTest(t, clientUUID)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			got, err := client.SearchScooters(withClientID(clientUUID), tt.request)
+			require.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.want != nil {
+				require.True(t, proto.Equal(tt.want, got), "SearchScooters() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRentScooter(t *testing.T) {
+	clientUUID := uuid.New()
+	scooterUUID := uuid.New()
+	rentalUUID := uuid.New()
+
+	scooter := rentalmodel.NewScooter(scooterUUID.String(), testCity, testLongitude, testLatitude, true)
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	rental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		scooterID                string
+		want                     *pb.Rental
+		wantCode                 codes.Code
+	}{
+		"successfully rented scooter": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(scooter, nil).Times(1)
+				mock.EXPECT().Rent(gomock.Any(), clientUUID, rentInfo).Return(rental, nil).Times(1)
+			},
+			scooterID: scooterUUID.String(),
+			want: &pb.Rental{
+				Id:             rentalUUID.String(),
+				ScooterId:      scooterUUID.String(),
+				City:           testCity,
+				StartLongitude: testLongitude,
+				StartLatitude:  testLatitude,
+				StartedAt:      timestamppb.New(testStartedAt),
+			},
+			wantCode: codes.OK,
+		},
+		"failed renting scooter because of invalid scooterID": {
+			scooterID: "scooter",
+			wantCode:  codes.InvalidArgument,
+		},
+		"failed renting scooter because it is not available": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(scooter, nil).Times(1)
+				mock.EXPECT().Rent(gomock.Any(), clientUUID, rentInfo).
+					Return(nil, repository.ErrScooterNotAvailable).Times(1)
+			},
+			scooterID: scooterUUID.String(),
+			wantCode:  codes.FailedPrecondition,
+		},
+		"failed renting scooter because another client rented it at the same time": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().GetScooter(gomock.Any(), scooterUUID).Return(scooter, nil).Times(1)
+				mock.EXPECT().Rent(gomock.Any(), clientUUID, rentInfo).
+					Return(nil, fmt.Errorf("renting scooter: %w", redis.TxFailedErr)).Times(1)
+			},
+			scooterID: scooterUUID.String(),
+			wantCode:  codes.Aborted,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mockRentalService, _, _ := beforeTest(t, clientUUID)
+
+			if tt.mockRentalServiceHandler != nil {
+				tt.mockRentalServiceHandler(mockRentalService)
+			}
+
+			got, err := client.RentScooter(withClientID(clientUUID), &pb.RentScooterRequest{ScooterId: tt.scooterID})
+			require.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.want != nil {
+				require.True(t, proto.Equal(tt.want, got), "RentScooter() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeScooter(t *testing.T) {
+	clientUUID := uuid.New()
+	scooterUUID := uuid.New()
+	rentalUUID := uuid.New()
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	endedRental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+	endedAt := testStartedAt.Add(time.Hour)
+	endedRental.EndedAt = &endedAt
+
+	tests := map[string]struct {
+		mockRentalServiceHandler func(mock *mockrental.MockRentalService)
+		want                     *pb.Rental
+		wantCode                 codes.Code
+	}{
+		"successfully freed scooter": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().EndRental(gomock.Any(), clientUUID, rentalUUID).Return(endedRental, nil).Times(1)
+			},
+			want:     toRental(endedRental),
+			wantCode: codes.OK,
+		},
+		"failed freeing scooter because rental does not exist": {
+			mockRentalServiceHandler: func(mock *mockrental.MockRentalService) {
+				mock.EXPECT().EndRental(gomock.Any(), clientUUID, rentalUUID).
+					Return(nil, service.ErrRentalNotFound).Times(1)
+			},
+			wantCode: codes.NotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, mockRentalService, _, _ := beforeTest(t, clientUUID)
+
+			tt.mockRentalServiceHandler(mockRentalService)
+
+			got, err := client.FreeScooter(withClientID(clientUUID), &pb.FreeScooterRequest{RentalId: rentalUUID.String()})
+			require.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.want != nil {
+				require.True(t, proto.Equal(tt.want, got), "FreeScooter() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackRide(t *testing.T) {
+	clientUUID := uuid.New()
+	scooterUUID := uuid.New()
+	rentalUUID := uuid.New()
+
+	rentInfo := rentalmodel.NewRentInfo(scooterUUID.String(), testCity, testLongitude, testLatitude)
+	rental := rentalmodel.NewRental(rentalUUID, clientUUID, scooterUUID, rentInfo, testStartedAt)
+
+	events := make(chan trackermodel.Event, 2)
+	events <- trackermodel.Event{
+		Type:        trackermodel.EventPosition,
+		ScooterUUID: scooterUUID,
+		Longitude:   testLongitude,
+		Latitude:    60.5,
+		Distance:    92.5,
+		At:          testStartedAt.Add(3 * time.Second),
+	}
+	events <- trackermodel.Event{
+		Type:        trackermodel.EventEnded,
+		ScooterUUID: scooterUUID,
+		Longitude:   testLongitude,
+		Latitude:    60.5,
+		Distance:    92.5,
+		At:          testStartedAt.Add(5 * time.Second),
+	}
+	close(events)
+
+	client, mockRentalService, mockTrackerService, _ := beforeTest(t, clientUUID)
+
+	mockRentalService.EXPECT().GetRental(gomock.Any(), clientUUID, rentalUUID).Return(rental, nil).Times(2)
+	mockTrackerService.EXPECT().Subscribe(scooterUUID).Return(events, func() {}).Times(1)
+
+	stream, err := client.TrackRide(withClientID(clientUUID), &pb.TrackRideRequest{RentalId: rentalUUID.String()})
+	require.NoError(t, err)
+
+	want := []*pb.RideEvent{
+		{
+			Type:           pb.RideEvent_TYPE_POSITION,
+			Longitude:      testLongitude,
+			Latitude:       60.5,
+			DistanceMeters: 92.5,
+			Elapsed:        durationpb.New(3 * time.Second),
+			At:             timestamppb.New(testStartedAt.Add(3 * time.Second)),
+		},
+		{
+			Type:           pb.RideEvent_TYPE_ENDED,
+			Longitude:      testLongitude,
+			Latitude:       60.5,
+			DistanceMeters: 92.5,
+			Elapsed:        durationpb.New(5 * time.Second),
+			At:             timestamppb.New(testStartedAt.Add(5 * time.Second)),
+		},
+	}
+
+	for i := range want {
+		got, recvErr := stream.Recv()
+		require.NoError(t, recvErr)
+		require.True(t, proto.Equal(want[i], got), "TrackRide() got = %v, want %v", got, want[i])
+	}
+
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestAuthentication(t *testing.T) {
+	clientUUID := uuid.New()
+
+	tests := map[string]struct {
+		mockUserServiceHandler func(mock *mockuser.MockService)
+		ctx                    context.Context
+		wantCode               codes.Code
+	}{
+		"failed calling without credentials": {
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+		"failed calling as unregistered user": {
+			mockUserServiceHandler: func(mock *mockuser.MockService) {
+				mock.EXPECT().GetUser(gomock.Any(), clientUUID).Return(nil, service.ErrUserNotFound).Times(1)
+			},
+			ctx:      withClientID(clientUUID),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, _, _, mockUserService := beforeTest(t, uuid.Nil)
+
+			if tt.mockUserServiceHandler != nil {
+				tt.mockUserServiceHandler(mockUserService)
+			}
+
+			_, err := client.RentScooter(tt.ctx, &pb.RentScooterRequest{ScooterId: uuid.NewString()})
+			require.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+// beforeTest serves the API over an in-memory connection. Unless the clientUUID is nil, it is registered as an active
+// rider.
+func beforeTest(t *testing.T, clientUUID uuid.UUID) (
+	pb.RentalServiceClient,
+	*mockrental.MockRentalService,
+	*mocktracker.MockService,
+	*mockuser.MockService,
+) {
+	t.Helper()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	controller := gomock.NewController(t)
+
+	mockRentalService := mockrental.NewMockRentalService(controller)
+	mockTrackerService := mocktracker.NewMockService(controller)
+	mockUserService := mockuser.NewMockService(controller)
+
+	if clientUUID != uuid.Nil {
+		mockUserService.EXPECT().GetUser(gomock.Any(), clientUUID).
+			Return(usermodel.NewUser(clientUUID, usermodel.Profile{}, testStartedAt), nil).AnyTimes()
+	}
+
+	s := NewServer(
+		logger,
+		"bufconn",
+		mockRentalService,
+		mockTrackerService,
+		mockUserService,
+		auth.NewAuthenticator(nil, "", true),
+	)
+
+	listener := bufconn.Listen(1 << 20)
+
+	go func() {
+		_ = s.grpcServer.Serve(listener)
+	}()
+
+	t.Cleanup(s.Shutdown)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewRentalServiceClient(conn), mockRentalService, mockTrackerService, mockUserService
+}
+
+func withClientID(clientUUID uuid.UUID) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), metadataClientID, clientUUID.String())
+}
@@ -0,0 +1,41 @@
+// Package telemetry holds the layout of the readings the scooters report, shared by the transports they report over.
+package telemetry
+
+import (
+	"time"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+// Batch is the batch of the readings of the scooter, the oldest first.
+type Batch struct {
+	Readings []Reading `json:"readings" validate:"required,min=1,max=100,dive"`
+}
+
+// Reading is a single report of the scooter. The speed is in kilometers per hour and the battery in percent.
+type Reading struct {
+	Longitude *float64  `json:"longitude" validate:"required,lon"`
+	Latitude  *float64  `json:"latitude" validate:"required,lat"`
+	Timestamp time.Time `json:"timestamp" validate:"required"`
+	Speed     *float64  `json:"speed" validate:"required,min=0"`
+	Battery   *int      `json:"battery" validate:"required,min=0,max=100"`
+	Locked    *bool     `json:"locked" validate:"required"`
+}
+
+// ToTracker converts the readings of the validated batch to the telemetry of the tracker.
+func (b *Batch) ToTracker() []*trackermodel.Telemetry {
+	telemetry := make([]*trackermodel.Telemetry, len(b.Readings))
+
+	for i, reading := range b.Readings {
+		telemetry[i] = &trackermodel.Telemetry{
+			Longitude: *reading.Longitude,
+			Latitude:  *reading.Latitude,
+			At:        reading.Timestamp.UTC(),
+			Speed:     *reading.Speed,
+			Battery:   *reading.Battery,
+			Locked:    *reading.Locked,
+		}
+	}
+
+	return telemetry
+}
@@ -0,0 +1,46 @@
+//go:build unit
+
+package telemetry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+func TestBatchToTracker(t *testing.T) {
+	payload := `{"readings":[
+		{"longitude":70.01,"latitude":60.01,"timestamp":"2024-05-01T14:00:00+02:00","speed":12.5,"battery":80,"locked":false},
+		{"longitude":70.02,"latitude":60.02,"timestamp":"2024-05-01T12:00:10Z","speed":0,"battery":79,"locked":true}
+	]}`
+
+	var batch Batch
+
+	require.NoError(t, json.Unmarshal([]byte(payload), &batch))
+
+	// the timestamps are taken in UTC, whatever zone the scooter reports them in
+	want := []*trackermodel.Telemetry{
+		{
+			Longitude: 70.01,
+			Latitude:  60.01,
+			At:        time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+			Speed:     12.5,
+			Battery:   80,
+			Locked:    false,
+		},
+		{
+			Longitude: 70.02,
+			Latitude:  60.02,
+			At:        time.Date(2024, time.May, 1, 12, 0, 10, 0, time.UTC),
+			Speed:     0,
+			Battery:   79,
+			Locked:    true,
+		},
+	}
+
+	require.Equal(t, want, batch.ToTracker())
+}
@@ -2,24 +2,49 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/redis/go-redis/v9"
 
+	"github.com/PatrykPasterny/scooter-rental/internal/auth"
 	"github.com/PatrykPasterny/scooter-rental/internal/config"
+	"github.com/PatrykPasterny/scooter-rental/internal/eventbus"
+	"github.com/PatrykPasterny/scooter-rental/internal/logging"
+	"github.com/PatrykPasterny/scooter-rental/internal/ratelimit"
 	redisservice "github.com/PatrykPasterny/scooter-rental/internal/repository"
+	"github.com/PatrykPasterny/scooter-rental/internal/resilience"
+	"github.com/PatrykPasterny/scooter-rental/internal/service"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/health"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/livemap"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/rental"
+	rentalmodel "github.com/PatrykPasterny/scooter-rental/internal/service/rental/model"
 	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/user"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/webhook"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/mqtt"
 	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rest/api"
+	"github.com/PatrykPasterny/scooter-rental/internal/transfer/rpc"
 )
 
-const configPath = "internal/config/default.env"
+const (
+	configPath = "internal/config/default.env"
+
+	// trackerEventGroup prefixes the consumer groups of the trackers, one per instance, as the rides are tracked in
+	// memory and every instance has to see the ends of the rentals it tracks.
+	trackerEventGroup = "tracker"
+	// webhookEventGroup is the consumer group delivering the events of the rentals to the webhooks.
+	webhookEventGroup = "webhooks"
+	// commandEventGroup is the consumer group publishing the lock and unlock commands to the scooters over MQTT.
+	commandEventGroup = "commands"
+)
 
 func main() {
 	cfg, err := config.NewConfig(context.Background(), configPath)
@@ -27,11 +52,39 @@ func main() {
 		log.Fatal(fmt.Errorf("config retrieval failed: %w", err))
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	logLevel, err := logging.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		log.Fatal(fmt.Errorf("log level parsing failed: %w", err))
+	}
+
+	logLevelVar := new(slog.LevelVar)
+	logLevelVar.Set(logLevel)
+
+	var logOutput io.Writer = os.Stdout
+
+	if cfg.Log.File != "" {
+		fileSink := logging.NewFileSink(
+			cfg.Log.File,
+			cfg.Log.FileMaxSizeMB,
+			cfg.Log.FileMaxBackups,
+			cfg.Log.FileMaxAgeDays,
+		)
+		defer fileSink.Close()
+
+		logOutput = io.MultiWriter(os.Stdout, fileSink)
+	}
+
+	logHandler, err := logging.NewHandler(logOutput, cfg.Log.Format, logLevelVar)
+	if err != nil {
+		log.Fatal(fmt.Errorf("log handler creation failed: %w", err))
+	}
+
+	logger := slog.New(logHandler)
+	slog.SetDefault(logger)
 
 	logger.Info("Starting Scootin Aboot")
 
-	validate := validator.New()
+	validate := api.NewValidator()
 
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Host,
@@ -39,6 +92,15 @@ func main() {
 		DB:       cfg.Redis.Database,
 	})
 
+	redisService := redisservice.NewRedisService(redisClient)
+
+	// the keys are written only once the database is known to hold them in the layout of this code
+	if err = redisService.EnsureSchemaVersion(context.Background()); err != nil {
+		logger.Error("failed to check redis schema version", slog.Any("err", err))
+
+		return
+	}
+
 	err = initializeRedis(redisClient)
 	if err != nil {
 		logger.Error("failed to initialize redis", slog.Any("err", err))
@@ -46,9 +108,82 @@ func main() {
 		return
 	}
 
-	redisService := redisservice.NewRedisService(redisClient)
-	trackerService := tracker.NewTrackingService(logger, redisService)
-	rentalService := rental.NewRentalService(redisService)
+	resilientRepository := redisservice.NewResilientRepository(
+		redisService,
+		resilience.NewRetrier(
+			cfg.Resilience.RetryAttempts,
+			cfg.Resilience.RetryInitialBackoff,
+			cfg.Resilience.RetryMaxBackoff,
+		),
+		resilience.NewCircuitBreaker(cfg.Resilience.BreakerFailureThreshold, cfg.Resilience.BreakerOpenTimeout),
+	)
+
+	liveMap := livemap.NewFeed()
+	scooterRepository := livemap.NewFeedingRepository(resilientRepository, liveMap)
+
+	eventBus := eventbus.NewStreamBus(
+		redisClient,
+		cfg.Events.Stream,
+		cfg.Events.MaxLen,
+		cfg.Events.ClaimMinIdle,
+		cfg.Events.DedupTTL,
+		resilience.NewRetrier(
+			cfg.Resilience.RetryAttempts,
+			cfg.Resilience.RetryInitialBackoff,
+			cfg.Resilience.RetryMaxBackoff,
+		),
+	)
+
+	eventConsumer, err := eventConsumerName(cfg.Events.Consumer)
+	if err != nil {
+		logger.Error("failed to name the event consumer", slog.Any("err", err))
+
+		return
+	}
+
+	rentalRepository := redisservice.NewRentalRepository(redisClient)
+
+	// the sessions are owned by the event consumer, as it is the one the rental events of the instance are delivered to
+	trackerService, err := tracker.NewTrackingService(
+		logging.WithLogger(context.Background(), logger),
+		scooterRepository,
+		rentalRepository,
+		redisservice.NewTelemetryRepository(redisClient),
+		redisservice.NewRouteRepository(redisClient),
+		redisservice.NewSessionRepository(redisClient, eventConsumer),
+		eventBus,
+		tracker.Mode(cfg.Tracking.Mode),
+		cfg.Tracking.MaxClockSkew,
+		cfg.Tracking.RouteTolerance,
+	)
+	if err != nil {
+		logger.Error("failed to start tracking", slog.Any("err", err))
+
+		return
+	}
+
+	rentalService := rental.NewRentalService(
+		scooterRepository,
+		rentalRepository,
+		rentalmodel.NewTariff(cfg.Fare.UnlockFee, cfg.Fare.PerMinute, cfg.Fare.Currency),
+	)
+	userService := user.NewUserService(redisservice.NewUserRepository(redisClient))
+	webhookService := webhook.NewWebhookService(
+		redisservice.NewWebhookRepository(redisClient),
+		&http.Client{Timeout: cfg.Webhook.Timeout},
+		resilience.NewRetrier(cfg.Webhook.RetryAttempts, cfg.Webhook.RetryInitialBackoff, cfg.Webhook.RetryMaxBackoff),
+	)
+
+	if err = userService.SeedUsers(context.Background(), userUUIDs(cfg.GetUserIDs())); err != nil {
+		logger.Error("failed to seed users", slog.Any("err", err))
+
+		return
+	}
+
+	healthService := health.NewService(cfg.Health.CheckTimeout)
+	healthService.Register("redis", redisService.Ping)
+	healthService.Register("schema", redisService.CheckSchema)
+	healthService.Register("tracker", trackerService.HealthCheck)
 
 	router := mux.NewRouter()
 
@@ -57,84 +192,250 @@ func main() {
 		Handler: router,
 	}
 
-	users := cfg.GetUsersMap()
+	authenticator, err := newAuthenticator(&cfg.Auth, cfg.AdminToken)
+	if err != nil {
+		logger.Error("failed to initialize authentication", slog.Any("err", err))
+
+		return
+	}
+
+	var rateLimiter *ratelimit.Limiter
+
+	if cfg.RateLimit.Enabled {
+		rateLimiter = ratelimit.NewLimiter(redisClient, cfg.RateLimit.Window, map[ratelimit.Bucket]map[auth.Role]int{
+			ratelimit.BucketSearch:   roleLimits(cfg.RateLimit.Search),
+			ratelimit.BucketMutation: roleLimits(cfg.RateLimit.Mutation),
+		})
+	}
+
+	companions := []api.Companion{
+		rpc.NewServer(
+			logger,
+			fmt.Sprintf(":%d", cfg.GRPC),
+			rentalService,
+			trackerService,
+			userService,
+			authenticator,
+		),
+		eventbus.NewRelay(
+			logger,
+			redisservice.NewOutboxRepository(redisClient),
+			eventBus,
+			resilience.NewRetrier(
+				cfg.Resilience.RetryAttempts,
+				cfg.Resilience.RetryInitialBackoff,
+				cfg.Resilience.RetryMaxBackoff,
+			),
+			cfg.Events.RelayInterval,
+		),
+		eventbus.NewWorker(
+			logger,
+			eventBus,
+			trackerEventGroup+":"+eventConsumer,
+			eventConsumer,
+			service.GroupStartLatest,
+			trackerService.HandleEvent,
+		),
+		eventbus.NewWorker(
+			logger,
+			eventBus,
+			webhookEventGroup,
+			eventConsumer,
+			service.GroupStartOldest,
+			webhook.NewEventHandler(webhookService, rentalRepository),
+		),
+	}
+
+	if cfg.MQTT.Enabled {
+		mqttClient := mqtt.NewClient(logger, validate, trackerService, &mqtt.Options{
+			Broker:         cfg.MQTT.Broker,
+			ClientID:       cfg.Name + "-" + eventConsumer,
+			Username:       cfg.MQTT.Username,
+			Password:       cfg.MQTT.Password,
+			TelemetryTopic: cfg.MQTT.TelemetryTopic,
+			TelemetryQoS:   cfg.MQTT.TelemetryQoS,
+			CommandTopic:   cfg.MQTT.CommandTopic,
+			CommandQoS:     cfg.MQTT.CommandQoS,
+			ShareGroup:     cfg.MQTT.ShareGroup,
+			Timeout:        cfg.MQTT.Timeout,
+		})
+
+		healthService.Register("mqtt", mqttClient.HealthCheck)
+
+		companions = append(
+			companions,
+			mqttClient,
+			eventbus.NewWorker(
+				logger,
+				eventBus,
+				commandEventGroup,
+				eventConsumer,
+				service.GroupStartOldest,
+				mqtt.NewCommandHandler(mqttClient),
+			),
+		)
+	}
+
+	server := api.NewServer(
+		logger,
+		validate,
+		httpServer,
+		router,
+		rentalService,
+		trackerService,
+		liveMap,
+		userService,
+		webhookService,
+		authenticator,
+		rateLimiter,
+		healthService,
+		cfg.Health.DrainDelay,
+		logLevelVar,
+		companions...,
+	)
+
+	watcher := config.NewWatcher(configPath, cfg, func(ctx context.Context, previous, current *config.Config) {
+		added, removed := config.DiffUsers(previous, current)
+
+		if syncErr := userService.SyncSeedUsers(ctx, userUUIDs(added), userUUIDs(removed)); syncErr != nil {
+			logging.FromContext(ctx).Error("failed to sync reloaded users", slog.Any("err", syncErr))
+		}
+
+		if previous.Log.Level == current.Log.Level {
+			return
+		}
+
+		level, levelErr := logging.ParseLevel(current.Log.Level)
+		if levelErr != nil {
+			logging.FromContext(ctx).Error("failed to apply reloaded log level", slog.Any("err", levelErr))
+
+			return
+		}
+
+		logLevelVar.Set(level)
+	})
 
-	server := api.NewServer(logger, validate, httpServer, router, rentalService, trackerService, users)
+	go func() {
+		if watchErr := watcher.Run(logging.WithLogger(context.Background(), logger)); watchErr != nil {
+			logger.Error("failed to watch config", slog.Any("err", watchErr))
+		}
+	}()
 
 	server.Run()
+
+	// the deliveries still being retried are written to the dead letters, to be replayed after the restart
+	webhookService.Close()
 }
 
-func initializeRedis(redisClient *redis.Client) error {
-	if _, err := redisClient.GeoAdd(context.Background(), "Ottawa", &redis.GeoLocation{
-		Name:      "0dae4f8c-dbbf-4bac-90f2-b80f07255ba5",
-		Longitude: 73.5673,
-		Latitude:  45.5017,
-	}).Result(); err != nil {
-		return fmt.Errorf("adding scooter location: %w", err)
+// eventConsumerName returns the configured name of the instance in the consumer groups, the hostname by default.
+func eventConsumerName(consumer string) (string, error) {
+	if consumer != "" {
+		return consumer, nil
 	}
 
-	if err := redisClient.Set(context.Background(), "0dae4f8c-dbbf-4bac-90f2-b80f07255ba5", true, 0).Err(); err != nil {
-		return fmt.Errorf("adding scooter's availability: %w", err)
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("getting hostname: %w", err)
 	}
 
-	if _, err := redisClient.GeoAdd(context.Background(), "Ottawa", &redis.GeoLocation{
-		Name:      "61637887-385e-47bd-ad8c-5ace4fbd2877",
-		Longitude: 73.5548,
-		Latitude:  45.5088,
-	}).Result(); err != nil {
-		return fmt.Errorf("adding scooter location: %w", err)
-	}
+	return hostname, nil
+}
 
-	if err := redisClient.Set(context.Background(), "61637887-385e-47bd-ad8c-5ace4fbd2877", true, 0).Err(); err != nil {
-		return fmt.Errorf("adding scooter's availability: %w", err)
+// userUUIDs parses the IDs of the users listed in the config, which are already validated.
+func userUUIDs(userIDs []string) []uuid.UUID {
+	result := make([]uuid.UUID, len(userIDs))
+
+	for i := range userIDs {
+		result[i] = uuid.MustParse(userIDs[i])
 	}
 
-	if _, err := redisClient.GeoAdd(context.Background(), "Ottawa", &redis.GeoLocation{
-		Name:      "4117b009-5e61-4b3a-aac5-c9d6a75483cb",
-		Longitude: 73.5637,
-		Latitude:  45.4724,
-	}).Result(); err != nil {
-		return fmt.Errorf("adding scooter location: %w", err)
+	return result
+}
+
+func roleLimits(limits map[string]int) map[auth.Role]int {
+	result := make(map[auth.Role]int, len(limits))
+
+	for role, requests := range limits {
+		result[auth.Role(role)] = requests
 	}
 
-	if err := redisClient.Set(context.Background(), "4117b009-5e61-4b3a-aac5-c9d6a75483cb", true, 0).Err(); err != nil {
-		return fmt.Errorf("adding scooter's availability: %w", err)
+	return result
+}
+
+// newAuthenticator loads the keys verifying bearer tokens. Without them only the admin token and the Client-Id header
+// are accepted.
+func newAuthenticator(cfg *config.Auth, adminToken string) (*auth.Authenticator, error) {
+	if !cfg.JWTEnabled() {
+		return auth.NewAuthenticator(nil, adminToken, cfg.AllowClientIDHeader), nil
 	}
 
-	if _, err := redisClient.GeoAdd(context.Background(), "Montreal", &redis.GeoLocation{
-		Name:      "bad9f260-e3f5-4375-a4b3-3f6e258eb21f",
-		Longitude: 65.5637,
-		Latitude:  30.5234,
-	}).Result(); err != nil {
-		return fmt.Errorf("adding scooter location: %w", err)
+	keys := auth.NewKeySet()
+
+	if cfg.JWTHMACKeyFile != "" {
+		if err := keys.LoadHMACKey(cfg.JWTHMACKeyFile); err != nil {
+			return nil, fmt.Errorf("loading hmac key: %w", err)
+		}
 	}
 
-	if err := redisClient.Set(context.Background(), "bad9f260-e3f5-4375-a4b3-3f6e258eb21f", true, 0).Err(); err != nil {
-		return fmt.Errorf("adding scooter's availability: %w", err)
+	for _, keyFile := range cfg.JWTPublicKeyFiles {
+		if err := keys.LoadPublicKey(keyFile); err != nil {
+			return nil, fmt.Errorf("loading public key: %w", err)
+		}
 	}
 
-	if _, err := redisClient.GeoAdd(context.Background(), "Montreal", &redis.GeoLocation{
-		Name:      "32341255-c86a-4106-94e0-28dd9b3f88f2",
-		Longitude: 65.1207,
-		Latitude:  30.2827,
-	}).Result(); err != nil {
-		return fmt.Errorf("adding scooter location: %w", err)
+	if cfg.JWTJWKSFile != "" {
+		if err := keys.LoadJWKS(cfg.JWTJWKSFile); err != nil {
+			return nil, fmt.Errorf("loading jwks: %w", err)
+		}
 	}
 
-	if err := redisClient.Set(context.Background(), "32341255-c86a-4106-94e0-28dd9b3f88f2", true, 0).Err(); err != nil {
-		return fmt.Errorf("adding scooter's availability: %w", err)
+	if keys.Empty() {
+		return nil, auth.ErrNoVerificationKey
 	}
 
-	if _, err := redisClient.GeoAdd(context.Background(), "Montreal", &redis.GeoLocation{
-		Name:      "b55fcd8c-383c-4169-9e4a-1c1bf15fdb76",
-		Longitude: 65.5537,
-		Latitude:  30.5234,
-	}).Result(); err != nil {
-		return fmt.Errorf("adding scooter location: %w", err)
+	verifier := auth.NewJWTVerifier(keys, cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTLeeway)
+
+	return auth.NewAuthenticator(verifier, adminToken, cfg.AllowClientIDHeader), nil
+}
+
+// initializeRedis seeds the scooters missing in Redis, leaving the ones seeded by an earlier start untouched.
+func initializeRedis(redisClient *redis.Client) error {
+	scooters := []struct {
+		city, name          string
+		longitude, latitude float64
+	}{
+		{"Ottawa", "0dae4f8c-dbbf-4bac-90f2-b80f07255ba5", 73.5673, 45.5017},
+		{"Ottawa", "61637887-385e-47bd-ad8c-5ace4fbd2877", 73.5548, 45.5088},
+		{"Ottawa", "4117b009-5e61-4b3a-aac5-c9d6a75483cb", 73.5637, 45.4724},
+		{"Montreal", "bad9f260-e3f5-4375-a4b3-3f6e258eb21f", 65.5637, 30.5234},
+		{"Montreal", "32341255-c86a-4106-94e0-28dd9b3f88f2", 65.1207, 30.2827},
+		{"Montreal", "b55fcd8c-383c-4169-9e4a-1c1bf15fdb76", 65.5537, 30.5234},
 	}
 
-	if err := redisClient.Set(context.Background(), "b55fcd8c-383c-4169-9e4a-1c1bf15fdb76", true, 0).Err(); err != nil {
-		return fmt.Errorf("adding scooter's availability: %w", err)
+	ctx := context.Background()
+
+	// only the missing keys are written, so the scooters rented or moved before the restart are kept as they are
+	for _, scooter := range scooters {
+		err := redisClient.ZScore(ctx, scooter.city, scooter.name).Err()
+		if errors.Is(err, redis.Nil) {
+			err = redisClient.GeoAdd(ctx, scooter.city, &redis.GeoLocation{
+				Name:      scooter.name,
+				Longitude: scooter.longitude,
+				Latitude:  scooter.latitude,
+			}).Err()
+		}
+
+		if err != nil {
+			return fmt.Errorf("adding scooter location: %w", err)
+		}
+
+		if err = redisClient.SetNX(ctx, scooter.name, true, 0).Err(); err != nil {
+			return fmt.Errorf("adding scooter's availability: %w", err)
+		}
+
+		if err = redisClient.HSetNX(ctx, redisservice.ScooterCitiesKey, scooter.name, scooter.city).Err(); err != nil {
+			return fmt.Errorf("adding scooter's city: %w", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,100 @@
+//go:build unit
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	redisservice "github.com/PatrykPasterny/scooter-rental/internal/repository"
+	eventmodel "github.com/PatrykPasterny/scooter-rental/internal/service/event/model"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/mock"
+	"github.com/PatrykPasterny/scooter-rental/internal/service/tracker"
+	trackermodel "github.com/PatrykPasterny/scooter-rental/internal/service/tracker/model"
+)
+
+const (
+	testScooterCity = "Ottawa"
+	testScooterName = "0dae4f8c-dbbf-4bac-90f2-b80f07255ba5"
+	testConsumer    = "tracker-1"
+)
+
+// TestRestartResumesRentedScooter starts the application again while a seeded scooter is rented, so the seed must not
+// free it or move it back, or the tracker would drop its ride.
+func TestRestartResumesRentedScooter(t *testing.T) {
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	require.NoError(t, initializeRedis(redisClient))
+
+	scooterUUID := uuid.MustParse(testScooterName)
+	redisService := redisservice.NewRedisService(redisClient)
+	sessionStore := redisservice.NewSessionRepository(redisClient, testConsumer)
+
+	rented := &trackermodel.Session{
+		ScooterUUID: scooterUUID,
+		UserUUID:    uuid.New(),
+		RentalUUID:  uuid.New(),
+		City:        testScooterCity,
+		Longitude:   73.5,
+		Latitude:    45.5,
+		StartedAt:   time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, redisService.UpdateScooterAvailability(ctx, scooterUUID, false))
+	require.NoError(t, redisService.UpdateScooterLocation(ctx, trackermodel.NewScooter(
+		testScooterName, testScooterCity, rented.Longitude, rented.Latitude,
+	)))
+	require.NoError(t, sessionStore.SaveSession(ctx, rented))
+
+	// the restart
+	require.NoError(t, initializeRedis(redisClient))
+
+	scooter, err := redisService.GetScooter(ctx, scooterUUID)
+	require.NoError(t, err)
+	require.False(t, scooter.Availability)
+	require.InDelta(t, rented.Longitude, scooter.Longitude, 0.0001)
+	require.InDelta(t, rented.Latitude, scooter.Latitude, 0.0001)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	publisher := mock.NewMockEventPublisher(controller)
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	trackerService, err := tracker.NewTrackingService(
+		ctx,
+		redisService,
+		redisservice.NewRentalRepository(redisClient),
+		redisservice.NewTelemetryRepository(redisClient),
+		redisservice.NewRouteRepository(redisClient),
+		sessionStore,
+		publisher,
+		tracker.ModeDevice,
+		time.Minute,
+		0,
+	)
+	require.NoError(t, err)
+
+	// the session of the dropped ride would be removed from the store
+	sessions, err := sessionStore.GetSessions(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*trackermodel.Session{rented}, sessions)
+
+	require.NoError(t, trackerService.HandleEvent(ctx, &eventmodel.Event{
+		UUID:        uuid.New(),
+		Type:        eventmodel.TypeRentalEnded,
+		RentalUUID:  rented.RentalUUID,
+		UserUUID:    rented.UserUUID,
+		ScooterUUID: scooterUUID,
+	}))
+}